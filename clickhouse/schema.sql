@@ -40,7 +40,16 @@ CREATE TABLE IF NOT EXISTS site_metrics (
 
     -- Metadata
     check_type Enum8('manual' = 1, 'automatic' = 2) DEFAULT 'automatic',
-    config_version UInt32 DEFAULT 1
+    config_version UInt32 DEFAULT 1,
+
+    -- ICMP ping metrics
+    packets_sent UInt16 DEFAULT 0,
+    packets_received UInt16 DEFAULT 0,
+    packet_loss_percent Float32 DEFAULT 0,
+    rtt_min_ms Float64 DEFAULT 0,
+    rtt_avg_ms Float64 DEFAULT 0,
+    rtt_max_ms Float64 DEFAULT 0,
+    jitter_ms Float64 DEFAULT 0
 ) ENGINE = MergeTree()
 PARTITION BY toYYYYMM(timestamp_date)
 ORDER BY (site_id, timestamp)
@@ -143,7 +152,22 @@ CREATE TABLE IF NOT EXISTS ssl_certificates (
     ssl_expiry DateTime,
     days_until_expiry Int32,
     last_checked DateTime64(3) DEFAULT now64(),
-    is_valid UInt8 DEFAULT 1
+    is_valid UInt8 DEFAULT 1,
+
+    -- TLS session and chain details
+    check_target String DEFAULT '',
+    ssl_subject String DEFAULT '',
+    ssl_sans Array(String) DEFAULT [],
+    ssl_chain Array(String) DEFAULT [],
+    hostname_match UInt8 DEFAULT 0,
+    chain_valid UInt8 DEFAULT 0,
+    ocsp_stapled UInt8 DEFAULT 0,
+    ocsp_status String DEFAULT '',
+    tls_version String DEFAULT '',
+    tls_cipher String DEFAULT '',
+    fingerprint_sha256 String DEFAULT '',
+    previous_fingerprint String DEFAULT '',
+    fingerprint_changed UInt8 DEFAULT 0
 ) ENGINE = ReplacingMergeTree(last_checked)
 PARTITION BY toYYYYMM(ssl_expiry)
 ORDER BY (site_id, ssl_expiry)
@@ -6,15 +6,26 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"ping-tower/internal/alerting"
+	"ping-tower/internal/anomaly"
 	"ping-tower/internal/config"
 	"ping-tower/internal/database"
+	"ping-tower/internal/escalation"
 	"ping-tower/internal/handlers"
+	"ping-tower/internal/heartbeat"
+	"ping-tower/internal/incident"
+	"ping-tower/internal/maintenance"
 	"ping-tower/internal/metrics"
 	"ping-tower/internal/models"
 	"ping-tower/internal/monitor"
 	"ping-tower/internal/notifications"
+	"ping-tower/internal/oncall"
+	"ping-tower/internal/queue"
+	"ping-tower/internal/routing"
 	"ping-tower/internal/scheduler"
-	"strings"
+	"ping-tower/internal/snapshots"
+	"ping-tower/internal/sslexpiry"
+	"ping-tower/internal/templates"
 	"syscall"
 	"time"
 
@@ -72,8 +83,79 @@ func main() {
 	}
 
 	checker := monitor.NewChecker(db, 15*time.Minute)
+	heartbeatService := heartbeat.NewService(db)
+	handlers.SetHeartbeatService(heartbeatService)
+	heartbeat.OnStateChange = func(hb models.Heartbeat) {
+		handlers.BroadcastSSE("heartbeat_status", hb)
+	}
+	incidentService := incident.NewService(db)
+	handlers.SetIncidentService(incidentService)
+	incident.OnChange = func(inc models.Incident) {
+		handlers.BroadcastSSE("incident_updated", inc)
+	}
+	maintenanceService := maintenance.NewService(db)
+	monitor.MaintenanceLookup = func(siteID int, config *models.SiteConfig) string {
+		if window := maintenanceService.SiteWindow(siteID, config); window != nil {
+			return window.Name
+		}
+		return ""
+	}
+	notifications.AddSuppressor(maintenanceService.Suppress)
+	notifications.AddSuppressor(incidentService.Suppress)
+	notifications.AlertDelivered = incidentService.RecordAlert
+	onCallService := oncall.NewService(db)
+	handlers.SetOnCallService(onCallService)
+	notifications.RouteAlert = onCallService.Route
+	alertRouter := routing.NewRouter(db)
+	alertRouter.SetOnCallService(onCallService)
+	notifications.SiteRoutes = alertRouter.Routes
+	escalationService := escalation.NewService(db)
+	escalationService.SetOnCallService(onCallService)
+	escalationService.SetRouter(alertRouter)
+	handlers.SetEscalationService(escalationService)
+	notifications.AddInterceptor(escalationService.Intercept)
+	templateService := templates.NewService(db)
+	handlers.SetTemplateService(templateService)
+	notifications.LookupTemplate = templateService.Lookup
+	queueService := queue.NewService(db)
+	handlers.SetQueueService(queueService)
+	notifications.DeliveryAttempted = queueService.Attempted
+	alertEngine := alerting.NewEngine(cfg.AlertEngine, alertRouter)
+	handlers.SetAlertEngine(alertEngine)
+	notifications.Batch = alertEngine.Batch
+	notifications.Throttle = alertEngine.Throttle
+	sslExpiryService := sslexpiry.NewService(db, alertRouter)
+	snapshotService := snapshots.NewService(db, cfg.Snapshots)
+	handlers.SetSnapshotService(snapshotService)
+	// Инцидент открывается до оповещения, чтобы доставки попали в его историю
+	monitor.SiteCheckRecorded = func(siteID int, siteURL string, result monitor.CheckResult) {
+		incidentService.RecordCheck(siteID, siteURL, incident.Check{
+			Status:       result.Status,
+			RawStatus:    result.RawStatus,
+			StatusCode:   result.StatusCode,
+			ResponseTime: result.ResponseTime,
+			Error:        result.Error,
+			Maintenance:  result.Maintenance,
+		})
+		snapshotService.Record(siteID, siteURL, result)
+		alertEngine.SiteChecked(siteID, siteURL, result)
+	}
+	monitor.SiteStatusUpdated = func(siteID int, siteURL string, result monitor.CheckResult) {
+		handlers.BroadcastSSE("site_checked", map[string]interface{}{
+			"site_id":               siteID,
+			"url":                   siteURL,
+			"status":                result.Status,
+			"raw_status":            result.RawStatus,
+			"pending":               result.Pending,
+			"consecutive_failures":  result.ConsecutiveFailures,
+			"consecutive_successes": result.ConsecutiveSuccesses,
+			"maintenance":           result.Maintenance,
+			"error":                 result.Error,
+		})
+	}
 
 	// Initialize alert manager with database configurations
+	notifications.PublicURL = cfg.PublicURL
 	var globalAlertManager *notifications.AlertManager
 	if cfg.Alerts.Enabled {
 		globalAlertManager = notifications.NewAlertManager(&cfg.Alerts)
@@ -90,7 +172,7 @@ func main() {
 
 	if err == nil && globalAlertConfig.Enabled {
 		// Convert database config to notifications config
-		dbAlertsConfig := convertDBToNotificationsConfig(globalAlertConfig)
+		dbAlertsConfig := routing.AlertsConfig(globalAlertConfig)
 		globalAlertManager = notifications.NewAlertManager(dbAlertsConfig)
 		log.Println("🔔 Система оповещений инициализирована (из базы данных)")
 
@@ -103,28 +185,45 @@ func main() {
 		if globalAlertConfig.TelegramEnabled {
 			log.Println("📱 Telegram оповещения включены")
 		}
-	} else if globalAlertManager == nil {
-		if err != nil {
-			log.Printf("🔕 Система оповещений отключена - ошибка загрузки конфигурации: %v", err)
-		} else if !globalAlertConfig.Enabled {
-			log.Println("🔕 Система оповещений отключена - globalAlertConfig.Enabled = false")
-		} else {
-			log.Println("🔕 Система оповещений отключена")
+		if globalAlertConfig.SlackEnabled {
+			log.Println("💬 Slack оповещения включены")
 		}
+		if globalAlertConfig.DiscordEnabled {
+			log.Println("🎮 Discord оповещения включены")
+		}
+		if globalAlertConfig.TeamsEnabled {
+			log.Println("👥 Teams оповещения включены")
+		}
+		if globalAlertConfig.PagerDutyEnabled {
+			log.Println("📟 PagerDuty интеграция включена")
+		}
+		if globalAlertConfig.OpsgenieEnabled {
+			log.Println("🚨 Opsgenie интеграция включена")
+		}
+	} else if err == nil && globalAlertManager == nil {
+		// Глобальные оповещения выключены, но у сайтов могут быть свои конфигурации
+		globalAlertManager = notifications.NewAlertManager(routing.AlertsConfig(globalAlertConfig))
+		log.Println("🔕 Глобальные оповещения отключены - действуют только конфигурации сайтов")
+	} else if globalAlertManager == nil {
+		log.Printf("🔕 Система оповещений отключена - ошибка загрузки конфигурации: %v", err)
 	}
 
-	// Устанавливаем AlertManager в checker и metrics service
+	// Оповещения сайтов отправляет только движок алертов
 	if globalAlertManager != nil {
-		checker.SetAlertManager(globalAlertManager)
-		log.Println("✅ AlertManager установлен в checker")
-
-		if metricsService != nil {
-			metricsService.SetAlertManager(globalAlertManager)
-			log.Println("✅ AlertManager установлен в metrics service")
-		}
+		alertEngine.SetAlertManager(globalAlertManager)
+		heartbeatService.SetAlertManager(globalAlertManager)
+		escalationService.SetAlertManager(globalAlertManager)
+		queueService.SetAlertManager(globalAlertManager)
+		sslExpiryService.SetAlertManager(globalAlertManager)
+		log.Println("✅ AlertManager установлен в движок алертов")
 	}
 
+	var anomalyService *anomaly.Service
 	if metricsService != nil {
+		anomalyService = anomaly.NewService(metricsService, cfg.Anomaly)
+		handlers.SetAnomalyService(anomalyService)
+		alertEngine.SetBaselines(anomalyService)
+
 		monitor.MetricsRecorder = func(siteID int, siteURL string, result monitor.CheckResult, checkType string) {
 			err := metricsService.RecordCheckResult(siteID, siteURL, result, checkType)
 			if err != nil {
@@ -133,107 +232,6 @@ func main() {
 		}
 	}
 
-	// Set up alert notifications for site checks
-	if globalAlertManager != nil {
-		monitor.NotifySiteChecked = func(siteURL string, result monitor.CheckResult) {
-			// Try to get site-specific alert config first
-			site, err := db.GetSiteByURL(siteURL)
-			var alertManager *notifications.AlertManager = globalAlertManager
-
-			// Check if site has specific alert configuration
-			if err == nil {
-				// TODO: Implement site-specific alert configs if needed
-				// For now, use global configuration
-			}
-
-			// Determine if we should send alert based on conditions
-			shouldAlert := false
-			alertType := "unknown"
-
-			// Get the global alert config to check conditions
-			if globalAlertConfig, err := db.GetAlertConfig("global"); err == nil {
-				if result.Status == "down" && globalAlertConfig.AlertOnDown {
-					shouldAlert = true
-					alertType = "site_down"
-				} else if result.Status == "up" && globalAlertConfig.AlertOnUp {
-					shouldAlert = true
-					alertType = "site_up"
-				} else if result.StatusCode >= 500 && globalAlertConfig.AlertOnDown {
-					shouldAlert = true
-					alertType = "server_error"
-				} else if globalAlertConfig.AlertOnResponseTimeThreshold &&
-					result.ResponseTime > int64(globalAlertConfig.ResponseTimeThreshold) {
-					shouldAlert = true
-					alertType = "slow_response"
-				}
-			} else {
-				// Fallback to basic conditions if no config
-				log.Printf("⚠️ Нет конфигурации алертов, используем fallback логику для %s", siteURL)
-				if result.Status == "down" {
-					shouldAlert = true
-					alertType = "site_down"
-					log.Printf("📢 Fallback: сайт %s недоступен, отправляем алерт", siteURL)
-				} else if result.StatusCode >= 500 {
-					shouldAlert = true
-					alertType = "server_error"
-					log.Printf("📢 Fallback: сайт %s вернул ошибку сервера (%d), отправляем алерт", siteURL, result.StatusCode)
-				}
-			}
-
-			if shouldAlert {
-				siteID := 0
-				if site != nil {
-					siteID = site.ID
-				}
-
-				// Конвертируем result в формат notifications
-				notificationResult := notifications.CheckResult{
-					Status:        result.Status,
-					StatusCode:    result.StatusCode,
-					ResponseTime:  result.ResponseTime,
-					ContentLength: result.ContentLength,
-					SSLValid:      result.SSLValid,
-					SSLExpiry:     result.SSLExpiry,
-					Error:         result.Error,
-					DNSTime:       result.DNSTime,
-					ConnectTime:   result.ConnectTime,
-					TLSTime:       result.TLSTime,
-					TTFB:          result.TTFB,
-					ContentHash:   result.ContentHash,
-					RedirectCount: result.RedirectCount,
-					FinalURL:      result.FinalURL,
-					Headers:       result.Headers,
-					Keywords:      result.Keywords,
-					SSLKeyLength:  result.SSLKeyLength,
-					SSLAlgorithm:  result.SSLAlgorithm,
-					SSLIssuer:     result.SSLIssuer,
-					ServerType:    result.ServerType,
-					PoweredBy:     result.PoweredBy,
-					ContentType:   result.ContentType,
-					CacheControl:  result.CacheControl,
-					Cookies:       result.Cookies,
-				}
-
-				err := alertManager.SendAlert(siteID, siteURL, notificationResult, alertType)
-				if err != nil {
-					log.Printf("⚠️ Ошибка отправки оповещения для %s: %v", siteURL, err)
-
-					// Log to alert history if possible
-					if site != nil && globalAlertConfig != nil {
-						db.LogAlert(site.ID, globalAlertConfig.ID, alertType, "all", "failed", "", err.Error())
-					}
-				} else {
-					log.Printf("✅ Оповещение отправлено для %s (тип: %s)", siteURL, alertType)
-
-					// Log to alert history
-					if site != nil && globalAlertConfig != nil {
-						db.LogAlert(site.ID, globalAlertConfig.ID, alertType, "all", "sent", "Alert sent successfully", "")
-					}
-				}
-			}
-		}
-	}
-
 	cronScheduler := scheduler.NewCronScheduler()
 	err = cronScheduler.Start()
 	if err != nil {
@@ -251,6 +249,75 @@ func main() {
 		log.Printf("⚠️ Ошибка добавления задания общей проверки: %v", err)
 	}
 
+	err = cronScheduler.AddJob(
+		"heartbeat-check",
+		"Проверка пропущенных heartbeat",
+		"* * * * *",
+		heartbeatService.CreateJob(),
+	)
+	if err != nil {
+		log.Printf("⚠️ Ошибка добавления задания проверки heartbeat: %v", err)
+	}
+
+	err = cronScheduler.AddJob(
+		"escalation-worker",
+		"Выполнение шагов эскалации",
+		"* * * * *",
+		escalationService.CreateJob(),
+	)
+	if err != nil {
+		log.Printf("⚠️ Ошибка добавления задания эскалации: %v", err)
+	}
+
+	err = cronScheduler.AddJob(
+		"notification-queue",
+		"Повторная отправка недоставленных оповещений",
+		"* * * * *",
+		queueService.CreateJob(),
+	)
+	if err != nil {
+		log.Printf("⚠️ Ошибка добавления задания очереди оповещений: %v", err)
+	}
+
+	err = cronScheduler.AddJob(
+		"alert-digest",
+		"Отправка дайджестов оповещений",
+		"* * * * *",
+		alertEngine.CreateJob(),
+	)
+	if err != nil {
+		log.Printf("⚠️ Ошибка добавления задания дайджестов: %v", err)
+	}
+
+	err = cronScheduler.AddJob(
+		"ssl-expiry",
+		"Проверка сроков действия SSL сертификатов",
+		"0 9 * * *",
+		sslExpiryService.CreateJob(),
+	)
+	if err != nil {
+		log.Printf("⚠️ Ошибка добавления задания проверки SSL: %v", err)
+	}
+	// Пороги, пересеченные пока сервис был остановлен, не ждут следующего дня
+	go sslExpiryService.CheckAll()
+
+	if anomalyService != nil {
+		err = cronScheduler.AddJob(
+			"anomaly-baselines",
+			"Обновление базовых линий времени отклика",
+			"5 * * * *",
+			anomalyService.CreateJob(),
+		)
+		if err != nil {
+			log.Printf("⚠️ Ошибка добавления задания базовых линий: %v", err)
+		}
+		go func() {
+			if err := anomalyService.Refresh(); err != nil {
+				log.Printf("⚠️ Ошибка построения базовых линий времени отклика: %v", err)
+			}
+		}()
+	}
+
 	sites, err := db.GetAllSites()
 	if err != nil {
 		log.Printf("⚠️ Ошибка загрузки сайтов: %v", err)
@@ -299,39 +366,3 @@ func main() {
 
 	log.Fatal(http.ListenAndServe(cfg.ServerAddress, r))
 }
-
-// convertDBToNotificationsConfig converts database AlertConfig to notifications AlertsConfig
-func convertDBToNotificationsConfig(dbConfig *models.AlertConfig) *config.AlertsConfig {
-	// Parse email recipients
-	var emailTo []string
-	if dbConfig.EmailTo != "" {
-		emailTo = strings.Split(dbConfig.EmailTo, ",")
-		for i := range emailTo {
-			emailTo[i] = strings.TrimSpace(emailTo[i])
-		}
-	}
-
-	return &config.AlertsConfig{
-		Enabled: dbConfig.Enabled,
-		Email: config.EmailAlertConfig{
-			Enabled:    dbConfig.EmailEnabled,
-			SMTPServer: dbConfig.SMTPServer,
-			Port:       dbConfig.SMTPPort,
-			Username:   dbConfig.SMTPUsername,
-			Password:   dbConfig.SMTPPassword,
-			From:       dbConfig.EmailFrom,
-			To:         emailTo,
-		},
-		Webhook: config.WebhookAlertConfig{
-			Enabled: dbConfig.WebhookEnabled,
-			URL:     dbConfig.WebhookURL,
-			Headers: dbConfig.WebhookHeaders,
-			Timeout: dbConfig.WebhookTimeout,
-		},
-		Telegram: config.TelegramAlertConfig{
-			Enabled:  dbConfig.TelegramEnabled,
-			BotToken: dbConfig.TelegramBotToken,
-			ChatID:   dbConfig.TelegramChatID,
-		},
-	}
-}
\ No newline at end of file
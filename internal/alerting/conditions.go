@@ -0,0 +1,279 @@
+package alerting
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ping-tower/internal/anomaly"
+	"ping-tower/internal/models"
+	"ping-tower/internal/notifications"
+	"ping-tower/internal/routing"
+)
+
+const (
+	AlertSlowResponse          = "slow_response"
+	AlertResponseTimeRecovered = "response_time_recovered"
+	AlertResponseTimeAnomaly   = "response_time_anomaly"
+	AlertResponseTimeNormal    = "response_time_normal"
+	AlertStatusCodeChanged     = "status_code_changed"
+	AlertStatusCodeRestored    = "status_code_restored"
+	AlertContentChanged        = "content_changed"
+	AlertContentRestored       = "content_restored"
+	AlertRedirectChanged       = "redirect_changed"
+	AlertRedirectRestored      = "redirect_restored"
+	AlertKeywordLost           = "keyword_lost"
+	AlertKeywordRestored       = "keyword_restored"
+)
+
+// responseTimeSmoothing is the weight of a check in the smoothed response
+// time compared with the baseline. The baseline is made of hourly means, so a
+// single slow check is not an anomaly.
+const responseTimeSmoothing = 0.3
+
+// recoveryOf maps the alerts of lasting conditions to the alert sent when
+// the condition clears while the site is up.
+var recoveryOf = map[string]string{
+	"site_down":              "site_up",
+	AlertSlowResponse:        AlertResponseTimeRecovered,
+	AlertResponseTimeAnomaly: AlertResponseTimeNormal,
+}
+
+// raisedAlert is an alert to send; a message replaces the error of the check.
+type raisedAlert struct {
+	alertType string
+	message   string
+}
+
+// changeTracker follows a value of the checks. A change is reported against
+// the value before it; returning to the value seen before the first of a
+// series of changes restores it.
+type changeTracker struct {
+	current  string
+	baseline string
+}
+
+func (t *changeTracker) observe(value string) (changed, restored bool, from string) {
+	if value == "" || value == t.current {
+		return false, false, ""
+	}
+	if t.current == "" {
+		t.current = value
+		return false, false, ""
+	}
+
+	from = t.current
+	if value == t.baseline {
+		restored = true
+		t.baseline = ""
+	} else {
+		changed = true
+		if t.baseline == "" {
+			t.baseline = t.current
+		}
+	}
+	t.current = value
+	return changed, restored, from
+}
+
+// conditionState is what the conditions of a site remember between checks.
+type conditionState struct {
+	// slow and anomalous count the consecutive slow and anomalous checks per
+	// alert configuration
+	slow      map[string]int
+	anomalous map[string]int
+	// responseTime is the exponentially weighted mean of the response times
+	// of the checks the site was up
+	responseTime float64
+
+	statusCode changeTracker
+	content    changeTracker
+	redirect   changeTracker
+
+	keywords     []string
+	lostKeywords map[string]bool
+}
+
+func newConditionState() conditionState {
+	return conditionState{
+		slow:         make(map[string]int),
+		anomalous:    make(map[string]int),
+		lostKeywords: make(map[string]bool),
+	}
+}
+
+// compareBaseline updates the smoothed response time of the site and sets how
+// far it is above the band of the hour in the result.
+func compareBaseline(state *siteState, band *anomaly.Band, result *notifications.CheckResult) {
+	if result.Status != "up" {
+		return
+	}
+
+	s := &state.conditions
+	if s.responseTime == 0 {
+		s.responseTime = float64(result.ResponseTime)
+	} else {
+		s.responseTime += responseTimeSmoothing * (float64(result.ResponseTime) - s.responseTime)
+	}
+
+	if band != nil {
+		result.BaselineResponseTime = band.Median
+		result.BaselineSigma = band.Sigma
+		result.ResponseTimeDeviation = band.Deviation(s.responseTime)
+	}
+}
+
+// conditions returns the lasting conditions the check meets under the alert
+// configurations of the site; without any configuration outages and server
+// errors are reported. Slow and anomalous responses count only after the
+// number of consecutive checks the configuration asks for.
+func conditions(state *siteState, configs []models.AlertConfig, result notifications.CheckResult) []raisedAlert {
+	if len(configs) == 0 {
+		switch {
+		case result.Status == "down":
+			return []raisedAlert{{alertType: "site_down"}}
+		case result.StatusCode >= 500:
+			return []raisedAlert{{alertType: "server_error"}}
+		}
+		return nil
+	}
+
+	var alerts []raisedAlert
+	seen := map[string]bool{}
+	for i := range configs {
+		cfg := &configs[i]
+		slow, anomalous := false, false
+		for _, alertType := range routing.Conditions(cfg, result) {
+			message := ""
+			switch alertType {
+			case AlertSlowResponse:
+				slow = true
+				state.conditions.slow[cfg.Name]++
+				count := state.conditions.slow[cfg.Name]
+				if count < cfg.ResponseTimeChecks {
+					continue
+				}
+				message = fmt.Sprintf("Response time %dms above %dms for %d checks", result.ResponseTime, cfg.ResponseTimeThreshold, count)
+			case AlertResponseTimeAnomaly:
+				anomalous = true
+				state.conditions.anomalous[cfg.Name]++
+				count := state.conditions.anomalous[cfg.Name]
+				if count < cfg.ResponseTimeChecks {
+					continue
+				}
+				smoothed := result.BaselineResponseTime + result.ResponseTimeDeviation*result.BaselineSigma
+				message = fmt.Sprintf("Response time averaging %.0fms is %.1fσ above the usual %.0fms for this hour",
+					smoothed, result.ResponseTimeDeviation, result.BaselineResponseTime)
+			}
+			if !seen[alertType] {
+				seen[alertType] = true
+				alerts = append(alerts, raisedAlert{alertType: alertType, message: message})
+			}
+		}
+		if !slow {
+			state.conditions.slow[cfg.Name] = 0
+		}
+		if !anomalous {
+			state.conditions.anomalous[cfg.Name] = 0
+		}
+	}
+	return alerts
+}
+
+// recovery returns the alert sent when a lasting condition clears.
+func recovery(alertType string, result notifications.CheckResult) (raisedAlert, bool) {
+	recoveryType, ok := recoveryOf[alertType]
+	if !ok || result.Status != "up" {
+		return raisedAlert{}, false
+	}
+
+	message := ""
+	switch {
+	case alertType == AlertResponseTimeAnomaly && result.BaselineResponseTime > 0:
+		message = fmt.Sprintf("Response time back to %dms, usual %.0fms", result.ResponseTime, result.BaselineResponseTime)
+	case alertType == AlertSlowResponse, alertType == AlertResponseTimeAnomaly:
+		message = fmt.Sprintf("Response time back to %dms", result.ResponseTime)
+	}
+	return raisedAlert{alertType: recoveryType, message: message}, true
+}
+
+// changes compares the check with the previous ones and returns the change
+// and restore alerts enabled in any of the configurations. The values are
+// followed even when no configuration alerts on them, so enabling an alert
+// does not report the state found at that moment as a change. Checks that
+// got no response carry none of the values and are skipped, error pages are
+// not searched for keywords.
+func changes(state *siteState, configs []models.AlertConfig, result notifications.CheckResult) []raisedAlert {
+	if result.StatusCode == 0 {
+		return nil
+	}
+
+	var statusCode, content, redirect, keywords bool
+	for _, cfg := range configs {
+		statusCode = statusCode || cfg.AlertOnStatusCodeChange
+		content = content || cfg.AlertOnContentChange
+		redirect = redirect || cfg.AlertOnRedirectChange
+		keywords = keywords || cfg.AlertOnKeywordLost
+	}
+
+	s := &state.conditions
+	var alerts []raisedAlert
+	add := func(enabled bool, alertType, message string) {
+		if enabled {
+			alerts = append(alerts, raisedAlert{alertType: alertType, message: message})
+		}
+	}
+
+	code := strconv.Itoa(result.StatusCode)
+	if changed, restored, from := s.statusCode.observe(code); changed {
+		add(statusCode, AlertStatusCodeChanged, fmt.Sprintf("Status code changed from %s to %s", from, code))
+	} else if restored {
+		add(statusCode, AlertStatusCodeRestored, fmt.Sprintf("Status code restored to %s", code))
+	}
+
+	if changed, restored, from := s.content.observe(result.ContentHash); changed {
+		add(content, AlertContentChanged, fmt.Sprintf("Content hash changed from %s to %s", from, result.ContentHash))
+	} else if restored {
+		add(content, AlertContentRestored, fmt.Sprintf("Content restored (hash %s)", result.ContentHash))
+	}
+
+	if changed, restored, from := s.redirect.observe(result.FinalURL); changed {
+		add(redirect, AlertRedirectChanged, fmt.Sprintf("Redirect target changed from %s to %s", from, result.FinalURL))
+	} else if restored {
+		add(redirect, AlertRedirectRestored, fmt.Sprintf("Redirect target restored to %s", result.FinalURL))
+	}
+
+	// Страница ошибки не содержит ключевых слов - это не их пропажа
+	if result.StatusCode >= 400 {
+		return alerts
+	}
+
+	found := map[string]bool{}
+	for _, keyword := range result.Keywords {
+		found[keyword] = true
+	}
+	var lost, back []string
+	for _, keyword := range s.keywords {
+		if !found[keyword] && !s.lostKeywords[keyword] {
+			s.lostKeywords[keyword] = true
+			lost = append(lost, keyword)
+		}
+	}
+	for keyword := range s.lostKeywords {
+		if found[keyword] {
+			delete(s.lostKeywords, keyword)
+			back = append(back, keyword)
+		}
+	}
+	s.keywords = result.Keywords
+	if len(lost) > 0 {
+		add(keywords, AlertKeywordLost, "Keywords no longer found: "+strings.Join(lost, ", "))
+	}
+	if len(back) > 0 {
+		sort.Strings(back)
+		add(keywords, AlertKeywordRestored, "Keywords found again: "+strings.Join(back, ", "))
+	}
+
+	return alerts
+}
@@ -0,0 +1,357 @@
+// Package alerting decides which site alerts are sent. Every stored check
+// goes through the Engine: an alert is raised once per site and type while
+// its condition lasts and its recovery is sent when it clears, changes of the
+// status code, content, redirect target and keywords are reported as they
+// happen, response times far above the baseline of the site are reported as
+// anomalies, a flapping site gets a single flapping alert instead of a stream
+// of outages and recoveries, every channel is held to a rate limit and
+// failures can be batched into digests.
+//
+// The state is kept in memory; after a restart an ongoing outage is reported
+// once more.
+package alerting
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ping-tower/internal/anomaly"
+	"ping-tower/internal/config"
+	"ping-tower/internal/models"
+	"ping-tower/internal/monitor"
+	"ping-tower/internal/notifications"
+	"ping-tower/internal/routing"
+)
+
+const (
+	AlertFlapping        = "site_flapping"
+	AlertFlappingStopped = "flapping_stopped"
+)
+
+// recoveryTypes are never rate limited or held for a digest. The recovery of
+// a site cancels all of its held alerts, the others only the alert they
+// recover from.
+var (
+	recoveryTypes = map[string]bool{
+		"site_up":                  true,
+		"heartbeat_recovered":      true,
+		AlertFlappingStopped:       true,
+		AlertResponseTimeRecovered: true,
+		AlertResponseTimeNormal:    true,
+		AlertStatusCodeRestored:    true,
+		AlertContentRestored:       true,
+		AlertRedirectRestored:      true,
+		AlertKeywordRestored:       true,
+	}
+	siteRecoveryTypes = map[string]bool{
+		"site_up":             true,
+		"heartbeat_recovered": true,
+		AlertFlappingStopped:  true,
+	}
+	recoveredBy = map[string]string{
+		AlertResponseTimeRecovered: AlertSlowResponse,
+		AlertResponseTimeNormal:    AlertResponseTimeAnomaly,
+		AlertStatusCodeRestored:    AlertStatusCodeChanged,
+		AlertContentRestored:       AlertContentChanged,
+		AlertRedirectRestored:      AlertRedirectChanged,
+		AlertKeywordRestored:       AlertKeywordLost,
+	}
+)
+
+type siteState struct {
+	url    string
+	status string
+	// open are the raised alerts whose condition still holds
+	open          map[string]time.Time
+	transitions   []time.Time
+	flappingSince *time.Time
+	conditions    conditionState
+}
+
+type digestGroup struct {
+	started time.Time
+	alerts  []notifications.AlertData
+}
+
+type Engine struct {
+	config       config.AlertEngineConfig
+	router       *routing.Router
+	alertManager *notifications.AlertManager
+	baselines    *anomaly.Service
+
+	mu    sync.Mutex
+	sites map[int]*siteState
+
+	rateMu    sync.Mutex
+	sent      map[string][]time.Time
+	throttled map[string]int
+
+	digestMu sync.Mutex
+	digests  map[string]*digestGroup
+}
+
+func NewEngine(cfg config.AlertEngineConfig, router *routing.Router) *Engine {
+	if cfg.FlapWindow <= 0 {
+		cfg.FlapWindow = 30 * time.Minute
+	}
+	if cfg.RateWindow <= 0 {
+		cfg.RateWindow = time.Hour
+	}
+	if cfg.DigestWindow <= 0 {
+		cfg.DigestWindow = 5 * time.Minute
+	}
+
+	return &Engine{
+		config:       cfg,
+		router:       router,
+		alertManager: nil, // Will be set externally
+		sites:        make(map[int]*siteState),
+		sent:         make(map[string][]time.Time),
+		throttled:    make(map[string]int),
+		digests:      make(map[string]*digestGroup),
+	}
+}
+
+func (e *Engine) SetAlertManager(alertManager *notifications.AlertManager) {
+	e.alertManager = alertManager
+}
+
+// SetBaselines enables response time anomaly alerts.
+func (e *Engine) SetBaselines(baselines *anomaly.Service) {
+	e.baselines = baselines
+}
+
+// SiteChecked evaluates a stored check with the confirmed status and sends
+// the alerts it raises. Checks during maintenance leave the state alone, so
+// an outage that outlasts the window is reported afterwards.
+func (e *Engine) SiteChecked(siteID int, siteURL string, result monitor.CheckResult) {
+	if e.alertManager == nil || result.Maintenance {
+		return
+	}
+
+	now := time.Now()
+	notificationResult := NotificationResult(result)
+
+	var band *anomaly.Band
+	if e.baselines != nil {
+		if b, ok := e.baselines.Band(siteID, now); ok {
+			band = &b
+		}
+	}
+
+	configs := e.router.ConditionConfigs(siteID)
+
+	e.mu.Lock()
+	compareBaseline(e.site(siteID), band, &notificationResult)
+	alerts := e.evaluate(siteID, siteURL, configs, notificationResult, now)
+	e.mu.Unlock()
+
+	for _, alert := range alerts {
+		result := notificationResult
+		if alert.message != "" {
+			result.Error = alert.message
+		}
+		if err := e.alertManager.SendAlert(siteID, siteURL, result, alert.alertType); err != nil {
+			log.Printf("⚠️ Ошибка отправки оповещения для %s: %v", siteURL, err)
+		} else {
+			log.Printf("✅ Оповещение обработано для %s (тип: %s)", siteURL, alert.alertType)
+		}
+	}
+}
+
+func (e *Engine) site(siteID int) *siteState {
+	state := e.sites[siteID]
+	if state == nil {
+		state = &siteState{open: make(map[string]time.Time), conditions: newConditionState()}
+		e.sites[siteID] = state
+	}
+	return state
+}
+
+// evaluate updates the state of the site and returns the alerts to send.
+func (e *Engine) evaluate(siteID int, siteURL string, configs []models.AlertConfig, result notifications.CheckResult, now time.Time) []raisedAlert {
+	state := e.site(siteID)
+	state.url = siteURL
+
+	if state.status != "" && state.status != result.Status {
+		state.transitions = append(state.transitions, now)
+	}
+	state.status = result.Status
+	state.transitions = since(state.transitions, now.Add(-e.config.FlapWindow))
+
+	var alerts []raisedAlert
+
+	flapping := e.config.FlapThreshold > 0 && len(state.transitions) >= e.config.FlapThreshold
+	switch {
+	case flapping && state.flappingSince == nil:
+		state.flappingSince = &now
+		delete(state.open, "site_down")
+		alerts = append(alerts, raisedAlert{alertType: AlertFlapping})
+		log.Printf("🔀 Сайт %s нестабилен: %d смен статуса за %s", siteURL, len(state.transitions), e.config.FlapWindow)
+	case state.flappingSince != nil && len(state.transitions) == 0:
+		// Статус не менялся все окно - сайт стабилен
+		state.flappingSince = nil
+		log.Printf("🔀 Сайт %s стабилен (%s)", siteURL, result.Status)
+		if result.Status == "up" {
+			alerts = append(alerts, raisedAlert{alertType: AlertFlappingStopped})
+		}
+	}
+
+	active := map[string]bool{}
+	for _, alert := range conditions(state, configs, result) {
+		active[alert.alertType] = true
+		if alert.alertType == "site_down" && state.flappingSince != nil {
+			continue
+		}
+		if _, open := state.open[alert.alertType]; !open {
+			state.open[alert.alertType] = now
+			alerts = append(alerts, alert)
+		}
+	}
+
+	for alertType := range state.open {
+		if active[alertType] {
+			continue
+		}
+		delete(state.open, alertType)
+		if alert, ok := recovery(alertType, result); ok {
+			alerts = append(alerts, alert)
+		}
+	}
+
+	return append(alerts, changes(state, configs, result)...)
+}
+
+// Throttle is assigned to notifications.Throttle. It enforces the rate limit
+// of the channel; test alerts and recoveries are always delivered.
+func (e *Engine) Throttle(channel string, alertData notifications.AlertData) bool {
+	limit := e.rateLimit(channel)
+	if limit <= 0 || alertData.AlertType == "test" || recoveryTypes[alertData.AlertType] {
+		return false
+	}
+
+	e.rateMu.Lock()
+	defer e.rateMu.Unlock()
+
+	now := time.Now()
+	e.sent[channel] = since(e.sent[channel], now.Add(-e.config.RateWindow))
+	if len(e.sent[channel]) >= limit {
+		e.throttled[channel]++
+		return true
+	}
+
+	e.sent[channel] = append(e.sent[channel], now)
+	return false
+}
+
+func (e *Engine) rateLimit(channel string) int {
+	if limit, ok := e.config.ChannelRateLimits[channel]; ok {
+		return limit
+	}
+	return e.config.RateLimit
+}
+
+// Batch is assigned to notifications.Batch. In digest mode failures are held
+// per group of sites with the same alert configurations. A recovery of a site
+// whose failure is still held cancels both.
+func (e *Engine) Batch(alertData notifications.AlertData) bool {
+	if !e.config.DigestEnabled || alertData.AlertType == "test" {
+		return false
+	}
+
+	key := e.groupKey(alertData.SiteID)
+
+	e.digestMu.Lock()
+	defer e.digestMu.Unlock()
+
+	group := e.digests[key]
+
+	if recoveryTypes[alertData.AlertType] {
+		if group == nil {
+			return false
+		}
+
+		held := group.alerts[:0]
+		for _, alert := range group.alerts {
+			if alert.SiteURL != alertData.SiteURL ||
+				(!siteRecoveryTypes[alertData.AlertType] && recoveredBy[alertData.AlertType] != alert.AlertType) {
+				held = append(held, alert)
+			}
+		}
+		if len(held) == len(group.alerts) {
+			return false
+		}
+
+		group.alerts = held
+		if len(held) == 0 {
+			delete(e.digests, key)
+		}
+		log.Printf("📋 %s восстановился до отправки дайджеста, оповещения отменены", alertData.SiteURL)
+		return true
+	}
+
+	if group == nil {
+		group = &digestGroup{started: time.Now()}
+		e.digests[key] = group
+	}
+	group.alerts = append(group.alerts, alertData)
+
+	log.Printf("📋 Алерт %s для %s отложен для дайджеста (%d в группе)", alertData.AlertType, alertData.SiteURL, len(group.alerts))
+	return true
+}
+
+// groupKey identifies the sites whose alerts go through the same routes.
+func (e *Engine) groupKey(siteID int) string {
+	var names []string
+	for _, cfg := range e.router.Configs(siteID) {
+		names = append(names, cfg.Name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// FlushDigests sends the digests whose window has passed.
+func (e *Engine) FlushDigests() error {
+	if e.alertManager == nil {
+		return nil
+	}
+
+	now := time.Now()
+	var due [][]notifications.AlertData
+
+	e.digestMu.Lock()
+	for key, group := range e.digests {
+		if now.Sub(group.started) >= e.config.DigestWindow {
+			due = append(due, group.alerts)
+			delete(e.digests, key)
+		}
+	}
+	e.digestMu.Unlock()
+
+	for _, alerts := range due {
+		if err := e.alertManager.SendDigest(alerts); err != nil {
+			log.Printf("⚠️ Ошибка отправки дайджеста: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// CreateJob returns a scheduler job that sends due digests.
+func (e *Engine) CreateJob() func() error {
+	return func() error {
+		return e.FlushDigests()
+	}
+}
+
+// since drops the times before the cutoff; times are in ascending order.
+func since(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && times[i].Before(cutoff) {
+		i++
+	}
+	return times[i:]
+}
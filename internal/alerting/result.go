@@ -0,0 +1,49 @@
+package alerting
+
+import (
+	"ping-tower/internal/monitor"
+	"ping-tower/internal/notifications"
+)
+
+// NotificationResult converts a check result into the one carried by alerts.
+func NotificationResult(result monitor.CheckResult) notifications.CheckResult {
+	return notifications.CheckResult{
+		Status:        result.Status,
+		StatusCode:    result.StatusCode,
+		ResponseTime:  result.ResponseTime,
+		ContentLength: result.ContentLength,
+		SSLValid:      result.SSLValid,
+		SSLExpiry:     result.SSLExpiry,
+		Error:         result.Error,
+		DNSTime:       result.DNSTime,
+		ConnectTime:   result.ConnectTime,
+		TLSTime:       result.TLSTime,
+		TTFB:          result.TTFB,
+		ContentHash:   result.ContentHash,
+		RedirectCount: result.RedirectCount,
+		FinalURL:      result.FinalURL,
+		Headers:       result.Headers,
+		Keywords:      result.Keywords,
+		SSLKeyLength:  result.SSLKeyLength,
+		SSLAlgorithm:  result.SSLAlgorithm,
+		SSLIssuer:     result.SSLIssuer,
+		ServerType:    result.ServerType,
+		PoweredBy:     result.PoweredBy,
+		ContentType:   result.ContentType,
+		CacheControl:  result.CacheControl,
+		Cookies:       result.Cookies,
+
+		PacketsSent:     result.PacketsSent,
+		PacketsReceived: result.PacketsReceived,
+		PacketLoss:      result.PacketLoss,
+		RTTMin:          result.RTTMin,
+		RTTAvg:          result.RTTAvg,
+		RTTMax:          result.RTTMax,
+		Jitter:          result.Jitter,
+
+		DNSAnswers: result.DNSAnswers,
+
+		Steps:      result.Steps,
+		FailedStep: result.FailedStep,
+	}
+}
@@ -0,0 +1,110 @@
+package alerting
+
+import (
+	"sort"
+	"time"
+
+	"ping-tower/internal/notifications"
+)
+
+// Status is a snapshot of the engine for the API.
+type Status struct {
+	FlapThreshold       int             `json:"flap_threshold"`
+	FlapWindowMinutes   int             `json:"flap_window_minutes"`
+	DigestEnabled       bool            `json:"digest_enabled"`
+	DigestWindowMinutes int             `json:"digest_window_minutes"`
+	OpenAlerts          []OpenAlert     `json:"open_alerts"`
+	FlappingSites       []FlappingSite  `json:"flapping_sites"`
+	RateLimits          []ChannelRate   `json:"rate_limits"`
+	PendingDigests      []PendingDigest `json:"pending_digests"`
+}
+
+// OpenAlert is an alert that was raised and is not sent again until its
+// condition clears.
+type OpenAlert struct {
+	SiteID    int       `json:"site_id"`
+	SiteURL   string    `json:"site_url"`
+	AlertType string    `json:"alert_type"`
+	Since     time.Time `json:"since"`
+}
+
+type FlappingSite struct {
+	SiteID        int       `json:"site_id"`
+	SiteURL       string    `json:"site_url"`
+	Since         time.Time `json:"since"`
+	StatusChanges int       `json:"status_changes"`
+}
+
+// ChannelRate shows the use of the rate limit of a channel; Throttled counts
+// the alerts skipped since the start.
+type ChannelRate struct {
+	Channel       string `json:"channel"`
+	Limit         int    `json:"limit"`
+	WindowMinutes int    `json:"window_minutes"`
+	Sent          int    `json:"sent"`
+	Throttled     int    `json:"throttled"`
+}
+
+type PendingDigest struct {
+	Configs string    `json:"configs"`
+	Since   time.Time `json:"since"`
+	SendAt  time.Time `json:"send_at"`
+	Alerts  int       `json:"alerts"`
+}
+
+func (e *Engine) Status() Status {
+	status := Status{
+		FlapThreshold:       e.config.FlapThreshold,
+		FlapWindowMinutes:   int(e.config.FlapWindow / time.Minute),
+		DigestEnabled:       e.config.DigestEnabled,
+		DigestWindowMinutes: int(e.config.DigestWindow / time.Minute),
+		OpenAlerts:          []OpenAlert{},
+		FlappingSites:       []FlappingSite{},
+		RateLimits:          []ChannelRate{},
+		PendingDigests:      []PendingDigest{},
+	}
+
+	e.mu.Lock()
+	for siteID, state := range e.sites {
+		for alertType, since := range state.open {
+			status.OpenAlerts = append(status.OpenAlerts, OpenAlert{siteID, state.url, alertType, since})
+		}
+		if state.flappingSince != nil {
+			status.FlappingSites = append(status.FlappingSites, FlappingSite{siteID, state.url, *state.flappingSince, len(state.transitions)})
+		}
+	}
+	e.mu.Unlock()
+
+	sort.Slice(status.OpenAlerts, func(i, j int) bool { return status.OpenAlerts[i].Since.Before(status.OpenAlerts[j].Since) })
+	sort.Slice(status.FlappingSites, func(i, j int) bool { return status.FlappingSites[i].SiteID < status.FlappingSites[j].SiteID })
+
+	cutoff := time.Now().Add(-e.config.RateWindow)
+	e.rateMu.Lock()
+	for _, channel := range notifications.Channels {
+		limit := e.rateLimit(channel)
+		if limit <= 0 {
+			continue
+		}
+		status.RateLimits = append(status.RateLimits, ChannelRate{
+			Channel:       channel,
+			Limit:         limit,
+			WindowMinutes: int(e.config.RateWindow / time.Minute),
+			Sent:          len(since(e.sent[channel], cutoff)),
+			Throttled:     e.throttled[channel],
+		})
+	}
+	e.rateMu.Unlock()
+
+	e.digestMu.Lock()
+	for key, group := range e.digests {
+		status.PendingDigests = append(status.PendingDigests, PendingDigest{
+			Configs: key,
+			Since:   group.started,
+			SendAt:  group.started.Add(e.config.DigestWindow),
+			Alerts:  len(group.alerts),
+		})
+	}
+	e.digestMu.Unlock()
+
+	return status
+}
@@ -0,0 +1,138 @@
+package anomaly
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	SeasonalityHourOfWeek = "hour_of_week"
+	SeasonalityHourOfDay  = "hour_of_day"
+	SeasonalityNone       = "none"
+)
+
+const (
+	// madScale turns the median absolute deviation into the standard
+	// deviation of normally distributed values
+	madScale = 1.4826
+
+	// Hours needed for a band of the same hour and weekday, of the same hour
+	// of any day and of all hours
+	minHourOfWeekSamples = 3
+	minHourOfDaySamples  = 7
+	minSamples           = 24
+
+	// minSigma and minRelativeSigma keep a very stable site from taking a few
+	// milliseconds for an anomaly; hourly means also vary less than checks
+	minSigma         = 10.0
+	minRelativeSigma = 0.1
+)
+
+// Sample is the mean response time of a site in an hour.
+type Sample struct {
+	Hour         time.Time
+	ResponseTime float64
+}
+
+// Band is the usual response time of a site in an hour: the median of the
+// past hours alike and their median absolute deviation scaled to a standard
+// deviation, both in milliseconds.
+type Band struct {
+	Median      float64 `json:"median"`
+	Sigma       float64 `json:"sigma"`
+	Samples     int     `json:"samples"`
+	Seasonality string  `json:"seasonality"`
+}
+
+// Deviation returns the distance of the response time above the median in
+// sigmas, negative below it.
+func (b Band) Deviation(responseTime float64) float64 {
+	return (responseTime - b.Median) / b.Sigma
+}
+
+// Baseline holds the bands of a site for every hour of the week. Hours of the
+// week and of the day are those of the local time zone of the server.
+type Baseline struct {
+	hourOfWeek [7 * 24]Band
+	hourOfDay  [24]Band
+	overall    Band
+	// samples are the measured response times by the unix time of the hour
+	samples map[int64]float64
+}
+
+func NewBaseline(samples []Sample) *Baseline {
+	var hourOfWeek [7 * 24][]float64
+	var hourOfDay [24][]float64
+	all := make([]float64, 0, len(samples))
+
+	b := &Baseline{samples: make(map[int64]float64, len(samples))}
+	for _, sample := range samples {
+		hour := sample.Hour.In(time.Local)
+		hourOfWeek[weekHour(hour)] = append(hourOfWeek[weekHour(hour)], sample.ResponseTime)
+		hourOfDay[hour.Hour()] = append(hourOfDay[hour.Hour()], sample.ResponseTime)
+		all = append(all, sample.ResponseTime)
+		b.samples[sample.Hour.Unix()] = sample.ResponseTime
+	}
+
+	for i, values := range hourOfWeek {
+		b.hourOfWeek[i] = newBand(values, SeasonalityHourOfWeek, minHourOfWeekSamples)
+	}
+	for i, values := range hourOfDay {
+		b.hourOfDay[i] = newBand(values, SeasonalityHourOfDay, minHourOfDaySamples)
+	}
+	b.overall = newBand(all, SeasonalityNone, minSamples)
+	return b
+}
+
+// At returns the band for the hour of the time: the one of the same hour and
+// weekday when enough weeks are known, otherwise of the same hour of any day,
+// otherwise of all hours.
+func (b *Baseline) At(t time.Time) (Band, bool) {
+	t = t.In(time.Local)
+	for _, band := range []Band{b.hourOfWeek[weekHour(t)], b.hourOfDay[t.Hour()], b.overall} {
+		if band.Samples > 0 {
+			return band, true
+		}
+	}
+	return Band{}, false
+}
+
+// ResponseTime returns the measured mean response time of the hour.
+func (b *Baseline) ResponseTime(hour time.Time) (float64, bool) {
+	responseTime, ok := b.samples[hour.Unix()]
+	return responseTime, ok
+}
+
+func weekHour(t time.Time) int {
+	return int(t.Weekday())*24 + t.Hour()
+}
+
+func newBand(values []float64, seasonality string, min int) Band {
+	if len(values) < min {
+		return Band{}
+	}
+
+	m := median(values)
+	deviations := make([]float64, len(values))
+	for i, value := range values {
+		deviations[i] = math.Abs(value - m)
+	}
+
+	return Band{
+		Median:      m,
+		Sigma:       max(madScale*median(deviations), m*minRelativeSigma, minSigma),
+		Samples:     len(values),
+		Seasonality: seasonality,
+	}
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
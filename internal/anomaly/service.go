@@ -0,0 +1,127 @@
+// Package anomaly learns the usual response time of every site from the hourly
+// means of its successful checks in ClickHouse. A baseline keeps, for every
+// hour of the week, the median of the past response times and their median
+// absolute deviation, which a few outages or slow hours barely move; the alert
+// engine compares the live response time with the band of the current hour,
+// and the bands are charted through the metrics API. Baselines are rebuilt
+// every hour.
+package anomaly
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"ping-tower/internal/config"
+	"ping-tower/internal/metrics"
+)
+
+// maxBandHours bounds the hours of a band series.
+const maxBandHours = 31 * 24
+
+// BandPoint is the band of an hour for charting. Lower and Upper are the
+// median minus and plus the given number of sigmas; ResponseTime is the
+// measured mean of the hour, when known.
+type BandPoint struct {
+	Hour time.Time `json:"hour"`
+	Band
+	Lower        float64  `json:"lower"`
+	Upper        float64  `json:"upper"`
+	ResponseTime *float64 `json:"response_time,omitempty"`
+}
+
+type Service struct {
+	metrics *metrics.Service
+	config  config.AnomalyConfig
+
+	mu        sync.RWMutex
+	baselines map[int]*Baseline
+	updatedAt time.Time
+}
+
+func NewService(metricsService *metrics.Service, cfg config.AnomalyConfig) *Service {
+	return &Service{
+		metrics:   metricsService,
+		config:    cfg,
+		baselines: make(map[int]*Baseline),
+	}
+}
+
+// Refresh rebuilds the baselines of all sites from the hourly means.
+func (s *Service) Refresh() error {
+	responseTimes, err := s.metrics.GetHourlyResponseTimes(s.config.BaselineDays)
+	if err != nil {
+		return err
+	}
+
+	samples := make(map[int][]Sample)
+	for _, r := range responseTimes {
+		siteID := int(r.SiteID)
+		samples[siteID] = append(samples[siteID], Sample{Hour: r.Hour, ResponseTime: r.AvgResponseTime})
+	}
+
+	baselines := make(map[int]*Baseline, len(samples))
+	for siteID, siteSamples := range samples {
+		baselines[siteID] = NewBaseline(siteSamples)
+	}
+
+	s.mu.Lock()
+	s.baselines = baselines
+	s.updatedAt = time.Now()
+	s.mu.Unlock()
+
+	log.Printf("📐 Базовые линии времени отклика обновлены: %d сайтов, %d часов метрик", len(baselines), len(responseTimes))
+	return nil
+}
+
+// CreateJob returns a scheduler job that rebuilds the baselines.
+func (s *Service) CreateJob() func() error {
+	return func() error {
+		return s.Refresh()
+	}
+}
+
+// Band returns the band of the site for the hour of the time.
+func (s *Service) Band(siteID int, at time.Time) (Band, bool) {
+	s.mu.RLock()
+	baseline := s.baselines[siteID]
+	s.mu.RUnlock()
+
+	if baseline == nil {
+		return Band{}, false
+	}
+	return baseline.At(at)
+}
+
+// Bands returns the band of the site for every hour from the hour of from
+// until to; hours without a band are left out. It also returns when the
+// baselines were built.
+func (s *Service) Bands(siteID int, from, to time.Time, sigmas float64) ([]BandPoint, time.Time) {
+	s.mu.RLock()
+	baseline := s.baselines[siteID]
+	updatedAt := s.updatedAt
+	s.mu.RUnlock()
+
+	points := []BandPoint{}
+	if baseline == nil {
+		return points, updatedAt
+	}
+
+	for hour, n := from.Truncate(time.Hour), 0; hour.Before(to) && n < maxBandHours; hour, n = hour.Add(time.Hour), n+1 {
+		band, ok := baseline.At(hour)
+		if !ok {
+			continue
+		}
+		point := BandPoint{
+			Hour:  hour,
+			Band:  band,
+			Lower: max(band.Median-sigmas*band.Sigma, 0),
+			Upper: band.Median + sigmas*band.Sigma,
+		}
+		if responseTime, ok := baseline.ResponseTime(hour); ok {
+			point.ResponseTime = &responseTime
+		}
+		points = append(points, point)
+	}
+	return points, updatedAt
+}
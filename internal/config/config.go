@@ -17,6 +17,11 @@ type Config struct {
 	ClickHouse     ClickHouseConfig
 	Metrics        MetricsConfig
 	Alerts         AlertsConfig
+	AlertEngine    AlertEngineConfig
+	Snapshots      SnapshotConfig
+	Anomaly        AnomalyConfig
+	// PublicURL is the address of the web interface used in links of alerts
+	PublicURL      string
 }
 
 type ClickHouseConfig struct {
@@ -39,6 +44,11 @@ type AlertsConfig struct {
 	Email     EmailAlertConfig
 	Webhook   WebhookAlertConfig
 	Telegram  TelegramAlertConfig
+	Slack     SlackAlertConfig
+	Discord   DiscordAlertConfig
+	Teams     TeamsAlertConfig
+	PagerDuty PagerDutyAlertConfig
+	Opsgenie  OpsgenieAlertConfig
 }
 
 type EmailAlertConfig struct {
@@ -56,6 +66,7 @@ type WebhookAlertConfig struct {
 	URL     string
 	Headers map[string]string
 	Timeout int
+	Secret  string
 }
 
 type TelegramAlertConfig struct {
@@ -64,6 +75,70 @@ type TelegramAlertConfig struct {
 	ChatID   string
 }
 
+// SlackAlertConfig posts through chat.postMessage when BotToken is set, which
+// allows threaded follow-ups, and through the incoming webhook otherwise.
+type SlackAlertConfig struct {
+	Enabled    bool
+	WebhookURL string
+	BotToken   string
+	Channel    string
+}
+
+type DiscordAlertConfig struct {
+	Enabled    bool
+	WebhookURL string
+}
+
+// TeamsAlertConfig accepts an incoming webhook or a Workflows webhook URL.
+type TeamsAlertConfig struct {
+	Enabled    bool
+	WebhookURL string
+}
+
+// PagerDutyAlertConfig holds the integration key of an Events API v2 service.
+type PagerDutyAlertConfig struct {
+	Enabled    bool
+	RoutingKey string
+}
+
+// OpsgenieAlertConfig holds the key of an API integration; Region is "us" or "eu".
+type OpsgenieAlertConfig struct {
+	Enabled bool
+	APIKey  string
+	Region  string
+}
+
+// AlertEngineConfig controls how site alerts are deduplicated, detected as
+// flapping, rate limited per channel and batched into digests.
+type AlertEngineConfig struct {
+	// FlapThreshold status changes within FlapWindow make a site flapping, 0 disables detection
+	FlapThreshold int
+	FlapWindow    time.Duration
+	// RateLimit is the number of alerts per channel within RateWindow, 0 is unlimited;
+	// ChannelRateLimits overrides it for single channels
+	RateLimit         int
+	ChannelRateLimits map[string]int
+	RateWindow        time.Duration
+	// DigestEnabled batches the failures raised within DigestWindow into one message
+	DigestEnabled bool
+	DigestWindow  time.Duration
+}
+
+// SnapshotConfig limits the content snapshots stored per site.
+type SnapshotConfig struct {
+	// MaxBytes caps the stored content, longer pages are truncated
+	MaxBytes int
+	// Retention is the number of snapshots kept per site
+	Retention int
+}
+
+// AnomalyConfig controls the response time baselines of the sites.
+type AnomalyConfig struct {
+	// BaselineDays of hourly metrics make up a baseline; the hourly metrics
+	// are kept for a month
+	BaselineDays int
+}
+
 func LoadConfig() (*Config, error) {
 	err := godotenv.Load()
 	if err != nil {
@@ -123,6 +198,88 @@ func LoadConfig() (*Config, error) {
 		telegramEnabled = false
 	}
 
+	slackEnabled, err := strconv.ParseBool(getEnv("SLACK_ALERTS_ENABLED", "false"))
+	if err != nil {
+		slackEnabled = false
+	}
+
+	discordEnabled, err := strconv.ParseBool(getEnv("DISCORD_ALERTS_ENABLED", "false"))
+	if err != nil {
+		discordEnabled = false
+	}
+
+	teamsEnabled, err := strconv.ParseBool(getEnv("TEAMS_ALERTS_ENABLED", "false"))
+	if err != nil {
+		teamsEnabled = false
+	}
+
+	pagerDutyEnabled, err := strconv.ParseBool(getEnv("PAGERDUTY_ALERTS_ENABLED", "false"))
+	if err != nil {
+		pagerDutyEnabled = false
+	}
+
+	opsgenieEnabled, err := strconv.ParseBool(getEnv("OPSGENIE_ALERTS_ENABLED", "false"))
+	if err != nil {
+		opsgenieEnabled = false
+	}
+
+	flapThreshold, err := strconv.Atoi(getEnv("ALERT_FLAP_THRESHOLD", "5"))
+	if err != nil {
+		flapThreshold = 5
+	}
+
+	flapWindow, err := strconv.Atoi(getEnv("ALERT_FLAP_WINDOW_MINUTES", "30"))
+	if err != nil {
+		flapWindow = 30
+	}
+
+	rateLimit, err := strconv.Atoi(getEnv("ALERT_RATE_LIMIT", "0"))
+	if err != nil {
+		rateLimit = 0
+	}
+
+	rateWindow, err := strconv.Atoi(getEnv("ALERT_RATE_WINDOW_MINUTES", "60"))
+	if err != nil {
+		rateWindow = 60
+	}
+
+	digestEnabled, err := strconv.ParseBool(getEnv("ALERT_DIGEST_ENABLED", "false"))
+	if err != nil {
+		digestEnabled = false
+	}
+
+	digestWindow, err := strconv.Atoi(getEnv("ALERT_DIGEST_WINDOW_MINUTES", "5"))
+	if err != nil {
+		digestWindow = 5
+	}
+
+	// Parse per-channel rate limits: slack=20,email=10
+	channelRateLimits := make(map[string]int)
+	if limitsStr := getEnv("ALERT_CHANNEL_RATE_LIMITS", ""); limitsStr != "" {
+		for _, pair := range strings.Split(limitsStr, ",") {
+			if kv := strings.SplitN(pair, "=", 2); len(kv) == 2 {
+				if limit, err := strconv.Atoi(strings.TrimSpace(kv[1])); err == nil {
+					channelRateLimits[strings.TrimSpace(kv[0])] = limit
+				}
+			}
+		}
+	}
+
+	snapshotMaxKB, err := strconv.Atoi(getEnv("SNAPSHOT_MAX_KB", "512"))
+	if err != nil || snapshotMaxKB <= 0 {
+		snapshotMaxKB = 512
+	}
+
+	snapshotRetention, err := strconv.Atoi(getEnv("SNAPSHOT_RETENTION", "50"))
+	if err != nil || snapshotRetention <= 0 {
+		snapshotRetention = 50
+	}
+
+	baselineDays, err := strconv.Atoi(getEnv("ANOMALY_BASELINE_DAYS", "28"))
+	if err != nil || baselineDays <= 0 {
+		baselineDays = 28
+	}
+
 	webhookTimeout, err := strconv.Atoi(getEnv("WEBHOOK_TIMEOUT", "10"))
 	if err != nil {
 		webhookTimeout = 10
@@ -181,13 +338,54 @@ func LoadConfig() (*Config, error) {
 				URL:     getEnv("WEBHOOK_URL", ""),
 				Headers: webhookHeaders,
 				Timeout: webhookTimeout,
+				Secret:  getEnv("WEBHOOK_SECRET", ""),
 			},
 			Telegram: TelegramAlertConfig{
 				Enabled:  telegramEnabled,
 				BotToken: getEnv("TELEGRAM_BOT_TOKEN", ""),
 				ChatID:   getEnv("TELEGRAM_CHAT_ID", ""),
 			},
+			Slack: SlackAlertConfig{
+				Enabled:    slackEnabled,
+				WebhookURL: getEnv("SLACK_WEBHOOK_URL", ""),
+				BotToken:   getEnv("SLACK_BOT_TOKEN", ""),
+				Channel:    getEnv("SLACK_CHANNEL", ""),
+			},
+			Discord: DiscordAlertConfig{
+				Enabled:    discordEnabled,
+				WebhookURL: getEnv("DISCORD_WEBHOOK_URL", ""),
+			},
+			Teams: TeamsAlertConfig{
+				Enabled:    teamsEnabled,
+				WebhookURL: getEnv("TEAMS_WEBHOOK_URL", ""),
+			},
+			PagerDuty: PagerDutyAlertConfig{
+				Enabled:    pagerDutyEnabled,
+				RoutingKey: getEnv("PAGERDUTY_ROUTING_KEY", ""),
+			},
+			Opsgenie: OpsgenieAlertConfig{
+				Enabled: opsgenieEnabled,
+				APIKey:  getEnv("OPSGENIE_API_KEY", ""),
+				Region:  getEnv("OPSGENIE_REGION", "us"),
+			},
+		},
+		AlertEngine: AlertEngineConfig{
+			FlapThreshold:     flapThreshold,
+			FlapWindow:        time.Duration(flapWindow) * time.Minute,
+			RateLimit:         rateLimit,
+			ChannelRateLimits: channelRateLimits,
+			RateWindow:        time.Duration(rateWindow) * time.Minute,
+			DigestEnabled:     digestEnabled,
+			DigestWindow:      time.Duration(digestWindow) * time.Minute,
+		},
+		Snapshots: SnapshotConfig{
+			MaxBytes:  snapshotMaxKB * 1024,
+			Retention: snapshotRetention,
+		},
+		Anomaly: AnomalyConfig{
+			BaselineDays: baselineDays,
 		},
+		PublicURL: strings.TrimRight(getEnv("PUBLIC_URL", "http://localhost:"+port), "/"),
 	}, nil
 }
 
@@ -99,13 +99,33 @@ func (ch *ClickHouseDB) initializeSchema() error {
 			cache_control String DEFAULT '',
 			error_message String DEFAULT '',
 			check_type Enum8('manual' = 1, 'automatic' = 2) DEFAULT 'automatic',
-			config_version UInt32 DEFAULT 1
+			config_version UInt32 DEFAULT 1,
+			packets_sent UInt16 DEFAULT 0,
+			packets_received UInt16 DEFAULT 0,
+			packet_loss_percent Float32 DEFAULT 0,
+			rtt_min_ms Float64 DEFAULT 0,
+			rtt_avg_ms Float64 DEFAULT 0,
+			rtt_max_ms Float64 DEFAULT 0,
+			jitter_ms Float64 DEFAULT 0,
+			maintenance UInt8 DEFAULT 0
 		) ENGINE = MergeTree()
 		PARTITION BY toYYYYMM(timestamp_date)
 		ORDER BY (site_id, timestamp)
 		TTL timestamp_date + INTERVAL 3 MONTH  -- Уменьшено с 1 года до 3 месяцев
 		SETTINGS index_granularity = 8192`,
 
+		// ICMP метрики для таблиц, созданных до их появления
+		`ALTER TABLE site_metrics ADD COLUMN IF NOT EXISTS packets_sent UInt16 DEFAULT 0`,
+		`ALTER TABLE site_metrics ADD COLUMN IF NOT EXISTS packets_received UInt16 DEFAULT 0`,
+		`ALTER TABLE site_metrics ADD COLUMN IF NOT EXISTS packet_loss_percent Float32 DEFAULT 0`,
+		`ALTER TABLE site_metrics ADD COLUMN IF NOT EXISTS rtt_min_ms Float64 DEFAULT 0`,
+		`ALTER TABLE site_metrics ADD COLUMN IF NOT EXISTS rtt_avg_ms Float64 DEFAULT 0`,
+		`ALTER TABLE site_metrics ADD COLUMN IF NOT EXISTS rtt_max_ms Float64 DEFAULT 0`,
+		`ALTER TABLE site_metrics ADD COLUMN IF NOT EXISTS jitter_ms Float64 DEFAULT 0`,
+
+		// Проверки во время окна обслуживания хранятся, но не входят в аптайм
+		`ALTER TABLE site_metrics ADD COLUMN IF NOT EXISTS maintenance UInt8 DEFAULT 0`,
+
 		`CREATE MATERIALIZED VIEW IF NOT EXISTS site_metrics_hourly
 		ENGINE = SummingMergeTree()
 		PARTITION BY toYYYYMM(hour)
@@ -115,8 +135,8 @@ func (ch *ClickHouseDB) initializeSchema() error {
 			toStartOfHour(timestamp_date) as hour,
 			site_id,
 			any(site_url) as site_url,  -- Используем any() вместо site_url для экономии
-			count() as total_checks,
-			countIf(status = 'up') as successful_checks,
+			countIf(maintenance = 0) as total_checks,
+			countIf(status = 'up' AND maintenance = 0) as successful_checks,
 			avg(response_time_ms) as avg_response_time,
 			quantile(0.95)(response_time_ms) as p95_response_time,
 			min(response_time_ms) as min_response_time,
@@ -139,9 +159,9 @@ func (ch *ClickHouseDB) initializeSchema() error {
 			toDate(timestamp_date) as day,
 			site_id,
 			any(site_url) as site_url,
-			count() as total_checks,
-			countIf(status = 'up') as successful_checks,
-			(countIf(status = 'up') * 100.0 / count()) as uptime_percent,
+			countIf(maintenance = 0) as total_checks,
+			countIf(status = 'up' AND maintenance = 0) as successful_checks,
+			if(countIf(maintenance = 0) > 0, countIf(status = 'up' AND maintenance = 0) * 100.0 / countIf(maintenance = 0), 100) as uptime_percent,
 			avg(response_time_ms) as avg_response_time,
 			quantile(0.95)(response_time_ms) as p95_response_time,
 			min(response_time_ms) as min_response_time,
@@ -177,12 +197,40 @@ func (ch *ClickHouseDB) initializeSchema() error {
 			ssl_expiry DateTime,
 			days_until_expiry Int32,
 			last_checked DateTime64(3) DEFAULT now64(),
-			is_valid UInt8 DEFAULT 1
+			is_valid UInt8 DEFAULT 1,
+			check_target String DEFAULT '',
+			ssl_subject String DEFAULT '',
+			ssl_sans Array(String) DEFAULT [],
+			ssl_chain Array(String) DEFAULT [],
+			hostname_match UInt8 DEFAULT 0,
+			chain_valid UInt8 DEFAULT 0,
+			ocsp_stapled UInt8 DEFAULT 0,
+			ocsp_status String DEFAULT '',
+			tls_version String DEFAULT '',
+			tls_cipher String DEFAULT '',
+			fingerprint_sha256 String DEFAULT '',
+			previous_fingerprint String DEFAULT '',
+			fingerprint_changed UInt8 DEFAULT 0
 		) ENGINE = ReplacingMergeTree(last_checked)
 		PARTITION BY toYYYYMM(ssl_expiry)
 		ORDER BY (site_id, ssl_expiry)
 		TTL ssl_expiry + INTERVAL 1 MONTH  -- Убираем старые SSL записи через месяц после истечения
 		SETTINGS index_granularity = 8192`,
+
+		// Детали TLS для таблиц, созданных до их появления
+		`ALTER TABLE ssl_certificates ADD COLUMN IF NOT EXISTS check_target String DEFAULT ''`,
+		`ALTER TABLE ssl_certificates ADD COLUMN IF NOT EXISTS ssl_subject String DEFAULT ''`,
+		`ALTER TABLE ssl_certificates ADD COLUMN IF NOT EXISTS ssl_sans Array(String) DEFAULT []`,
+		`ALTER TABLE ssl_certificates ADD COLUMN IF NOT EXISTS ssl_chain Array(String) DEFAULT []`,
+		`ALTER TABLE ssl_certificates ADD COLUMN IF NOT EXISTS hostname_match UInt8 DEFAULT 0`,
+		`ALTER TABLE ssl_certificates ADD COLUMN IF NOT EXISTS chain_valid UInt8 DEFAULT 0`,
+		`ALTER TABLE ssl_certificates ADD COLUMN IF NOT EXISTS ocsp_stapled UInt8 DEFAULT 0`,
+		`ALTER TABLE ssl_certificates ADD COLUMN IF NOT EXISTS ocsp_status String DEFAULT ''`,
+		`ALTER TABLE ssl_certificates ADD COLUMN IF NOT EXISTS tls_version String DEFAULT ''`,
+		`ALTER TABLE ssl_certificates ADD COLUMN IF NOT EXISTS tls_cipher String DEFAULT ''`,
+		`ALTER TABLE ssl_certificates ADD COLUMN IF NOT EXISTS fingerprint_sha256 String DEFAULT ''`,
+		`ALTER TABLE ssl_certificates ADD COLUMN IF NOT EXISTS previous_fingerprint String DEFAULT ''`,
+		`ALTER TABLE ssl_certificates ADD COLUMN IF NOT EXISTS fingerprint_changed UInt8 DEFAULT 0`,
 	}
 
 	for i, schema := range schemas {
@@ -234,6 +282,17 @@ type SiteMetric struct {
 	ErrorMessage    string
 	CheckType       string
 	ConfigVersion   uint32
+
+	PacketsSent       uint16
+	PacketsReceived   uint16
+	PacketLossPercent float32
+	RTTMinMs          float64
+	RTTAvgMs          float64
+	RTTMaxMs          float64
+	JitterMs          float64
+
+	// Maintenance is 1 for checks during a maintenance window
+	Maintenance uint8
 }
 
 func (ch *ClickHouseDB) InsertMetric(metric SiteMetric) error {
@@ -244,8 +303,10 @@ func (ch *ClickHouseDB) InsertMetric(metric SiteMetric) error {
 		content_length, dns_time_ms, connect_time_ms, tls_time_ms, ttfb_ms,
 		ssl_valid, ssl_expiry, ssl_key_length, ssl_algorithm, ssl_issuer,
 		content_hash, content_type, redirect_count, final_url,
-		server_type, powered_by, cache_control, error_message, check_type, config_version
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		server_type, powered_by, cache_control, error_message, check_type, config_version,
+		packets_sent, packets_received, packet_loss_percent, rtt_min_ms, rtt_avg_ms, rtt_max_ms, jitter_ms,
+		maintenance
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	return ch.conn.Exec(ctx, query,
 		metric.Timestamp, metric.TimestampDate, metric.SiteID, metric.SiteURL, metric.Status,
@@ -256,6 +317,9 @@ func (ch *ClickHouseDB) InsertMetric(metric SiteMetric) error {
 		metric.ContentType, metric.RedirectCount, metric.FinalURL,
 		metric.ServerType, metric.PoweredBy, metric.CacheControl,
 		metric.ErrorMessage, metric.CheckType, metric.ConfigVersion,
+		metric.PacketsSent, metric.PacketsReceived, metric.PacketLossPercent,
+		metric.RTTMinMs, metric.RTTAvgMs, metric.RTTMaxMs, metric.JitterMs,
+		metric.Maintenance,
 	)
 }
 
@@ -270,7 +334,9 @@ func (ch *ClickHouseDB) InsertMetricsBatch(metrics []SiteMetric) error {
 		content_length, dns_time_ms, connect_time_ms, tls_time_ms, ttfb_ms,
 		ssl_valid, ssl_expiry, ssl_key_length, ssl_algorithm, ssl_issuer,
 		content_hash, content_type, redirect_count, final_url,
-		server_type, powered_by, cache_control, error_message, check_type, config_version
+		server_type, powered_by, cache_control, error_message, check_type, config_version,
+		packets_sent, packets_received, packet_loss_percent, rtt_min_ms, rtt_avg_ms, rtt_max_ms, jitter_ms,
+		maintenance
 	)`)
 
 	if err != nil {
@@ -287,6 +353,9 @@ func (ch *ClickHouseDB) InsertMetricsBatch(metrics []SiteMetric) error {
 			metric.ContentType, metric.RedirectCount, metric.FinalURL,
 			metric.ServerType, metric.PoweredBy, metric.CacheControl,
 			metric.ErrorMessage, metric.CheckType, metric.ConfigVersion,
+			metric.PacketsSent, metric.PacketsReceived, metric.PacketLossPercent,
+			metric.RTTMinMs, metric.RTTAvgMs, metric.RTTMaxMs, metric.JitterMs,
+			metric.Maintenance,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to append to batch: %w", err)
@@ -354,6 +423,46 @@ func (ch *ClickHouseDB) GetHourlyMetrics(siteID uint32, hours int) ([]HourlyMetr
 	return metrics, nil
 }
 
+// HourlyResponseTime is the mean response time of a site in an hour.
+type HourlyResponseTime struct {
+	SiteID          uint32
+	Hour            time.Time
+	AvgResponseTime float64
+}
+
+// GetHourlyResponseTimes returns the mean response time of the successful
+// checks of every site outside maintenance windows in the completed hours of
+// the last days, hours without a successful check left out. It reads site_metrics: the averages of
+// site_metrics_hourly are summed when SummingMergeTree merges its parts.
+func (ch *ClickHouseDB) GetHourlyResponseTimes(days int) ([]HourlyResponseTime, error) {
+	ctx := context.Background()
+
+	query := `SELECT site_id, toStartOfHour(timestamp_date) AS hour, avgIf(response_time_ms, status = 'up') AS response_time
+	FROM site_metrics
+	WHERE timestamp_date >= subtractDays(toStartOfHour(now()), ?) AND timestamp_date < toStartOfHour(now())
+		AND maintenance = 0
+	GROUP BY site_id, hour
+	HAVING countIf(status = 'up') > 0
+	ORDER BY site_id, hour`
+
+	rows, err := ch.conn.Query(ctx, query, days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query hourly response times: %w", err)
+	}
+	defer rows.Close()
+
+	var responseTimes []HourlyResponseTime
+	for rows.Next() {
+		var r HourlyResponseTime
+		if err := rows.Scan(&r.SiteID, &r.Hour, &r.AvgResponseTime); err != nil {
+			return nil, fmt.Errorf("failed to scan hourly response time: %w", err)
+		}
+		responseTimes = append(responseTimes, r)
+	}
+
+	return responseTimes, nil
+}
+
 func (ch *ClickHouseDB) RecordDowntimeEvent(siteID uint32, siteURL, errorMessage string, statusCode uint16) error {
 	ctx := context.Background()
 
@@ -376,21 +485,94 @@ func (ch *ClickHouseDB) ResolveDowntimeEvent(siteID uint32) error {
 	return ch.conn.Exec(ctx, query, siteID)
 }
 
-func (ch *ClickHouseDB) UpdateSSLCertificate(siteID uint32, siteURL, issuer, algorithm string, keyLength uint16, expiry time.Time) error {
+// SSLCertificate is a row of the ssl_certificates table.
+type SSLCertificate struct {
+	SiteID              uint32
+	SiteURL             string
+	CheckTarget         string
+	Issuer              string
+	Subject             string
+	Algorithm           string
+	KeyLength           uint16
+	Expiry              time.Time
+	SANs                []string
+	Chain               []string
+	HostnameMatch       bool
+	ChainValid          bool
+	OCSPStapled         bool
+	OCSPStatus          string
+	TLSVersion          string
+	TLSCipher           string
+	Fingerprint         string
+	PreviousFingerprint string
+}
+
+func (ch *ClickHouseDB) UpdateSSLCertificate(cert SSLCertificate) error {
 	ctx := context.Background()
 
-	daysUntilExpiry := int32(time.Until(expiry).Hours() / 24)
+	daysUntilExpiry := int32(time.Until(cert.Expiry).Hours() / 24)
 	isValid := uint8(0)
-	if time.Now().Before(expiry) {
+	if time.Now().Before(cert.Expiry) {
 		isValid = 1
 	}
 
+	fingerprintChanged := cert.PreviousFingerprint != "" && cert.PreviousFingerprint != cert.Fingerprint
+
+	if cert.SANs == nil {
+		cert.SANs = []string{}
+	}
+	if cert.Chain == nil {
+		cert.Chain = []string{}
+	}
+
 	query := `INSERT INTO ssl_certificates (
 		site_id, site_url, ssl_issuer, ssl_algorithm, ssl_key_length,
-		ssl_expiry, days_until_expiry, is_valid
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+		ssl_expiry, days_until_expiry, is_valid,
+		check_target, ssl_subject, ssl_sans, ssl_chain, hostname_match, chain_valid,
+		ocsp_stapled, ocsp_status, tls_version, tls_cipher,
+		fingerprint_sha256, previous_fingerprint, fingerprint_changed
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	return ch.conn.Exec(ctx, query,
+		cert.SiteID, cert.SiteURL, cert.Issuer, cert.Algorithm, cert.KeyLength,
+		cert.Expiry, daysUntilExpiry, isValid,
+		cert.CheckTarget, cert.Subject, cert.SANs, cert.Chain,
+		boolToUInt8(cert.HostnameMatch), boolToUInt8(cert.ChainValid),
+		boolToUInt8(cert.OCSPStapled), cert.OCSPStatus, cert.TLSVersion, cert.TLSCipher,
+		cert.Fingerprint, cert.PreviousFingerprint, boolToUInt8(fingerprintChanged),
+	)
+}
+
+// GetLatestSSLFingerprint returns the fingerprint of the most recently seen
+// certificate of the site, or an empty string if none was recorded.
+func (ch *ClickHouseDB) GetLatestSSLFingerprint(siteID uint32) (string, error) {
+	ctx := context.Background()
 
-	return ch.conn.Exec(ctx, query, siteID, siteURL, issuer, algorithm, keyLength, expiry, daysUntilExpiry, isValid)
+	query := `SELECT fingerprint_sha256 FROM ssl_certificates
+			  WHERE site_id = ? AND fingerprint_sha256 != ''
+			  ORDER BY last_checked DESC LIMIT 1`
+
+	rows, err := ch.conn.Query(ctx, query, siteID)
+	if err != nil {
+		return "", fmt.Errorf("failed to query SSL fingerprint: %w", err)
+	}
+	defer rows.Close()
+
+	var fingerprint string
+	if rows.Next() {
+		if err := rows.Scan(&fingerprint); err != nil {
+			return "", fmt.Errorf("failed to scan SSL fingerprint: %w", err)
+		}
+	}
+
+	return fingerprint, nil
+}
+
+func boolToUInt8(b bool) uint8 {
+	if b {
+		return 1
+	}
+	return 0
 }
 
 func (ch *ClickHouseDB) GetExpiringSSLCertificates(days int) ([]map[string]interface{}, error) {
@@ -0,0 +1,200 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"ping-tower/internal/models"
+	"time"
+)
+
+const escalationPolicyColumns = `id, name, COALESCE(description, ''), COALESCE(steps, '[]'), enabled, created_at, updated_at`
+
+func scanEscalationPolicy(row rowScanner) (*models.EscalationPolicy, error) {
+	var p models.EscalationPolicy
+	var stepsJSON []byte
+
+	if err := row.Scan(&p.ID, &p.Name, &p.Description, &stepsJSON, &p.Enabled, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	p.Steps = []models.EscalationStep{}
+	json.Unmarshal(stepsJSON, &p.Steps)
+
+	return &p, nil
+}
+
+func (db *DB) GetEscalationPolicies() ([]models.EscalationPolicy, error) {
+	rows, err := db.Query(`SELECT ` + escalationPolicyColumns + ` FROM escalation_policies ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения политик эскалации: %w", err)
+	}
+	defer rows.Close()
+
+	policies := []models.EscalationPolicy{}
+	for rows.Next() {
+		p, err := scanEscalationPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения политики эскалации: %w", err)
+		}
+		policies = append(policies, *p)
+	}
+
+	return policies, nil
+}
+
+func (db *DB) GetEscalationPolicy(id int) (*models.EscalationPolicy, error) {
+	p, err := scanEscalationPolicy(db.QueryRow(`SELECT `+escalationPolicyColumns+` FROM escalation_policies WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("политика эскалации не найдена")
+	}
+	return p, err
+}
+
+func (db *DB) CreateEscalationPolicy(p *models.EscalationPolicy) error {
+	stepsJSON, _ := json.Marshal(p.Steps)
+
+	query := `INSERT INTO escalation_policies (name, description, steps, enabled)
+			  VALUES ($1, $2, $3, $4)
+			  RETURNING id, created_at, updated_at`
+
+	return db.QueryRow(query, p.Name, p.Description, stepsJSON, p.Enabled).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+}
+
+func (db *DB) UpdateEscalationPolicy(p *models.EscalationPolicy) error {
+	stepsJSON, _ := json.Marshal(p.Steps)
+
+	query := `UPDATE escalation_policies SET
+			  name = $2, description = $3, steps = $4, enabled = $5, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1`
+
+	result, err := db.Exec(query, p.ID, p.Name, p.Description, stepsJSON, p.Enabled)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("политика эскалации не найдена")
+	}
+	return nil
+}
+
+func (db *DB) DeleteEscalationPolicy(id int) error {
+	result, err := db.Exec(`DELETE FROM escalation_policies WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления политики эскалации: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("политика эскалации не найдена")
+	}
+	return nil
+}
+
+const escalationColumns = `id, COALESCE(policy_id, 0), COALESCE(config_name, ''), COALESCE(site_id, 0), site_url, alert_type, alert, status,
+			  current_step, COALESCE(notified_channels, '[]'), next_step_at, acknowledged_at,
+			  COALESCE(acknowledged_by, ''), started_at, updated_at`
+
+func scanEscalation(row rowScanner) (*models.Escalation, error) {
+	var e models.Escalation
+	var alertJSON, channelsJSON []byte
+	var nextStep, acknowledged sql.NullTime
+
+	err := row.Scan(&e.ID, &e.PolicyID, &e.ConfigName, &e.SiteID, &e.SiteURL, &e.AlertType, &alertJSON, &e.Status,
+		&e.CurrentStep, &channelsJSON, &nextStep, &acknowledged,
+		&e.AcknowledgedBy, &e.StartedAt, &e.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	e.Alert = json.RawMessage(alertJSON)
+	e.NotifiedChannels = []string{}
+	json.Unmarshal(channelsJSON, &e.NotifiedChannels)
+
+	if nextStep.Valid {
+		e.NextStepAt = &nextStep.Time
+	}
+	if acknowledged.Valid {
+		e.AcknowledgedAt = &acknowledged.Time
+	}
+
+	return &e, nil
+}
+
+func (db *DB) queryEscalations(query string, args ...interface{}) ([]models.Escalation, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения эскалаций: %w", err)
+	}
+	defer rows.Close()
+
+	escalations := []models.Escalation{}
+	for rows.Next() {
+		e, err := scanEscalation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения эскалации: %w", err)
+		}
+		escalations = append(escalations, *e)
+	}
+
+	return escalations, nil
+}
+
+// GetEscalations returns escalations, newest first; an empty status means any.
+func (db *DB) GetEscalations(status string, limit int) ([]models.Escalation, error) {
+	return db.queryEscalations(`SELECT `+escalationColumns+` FROM escalations
+			  WHERE ($1 = '' OR status = $1)
+			  ORDER BY started_at DESC LIMIT $2`, status, limit)
+}
+
+func (db *DB) GetEscalation(id int) (*models.Escalation, error) {
+	e, err := scanEscalation(db.QueryRow(`SELECT `+escalationColumns+` FROM escalations WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("эскалация не найдена")
+	}
+	return e, err
+}
+
+// GetOpenEscalation returns the active or acknowledged escalation for the
+// alert source or nil.
+func (db *DB) GetOpenEscalation(siteURL string) (*models.Escalation, error) {
+	e, err := scanEscalation(db.QueryRow(`SELECT `+escalationColumns+` FROM escalations
+			  WHERE site_url = $1 AND status IN ($2, $3)
+			  ORDER BY started_at DESC LIMIT 1`,
+		siteURL, models.EscalationStatusActive, models.EscalationStatusAcknowledged))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return e, err
+}
+
+// GetDueEscalations returns active escalations whose next step is due.
+func (db *DB) GetDueEscalations(now time.Time) ([]models.Escalation, error) {
+	return db.queryEscalations(`SELECT `+escalationColumns+` FROM escalations
+			  WHERE status = $1 AND next_step_at IS NOT NULL AND next_step_at <= $2
+			  ORDER BY next_step_at`, models.EscalationStatusActive, now)
+}
+
+func (db *DB) CreateEscalation(e *models.Escalation) error {
+	channelsJSON, _ := json.Marshal(e.NotifiedChannels)
+
+	query := `INSERT INTO escalations (policy_id, config_name, site_id, site_url, alert_type, alert, status,
+			  current_step, notified_channels, next_step_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			  RETURNING id, started_at, updated_at`
+
+	return db.QueryRow(query, e.PolicyID, e.ConfigName, e.SiteID, e.SiteURL, e.AlertType, []byte(e.Alert), e.Status,
+		e.CurrentStep, channelsJSON, e.NextStepAt).Scan(&e.ID, &e.StartedAt, &e.UpdatedAt)
+}
+
+// UpdateEscalationState stores the progress of the escalation.
+func (db *DB) UpdateEscalationState(e *models.Escalation) error {
+	channelsJSON, _ := json.Marshal(e.NotifiedChannels)
+
+	query := `UPDATE escalations SET
+			  status = $2, current_step = $3, notified_channels = $4, next_step_at = $5,
+			  acknowledged_at = $6, acknowledged_by = $7, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1`
+
+	_, err := db.Exec(query, e.ID, e.Status, e.CurrentStep, channelsJSON, e.NextStepAt,
+		e.AcknowledgedAt, e.AcknowledgedBy)
+	return err
+}
@@ -0,0 +1,177 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"ping-tower/internal/models"
+	"time"
+)
+
+const heartbeatColumns = `id, name, token, period_seconds, grace_seconds, COALESCE(cron_schedule, ''),
+			  status, enabled, last_ping_at, last_start_at, COALESCE(last_duration_ms, 0),
+			  COALESCE(last_log, ''), next_due_at, created_at, updated_at`
+
+func scanHeartbeat(row rowScanner) (*models.Heartbeat, error) {
+	var hb models.Heartbeat
+	var lastPing, lastStart, nextDue sql.NullTime
+
+	err := row.Scan(&hb.ID, &hb.Name, &hb.Token, &hb.PeriodSeconds, &hb.GraceSeconds, &hb.CronSchedule,
+		&hb.Status, &hb.Enabled, &lastPing, &lastStart, &hb.LastDuration,
+		&hb.LastLog, &nextDue, &hb.CreatedAt, &hb.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastPing.Valid {
+		hb.LastPingAt = &lastPing.Time
+	}
+	if lastStart.Valid {
+		hb.LastStartAt = &lastStart.Time
+	}
+	if nextDue.Valid {
+		hb.NextDueAt = &nextDue.Time
+	}
+
+	return &hb, nil
+}
+
+func (db *DB) GetHeartbeats() ([]models.Heartbeat, error) {
+	rows, err := db.Query(`SELECT ` + heartbeatColumns + ` FROM heartbeats ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения heartbeat мониторов: %w", err)
+	}
+	defer rows.Close()
+
+	heartbeats := []models.Heartbeat{}
+	for rows.Next() {
+		hb, err := scanHeartbeat(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения heartbeat монитора: %w", err)
+		}
+		heartbeats = append(heartbeats, *hb)
+	}
+
+	return heartbeats, nil
+}
+
+func (db *DB) GetHeartbeat(id int) (*models.Heartbeat, error) {
+	hb, err := scanHeartbeat(db.QueryRow(`SELECT `+heartbeatColumns+` FROM heartbeats WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("heartbeat монитор не найден")
+	}
+	return hb, err
+}
+
+func (db *DB) GetHeartbeatByToken(token string) (*models.Heartbeat, error) {
+	hb, err := scanHeartbeat(db.QueryRow(`SELECT `+heartbeatColumns+` FROM heartbeats WHERE token = $1`, token))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("heartbeat монитор не найден")
+	}
+	return hb, err
+}
+
+func (db *DB) CreateHeartbeat(hb *models.Heartbeat) error {
+	query := `INSERT INTO heartbeats (name, token, period_seconds, grace_seconds, cron_schedule, status, enabled, next_due_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			  RETURNING id, created_at, updated_at`
+
+	return db.QueryRow(query, hb.Name, hb.Token, hb.PeriodSeconds, hb.GraceSeconds, hb.CronSchedule,
+		hb.Status, hb.Enabled, hb.NextDueAt).Scan(&hb.ID, &hb.CreatedAt, &hb.UpdatedAt)
+}
+
+func (db *DB) UpdateHeartbeat(hb *models.Heartbeat) error {
+	query := `UPDATE heartbeats SET
+			  name = $2, period_seconds = $3, grace_seconds = $4, cron_schedule = $5, enabled = $6,
+			  next_due_at = $7, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1`
+
+	result, err := db.Exec(query, hb.ID, hb.Name, hb.PeriodSeconds, hb.GraceSeconds, hb.CronSchedule,
+		hb.Enabled, hb.NextDueAt)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("heartbeat монитор не найден")
+	}
+	return nil
+}
+
+// UpdateHeartbeatState stores the state produced by a ping or a missed deadline.
+func (db *DB) UpdateHeartbeatState(hb *models.Heartbeat) error {
+	query := `UPDATE heartbeats SET
+			  status = $2, last_ping_at = $3, last_start_at = $4, last_duration_ms = $5,
+			  last_log = $6, next_due_at = $7, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1`
+
+	_, err := db.Exec(query, hb.ID, hb.Status, hb.LastPingAt, hb.LastStartAt, hb.LastDuration,
+		hb.LastLog, hb.NextDueAt)
+	return err
+}
+
+func (db *DB) DeleteHeartbeat(id int) error {
+	result, err := db.Exec(`DELETE FROM heartbeats WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления heartbeat монитора: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("heartbeat монитор не найден")
+	}
+	return nil
+}
+
+// GetOverdueHeartbeats returns enabled monitors whose deadline has passed
+// and that are not down yet.
+func (db *DB) GetOverdueHeartbeats(now time.Time) ([]models.Heartbeat, error) {
+	query := `SELECT ` + heartbeatColumns + ` FROM heartbeats
+			  WHERE enabled = TRUE AND status != $1 AND next_due_at IS NOT NULL AND next_due_at < $2`
+
+	rows, err := db.Query(query, models.HeartbeatStatusDown, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var heartbeats []models.Heartbeat
+	for rows.Next() {
+		hb, err := scanHeartbeat(rows)
+		if err != nil {
+			return nil, err
+		}
+		heartbeats = append(heartbeats, *hb)
+	}
+
+	return heartbeats, nil
+}
+
+func (db *DB) AddHeartbeatPing(ping *models.HeartbeatPing) error {
+	query := `INSERT INTO heartbeat_pings (heartbeat_id, kind, log, remote_addr, duration_ms)
+			  VALUES ($1, $2, $3, $4, $5)
+			  RETURNING id, created_at`
+
+	return db.QueryRow(query, ping.HeartbeatID, ping.Kind, ping.Log, ping.RemoteAddr, ping.Duration).
+		Scan(&ping.ID, &ping.CreatedAt)
+}
+
+func (db *DB) GetHeartbeatPings(heartbeatID int, limit int) ([]models.HeartbeatPing, error) {
+	query := `SELECT id, heartbeat_id, kind, COALESCE(log, ''), COALESCE(remote_addr, ''),
+			  COALESCE(duration_ms, 0), created_at
+			  FROM heartbeat_pings WHERE heartbeat_id = $1
+			  ORDER BY created_at DESC LIMIT $2`
+
+	rows, err := db.Query(query, heartbeatID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pings := []models.HeartbeatPing{}
+	for rows.Next() {
+		var p models.HeartbeatPing
+		if err := rows.Scan(&p.ID, &p.HeartbeatID, &p.Kind, &p.Log, &p.RemoteAddr, &p.Duration, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		pings = append(pings, p)
+	}
+
+	return pings, nil
+}
@@ -0,0 +1,197 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"ping-tower/internal/models"
+	"time"
+)
+
+const incidentColumns = `id, COALESCE(site_id, 0), site_url, status, COALESCE(title, ''), COALESCE(cause, ''),
+			  COALESCE(last_error, ''), started_at, acknowledged_at, COALESCE(acknowledged_by, ''),
+			  COALESCE(assigned_to, ''), resolved_at, COALESCE(resolved_by, ''), last_check_at,
+			  COALESCE(check_count, 0), COALESCE(failed_checks, 0), created_at, updated_at`
+
+func scanIncident(row rowScanner) (*models.Incident, error) {
+	var inc models.Incident
+	var acknowledged, resolved, lastCheck sql.NullTime
+
+	err := row.Scan(&inc.ID, &inc.SiteID, &inc.SiteURL, &inc.Status, &inc.Title, &inc.Cause,
+		&inc.LastError, &inc.StartedAt, &acknowledged, &inc.AcknowledgedBy,
+		&inc.AssignedTo, &resolved, &inc.ResolvedBy, &lastCheck,
+		&inc.CheckCount, &inc.FailedChecks, &inc.CreatedAt, &inc.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if acknowledged.Valid {
+		inc.AcknowledgedAt = &acknowledged.Time
+	}
+	if resolved.Valid {
+		inc.ResolvedAt = &resolved.Time
+	}
+	if lastCheck.Valid {
+		inc.LastCheckAt = &lastCheck.Time
+	}
+
+	return &inc, nil
+}
+
+// GetIncidents returns incidents, newest first. status may be a concrete
+// status, "active" for all unresolved incidents or empty for any; siteID 0
+// means all sites.
+func (db *DB) GetIncidents(status string, siteID int, limit int) ([]models.Incident, error) {
+	query := `SELECT ` + incidentColumns + ` FROM incidents
+			  WHERE ($1 = '' OR ($1 = 'active' AND status != 'resolved') OR status = $1)
+			  AND ($2 = 0 OR site_id = $2)
+			  ORDER BY started_at DESC LIMIT $3`
+
+	rows, err := db.Query(query, status, siteID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения инцидентов: %w", err)
+	}
+	defer rows.Close()
+
+	incidents := []models.Incident{}
+	for rows.Next() {
+		inc, err := scanIncident(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения инцидента: %w", err)
+		}
+		incidents = append(incidents, *inc)
+	}
+
+	return incidents, nil
+}
+
+func (db *DB) GetIncident(id int) (*models.Incident, error) {
+	inc, err := scanIncident(db.QueryRow(`SELECT `+incidentColumns+` FROM incidents WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("инцидент не найден")
+	}
+	return inc, err
+}
+
+// GetActiveIncident returns the unresolved incident of the site or nil.
+func (db *DB) GetActiveIncident(siteID int) (*models.Incident, error) {
+	inc, err := scanIncident(db.QueryRow(`SELECT `+incidentColumns+` FROM incidents
+			  WHERE site_id = $1 AND status != 'resolved'`, siteID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return inc, err
+}
+
+// GetRecentIncident returns the unresolved incident of the site or the one
+// resolved after since, so that a recovery alert still lands on its timeline.
+func (db *DB) GetRecentIncident(siteID int, since time.Time) (*models.Incident, error) {
+	inc, err := scanIncident(db.QueryRow(`SELECT `+incidentColumns+` FROM incidents
+			  WHERE site_id = $1 AND (status != 'resolved' OR resolved_at > $2)
+			  ORDER BY started_at DESC LIMIT 1`, siteID, since))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return inc, err
+}
+
+func (db *DB) CreateIncident(inc *models.Incident) error {
+	query := `INSERT INTO incidents (site_id, site_url, status, title, cause, last_error, started_at,
+			  last_check_at, check_count, failed_checks)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $7, $8, $9)
+			  RETURNING id, created_at, updated_at`
+
+	return db.QueryRow(query, inc.SiteID, inc.SiteURL, inc.Status, inc.Title, inc.Cause, inc.LastError,
+		inc.StartedAt, inc.CheckCount, inc.FailedChecks).Scan(&inc.ID, &inc.CreatedAt, &inc.UpdatedAt)
+}
+
+// RecordIncidentCheck counts a check made while the incident is active.
+func (db *DB) RecordIncidentCheck(id int, failed bool, errText string, checkedAt time.Time) error {
+	query := `UPDATE incidents SET
+			  check_count = check_count + 1,
+			  failed_checks = failed_checks + CASE WHEN $2 THEN 1 ELSE 0 END,
+			  last_error = CASE WHEN $2 THEN $3 ELSE last_error END,
+			  last_check_at = $4, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1`
+
+	_, err := db.Exec(query, id, failed, errText, checkedAt)
+	return err
+}
+
+func (db *DB) AcknowledgeIncident(id int, by string) error {
+	query := `UPDATE incidents SET status = $2, acknowledged_at = CURRENT_TIMESTAMP, acknowledged_by = $3,
+			  updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1 AND status = $4`
+
+	result, err := db.Exec(query, id, models.IncidentStatusAcknowledged, by, models.IncidentStatusOpen)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("инцидент не найден или уже подтвержден")
+	}
+	return nil
+}
+
+func (db *DB) AssignIncident(id int, assignee string) error {
+	query := `UPDATE incidents SET assigned_to = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+
+	result, err := db.Exec(query, id, assignee)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("инцидент не найден")
+	}
+	return nil
+}
+
+func (db *DB) ResolveIncident(id int, by string, resolvedAt time.Time) error {
+	query := `UPDATE incidents SET status = $2, resolved_at = $3, resolved_by = $4, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1 AND status != $2`
+
+	result, err := db.Exec(query, id, models.IncidentStatusResolved, resolvedAt, by)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("инцидент не найден или уже закрыт")
+	}
+	return nil
+}
+
+func (db *DB) AddIncidentEvent(event *models.IncidentEvent) error {
+	query := `INSERT INTO incident_events (incident_id, event_type, message, author, status, status_code,
+			  response_time, channel)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			  RETURNING id, created_at`
+
+	return db.QueryRow(query, event.IncidentID, event.Type, event.Message, event.Author, event.Status,
+		event.StatusCode, event.ResponseTime, event.Channel).Scan(&event.ID, &event.CreatedAt)
+}
+
+// GetIncidentEvents returns the timeline of the incident in chronological order.
+func (db *DB) GetIncidentEvents(incidentID int) ([]models.IncidentEvent, error) {
+	query := `SELECT id, incident_id, event_type, COALESCE(message, ''), COALESCE(author, ''),
+			  COALESCE(status, ''), COALESCE(status_code, 0), COALESCE(response_time, 0),
+			  COALESCE(channel, ''), created_at
+			  FROM incident_events WHERE incident_id = $1
+			  ORDER BY created_at, id`
+
+	rows, err := db.Query(query, incidentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.IncidentEvent{}
+	for rows.Next() {
+		var e models.IncidentEvent
+		if err := rows.Scan(&e.ID, &e.IncidentID, &e.Type, &e.Message, &e.Author,
+			&e.Status, &e.StatusCode, &e.ResponseTime, &e.Channel, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
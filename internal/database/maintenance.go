@@ -0,0 +1,121 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"ping-tower/internal/models"
+)
+
+const maintenanceColumns = `id, name, COALESCE(description, ''), COALESCE(scope, 'all'),
+			  COALESCE(site_ids, '[]'), COALESCE(tags, '[]'), starts_at, ends_at,
+			  COALESCE(cron_schedule, ''), COALESCE(duration_minutes, 0), enabled, created_at, updated_at`
+
+func scanMaintenanceWindow(row rowScanner) (*models.MaintenanceWindow, error) {
+	var w models.MaintenanceWindow
+	var siteIDsJSON, tagsJSON []byte
+	var startsAt, endsAt sql.NullTime
+
+	err := row.Scan(&w.ID, &w.Name, &w.Description, &w.Scope,
+		&siteIDsJSON, &tagsJSON, &startsAt, &endsAt,
+		&w.CronSchedule, &w.DurationMinutes, &w.Enabled, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	w.SiteIDs = []int{}
+	json.Unmarshal(siteIDsJSON, &w.SiteIDs)
+	w.Tags = []string{}
+	json.Unmarshal(tagsJSON, &w.Tags)
+
+	if startsAt.Valid {
+		w.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		w.EndsAt = &endsAt.Time
+	}
+
+	return &w, nil
+}
+
+func (db *DB) queryMaintenanceWindows(query string, args ...interface{}) ([]models.MaintenanceWindow, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения окон обслуживания: %w", err)
+	}
+	defer rows.Close()
+
+	windows := []models.MaintenanceWindow{}
+	for rows.Next() {
+		w, err := scanMaintenanceWindow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения окна обслуживания: %w", err)
+		}
+		windows = append(windows, *w)
+	}
+
+	return windows, nil
+}
+
+func (db *DB) GetMaintenanceWindows() ([]models.MaintenanceWindow, error) {
+	return db.queryMaintenanceWindows(`SELECT ` + maintenanceColumns + ` FROM maintenance_windows ORDER BY id`)
+}
+
+// GetEnabledMaintenanceWindows returns the windows that may be in effect:
+// enabled ones whose end (if any) has not passed yet.
+func (db *DB) GetEnabledMaintenanceWindows() ([]models.MaintenanceWindow, error) {
+	return db.queryMaintenanceWindows(`SELECT ` + maintenanceColumns + ` FROM maintenance_windows
+			  WHERE enabled = TRUE AND (ends_at IS NULL OR ends_at > CURRENT_TIMESTAMP)`)
+}
+
+func (db *DB) GetMaintenanceWindow(id int) (*models.MaintenanceWindow, error) {
+	w, err := scanMaintenanceWindow(db.QueryRow(`SELECT `+maintenanceColumns+` FROM maintenance_windows WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("окно обслуживания не найдено")
+	}
+	return w, err
+}
+
+func (db *DB) CreateMaintenanceWindow(w *models.MaintenanceWindow) error {
+	siteIDsJSON, _ := json.Marshal(w.SiteIDs)
+	tagsJSON, _ := json.Marshal(w.Tags)
+
+	query := `INSERT INTO maintenance_windows (name, description, scope, site_ids, tags, starts_at, ends_at,
+			  cron_schedule, duration_minutes, enabled)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			  RETURNING id, created_at, updated_at`
+
+	return db.QueryRow(query, w.Name, w.Description, w.Scope, siteIDsJSON, tagsJSON, w.StartsAt, w.EndsAt,
+		w.CronSchedule, w.DurationMinutes, w.Enabled).Scan(&w.ID, &w.CreatedAt, &w.UpdatedAt)
+}
+
+func (db *DB) UpdateMaintenanceWindow(w *models.MaintenanceWindow) error {
+	siteIDsJSON, _ := json.Marshal(w.SiteIDs)
+	tagsJSON, _ := json.Marshal(w.Tags)
+
+	query := `UPDATE maintenance_windows SET
+			  name = $2, description = $3, scope = $4, site_ids = $5, tags = $6, starts_at = $7, ends_at = $8,
+			  cron_schedule = $9, duration_minutes = $10, enabled = $11, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1`
+
+	result, err := db.Exec(query, w.ID, w.Name, w.Description, w.Scope, siteIDsJSON, tagsJSON, w.StartsAt, w.EndsAt,
+		w.CronSchedule, w.DurationMinutes, w.Enabled)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("окно обслуживания не найдено")
+	}
+	return nil
+}
+
+func (db *DB) DeleteMaintenanceWindow(id int) error {
+	result, err := db.Exec(`DELETE FROM maintenance_windows WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления окна обслуживания: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("окно обслуживания не найдено")
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"ping-tower/internal/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const queuedNotificationColumns = `id, COALESCE(site_id, 0), site_url, alert_type, channel, COALESCE(config_name, ''),
+			  recipients, alert, COALESCE(alerted_at, created_at), status, attempts, max_attempts, next_attempt_at,
+			  COALESCE(last_error, ''), created_at, updated_at, sent_at`
+
+func scanQueuedNotification(row rowScanner) (*models.QueuedNotification, error) {
+	var n models.QueuedNotification
+	var recipientsJSON, alertJSON []byte
+	var nextAttempt, sent sql.NullTime
+
+	err := row.Scan(&n.ID, &n.SiteID, &n.SiteURL, &n.AlertType, &n.Channel, &n.ConfigName,
+		&recipientsJSON, &alertJSON, &n.AlertedAt, &n.Status, &n.Attempts, &n.MaxAttempts, &nextAttempt, &n.LastError,
+		&n.CreatedAt, &n.UpdatedAt, &sent)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(recipientsJSON) > 0 {
+		n.Recipients = json.RawMessage(recipientsJSON)
+	}
+	n.Alert = json.RawMessage(alertJSON)
+
+	if nextAttempt.Valid {
+		n.NextAttemptAt = &nextAttempt.Time
+	}
+	if sent.Valid {
+		n.SentAt = &sent.Time
+	}
+
+	return &n, nil
+}
+
+func (db *DB) queryQueuedNotifications(query string, args ...interface{}) ([]models.QueuedNotification, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения очереди оповещений: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []models.QueuedNotification{}
+	for rows.Next() {
+		n, err := scanQueuedNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения оповещения из очереди: %w", err)
+		}
+		notifications = append(notifications, *n)
+	}
+
+	return notifications, nil
+}
+
+// GetQueuedNotifications returns queued notifications, newest first; an empty
+// status means any.
+func (db *DB) GetQueuedNotifications(status string, limit int) ([]models.QueuedNotification, error) {
+	return db.queryQueuedNotifications(`SELECT `+queuedNotificationColumns+` FROM notification_queue
+			  WHERE ($1 = '' OR status = $1)
+			  ORDER BY created_at DESC LIMIT $2`, status, limit)
+}
+
+func (db *DB) GetQueuedNotification(id int) (*models.QueuedNotification, error) {
+	n, err := scanQueuedNotification(db.QueryRow(`SELECT `+queuedNotificationColumns+` FROM notification_queue WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("оповещение в очереди не найдено")
+	}
+	return n, err
+}
+
+// GetDueNotifications returns pending notifications whose next attempt is due.
+func (db *DB) GetDueNotifications(now time.Time, limit int) ([]models.QueuedNotification, error) {
+	return db.queryQueuedNotifications(`SELECT `+queuedNotificationColumns+` FROM notification_queue
+			  WHERE status = $1 AND next_attempt_at IS NOT NULL AND next_attempt_at <= $2
+			  ORDER BY next_attempt_at LIMIT $3`, models.NotificationStatusPending, now, limit)
+}
+
+func (db *DB) EnqueueNotification(n *models.QueuedNotification) error {
+	var recipients interface{}
+	if len(n.Recipients) > 0 {
+		recipients = []byte(n.Recipients)
+	}
+
+	query := `INSERT INTO notification_queue (site_id, site_url, alert_type, channel, config_name, recipients, alert,
+			  alerted_at, status, attempts, max_attempts, next_attempt_at, last_error)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			  RETURNING id, created_at, updated_at`
+
+	return db.QueryRow(query, n.SiteID, n.SiteURL, n.AlertType, n.Channel, n.ConfigName, recipients, []byte(n.Alert),
+		n.AlertedAt, n.Status, n.Attempts, n.MaxAttempts, n.NextAttemptAt, n.LastError).Scan(&n.ID, &n.CreatedAt, &n.UpdatedAt)
+}
+
+// UpdateNotificationState stores the outcome of a delivery attempt.
+func (db *DB) UpdateNotificationState(n *models.QueuedNotification) error {
+	query := `UPDATE notification_queue SET
+			  status = $2, attempts = $3, next_attempt_at = $4, last_error = $5, sent_at = $6,
+			  updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1`
+
+	_, err := db.Exec(query, n.ID, n.Status, n.Attempts, n.NextAttemptAt, n.LastError, n.SentAt)
+	return err
+}
+
+// SupersedeNotifications marks the pending and dead notifications of alerts
+// of the given types raised before the given time as superseded. They are
+// matched by site, channel and alert configuration; alerts without a site by
+// their URL.
+func (db *DB) SupersedeNotifications(siteID int, siteURL string, alertTypes []string, channel, configName string, before time.Time) (int64, error) {
+	query := `UPDATE notification_queue SET status = $1, next_attempt_at = NULL, updated_at = CURRENT_TIMESTAMP
+			  WHERE status IN ($2, $3) AND site_id = $4 AND ($4 <> 0 OR site_url = $5) AND alert_type = ANY($6)
+			  AND channel = $7 AND COALESCE(config_name, '') = $8 AND COALESCE(alerted_at, created_at) < $9`
+
+	result, err := db.Exec(query, models.NotificationStatusSuperseded, models.NotificationStatusPending,
+		models.NotificationStatusDead, siteID, siteURL, pq.Array(alertTypes), channel, configName, before)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка отмены устаревших оповещений: %w", err)
+	}
+	return result.RowsAffected()
+}
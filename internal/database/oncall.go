@@ -0,0 +1,208 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"ping-tower/internal/models"
+	"time"
+)
+
+const onCallUserColumns = `id, name, COALESCE(email, ''), COALESCE(telegram_chat_id, ''), COALESCE(webhook_url, ''),
+			  created_at, updated_at`
+
+func scanOnCallUser(row rowScanner) (*models.OnCallUser, error) {
+	var u models.OnCallUser
+	if err := row.Scan(&u.ID, &u.Name, &u.Email, &u.TelegramChatID, &u.WebhookURL, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (db *DB) GetOnCallUsers() ([]models.OnCallUser, error) {
+	rows, err := db.Query(`SELECT ` + onCallUserColumns + ` FROM oncall_users ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения дежурных: %w", err)
+	}
+	defer rows.Close()
+
+	users := []models.OnCallUser{}
+	for rows.Next() {
+		u, err := scanOnCallUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения дежурного: %w", err)
+		}
+		users = append(users, *u)
+	}
+
+	return users, nil
+}
+
+func (db *DB) GetOnCallUser(id int) (*models.OnCallUser, error) {
+	u, err := scanOnCallUser(db.QueryRow(`SELECT `+onCallUserColumns+` FROM oncall_users WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("дежурный не найден")
+	}
+	return u, err
+}
+
+func (db *DB) CreateOnCallUser(u *models.OnCallUser) error {
+	query := `INSERT INTO oncall_users (name, email, telegram_chat_id, webhook_url)
+			  VALUES ($1, $2, $3, $4)
+			  RETURNING id, created_at, updated_at`
+
+	return db.QueryRow(query, u.Name, u.Email, u.TelegramChatID, u.WebhookURL).Scan(&u.ID, &u.CreatedAt, &u.UpdatedAt)
+}
+
+func (db *DB) UpdateOnCallUser(u *models.OnCallUser) error {
+	query := `UPDATE oncall_users SET
+			  name = $2, email = $3, telegram_chat_id = $4, webhook_url = $5, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1`
+
+	result, err := db.Exec(query, u.ID, u.Name, u.Email, u.TelegramChatID, u.WebhookURL)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("дежурный не найден")
+	}
+	return nil
+}
+
+func (db *DB) DeleteOnCallUser(id int) error {
+	result, err := db.Exec(`DELETE FROM oncall_users WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления дежурного: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("дежурный не найден")
+	}
+	return nil
+}
+
+const onCallScheduleColumns = `id, name, COALESCE(description, ''), COALESCE(timezone, 'UTC'), COALESCE(layers, '[]'),
+			  enabled, created_at, updated_at`
+
+func scanOnCallSchedule(row rowScanner) (*models.OnCallSchedule, error) {
+	var s models.OnCallSchedule
+	var layersJSON []byte
+
+	if err := row.Scan(&s.ID, &s.Name, &s.Description, &s.Timezone, &layersJSON, &s.Enabled, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	s.Layers = []models.OnCallLayer{}
+	json.Unmarshal(layersJSON, &s.Layers)
+
+	return &s, nil
+}
+
+func (db *DB) GetOnCallSchedules() ([]models.OnCallSchedule, error) {
+	rows, err := db.Query(`SELECT ` + onCallScheduleColumns + ` FROM oncall_schedules ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения расписаний дежурств: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []models.OnCallSchedule{}
+	for rows.Next() {
+		s, err := scanOnCallSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения расписания дежурств: %w", err)
+		}
+		schedules = append(schedules, *s)
+	}
+
+	return schedules, nil
+}
+
+func (db *DB) GetOnCallSchedule(id int) (*models.OnCallSchedule, error) {
+	s, err := scanOnCallSchedule(db.QueryRow(`SELECT `+onCallScheduleColumns+` FROM oncall_schedules WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("расписание дежурств не найдено")
+	}
+	return s, err
+}
+
+func (db *DB) CreateOnCallSchedule(s *models.OnCallSchedule) error {
+	layersJSON, _ := json.Marshal(s.Layers)
+
+	query := `INSERT INTO oncall_schedules (name, description, timezone, layers, enabled)
+			  VALUES ($1, $2, $3, $4, $5)
+			  RETURNING id, created_at, updated_at`
+
+	return db.QueryRow(query, s.Name, s.Description, s.Timezone, layersJSON, s.Enabled).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+}
+
+func (db *DB) UpdateOnCallSchedule(s *models.OnCallSchedule) error {
+	layersJSON, _ := json.Marshal(s.Layers)
+
+	query := `UPDATE oncall_schedules SET
+			  name = $2, description = $3, timezone = $4, layers = $5, enabled = $6, updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1`
+
+	result, err := db.Exec(query, s.ID, s.Name, s.Description, s.Timezone, layersJSON, s.Enabled)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("расписание дежурств не найдено")
+	}
+	return nil
+}
+
+func (db *DB) DeleteOnCallSchedule(id int) error {
+	result, err := db.Exec(`DELETE FROM oncall_schedules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления расписания дежурств: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("расписание дежурств не найдено")
+	}
+	return nil
+}
+
+// GetOnCallOverrides returns the overrides of the schedule that overlap
+// [from, to), in creation order so that later ones win.
+func (db *DB) GetOnCallOverrides(scheduleID int, from, to time.Time) ([]models.OnCallOverride, error) {
+	query := `SELECT id, schedule_id, user_id, starts_at, ends_at, COALESCE(reason, ''), created_at
+			  FROM oncall_overrides
+			  WHERE schedule_id = $1 AND ends_at > $2 AND starts_at < $3
+			  ORDER BY created_at, id`
+
+	rows, err := db.Query(query, scheduleID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения замен: %w", err)
+	}
+	defer rows.Close()
+
+	overrides := []models.OnCallOverride{}
+	for rows.Next() {
+		var o models.OnCallOverride
+		if err := rows.Scan(&o.ID, &o.ScheduleID, &o.UserID, &o.StartsAt, &o.EndsAt, &o.Reason, &o.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка чтения замены: %w", err)
+		}
+		overrides = append(overrides, o)
+	}
+
+	return overrides, nil
+}
+
+func (db *DB) CreateOnCallOverride(o *models.OnCallOverride) error {
+	query := `INSERT INTO oncall_overrides (schedule_id, user_id, starts_at, ends_at, reason)
+			  VALUES ($1, $2, $3, $4, $5)
+			  RETURNING id, created_at`
+
+	return db.QueryRow(query, o.ScheduleID, o.UserID, o.StartsAt, o.EndsAt, o.Reason).Scan(&o.ID, &o.CreatedAt)
+}
+
+func (db *DB) DeleteOnCallOverride(scheduleID, id int) error {
+	result, err := db.Exec(`DELETE FROM oncall_overrides WHERE id = $1 AND schedule_id = $2`, id, scheduleID)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления замены: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("замена не найдена")
+	}
+	return nil
+}
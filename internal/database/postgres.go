@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"ping-tower/internal/models"
+	"strings"
 
 	_ "github.com/lib/pq"
 )
@@ -73,7 +74,10 @@ func (db *DB) GetSiteByURL(url string) (*models.Site, error) {
               COALESCE(server_type, '') as server_type,
               COALESCE(powered_by, '') as powered_by,
               COALESCE(content_type, '') as content_type,
-              COALESCE(cache_control, '') as cache_control
+              COALESCE(cache_control, '') as cache_control,
+              COALESCE(pending_status, '') as pending_status,
+              COALESCE(consecutive_failures, 0) as consecutive_failures,
+              COALESCE(consecutive_successes, 0) as consecutive_successes
               FROM sites WHERE url = $1`
     
     err := db.QueryRow(query, url).Scan(
@@ -84,7 +88,8 @@ func (db *DB) GetSiteByURL(url string) (*models.Site, error) {
         &site.DNSTime, &site.ConnectTime, &site.TLSTime, &site.TTFB,
         &site.ContentHash, &site.RedirectCount, &site.FinalURL,
         &site.SSLKeyLength, &site.SSLAlgorithm, &site.SSLIssuer,
-        &site.ServerType, &site.PoweredBy, &site.ContentType, &site.CacheControl)
+        &site.ServerType, &site.PoweredBy, &site.ContentType, &site.CacheControl,
+        &site.PendingStatus, &site.ConsecutiveFailures, &site.ConsecutiveSuccesses)
     
     if err != nil {
         if err == sql.ErrNoRows {
@@ -113,7 +118,7 @@ func (db *DB) AddSite(url string) error {
 
 func (db *DB) GetSiteConfig(siteID int) (*models.SiteConfig, error) {
 	var config models.SiteConfig
-	var headersJSON []byte
+	var headersJSON, stepsJSON, tagsJSON []byte
 	
 	query := `SELECT site_id, check_interval, timeout, expected_status, follow_redirects, 
 			  max_redirects, check_ssl, ssl_alert_days, check_keywords, avoid_keywords,
@@ -127,6 +132,18 @@ func (db *DB) GetSiteConfig(siteID int) (*models.SiteConfig, error) {
 			  COALESCE(show_ssl_info, TRUE), COALESCE(show_server_info, FALSE),
 			  COALESCE(show_performance, FALSE), COALESCE(show_redirect_info, FALSE),
 			  COALESCE(show_content_info, FALSE),
+			  COALESCE(check_type, 'http'), COALESCE(target, ''), COALESCE(connect_timeout, 10),
+			  COALESCE(tcp_send, ''), COALESCE(tcp_expect, ''),
+			  COALESCE(ping_count, 4), COALESCE(ping_interval, 1000), COALESCE(max_packet_loss, 0),
+			  COALESCE(dns_record_type, 'A'), COALESCE(dns_resolver, ''), COALESCE(dns_expected, ''),
+			  COALESCE(tls_starttls, ''), COALESCE(tls_server_name, ''), COALESCE(tls_allow_untrusted, FALSE),
+			  COALESCE(steps, '[]'), COALESCE(json_assertions, ''),
+			  COALESCE(method, 'GET'), COALESCE(request_body, ''), COALESCE(request_content_type, ''),
+			  COALESCE(expected_status_codes, ''), COALESCE(header_assertions, ''),
+			  COALESCE(fail_threshold, 1), COALESCE(recover_threshold, 1),
+			  COALESCE(retry_on_failure, FALSE), COALESCE(retry_delay, 5),
+			  COALESCE(tags, '[]'), COALESCE(escalation_policy_id, 0),
+			  COALESCE(ssl_alert_thresholds, ''), COALESCE(content_masks, ''),
 			  created_at, updated_at FROM site_configs WHERE site_id = $1`
 	
 	err := db.QueryRow(query, siteID).Scan(
@@ -140,6 +157,18 @@ func (db *DB) GetSiteConfig(siteID int) (*models.SiteConfig, error) {
 		&config.ShowResponseTime, &config.ShowContentLength, &config.ShowUptime,
 		&config.ShowSSLInfo, &config.ShowServerInfo, &config.ShowPerformance,
 		&config.ShowRedirectInfo, &config.ShowContentInfo,
+		&config.CheckType, &config.Target, &config.ConnectTimeout,
+		&config.TCPSend, &config.TCPExpect,
+		&config.PingCount, &config.PingInterval, &config.MaxPacketLoss,
+		&config.DNSRecordType, &config.DNSResolver, &config.DNSExpected,
+		&config.TLSStartTLS, &config.TLSServerName, &config.TLSAllowUntrusted,
+		&stepsJSON, &config.JSONAssertions,
+		&config.Method, &config.RequestBody, &config.RequestContentType,
+		&config.ExpectedStatusCodes, &config.HeaderAssertions,
+		&config.FailThreshold, &config.RecoverThreshold,
+		&config.RetryOnFailure, &config.RetryDelay,
+		&tagsJSON, &config.EscalationPolicyID,
+		&config.SSLAlertThresholds, &config.ContentMasks,
 		&config.CreatedAt, &config.UpdatedAt)
 	
 	if err != nil {
@@ -153,12 +182,55 @@ func (db *DB) GetSiteConfig(siteID int) (*models.SiteConfig, error) {
 	if len(headersJSON) > 0 {
 		json.Unmarshal(headersJSON, &config.Headers)
 	}
+
+	config.Steps = []models.HTTPStep{}
+	if len(stepsJSON) > 0 {
+		json.Unmarshal(stepsJSON, &config.Steps)
+	}
+
+	config.Tags = []string{}
+	if len(tagsJSON) > 0 {
+		json.Unmarshal(tagsJSON, &config.Tags)
+	}
 	
 	return &config, nil
 }
 
 func (db *DB) UpdateSiteConfig(config *models.SiteConfig) error {
 	headersJSON, _ := json.Marshal(config.Headers)
+
+	steps := config.Steps
+	if steps == nil {
+		steps = []models.HTTPStep{}
+	}
+	stepsJSON, _ := json.Marshal(steps)
+
+	tags := config.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	tagsJSON, _ := json.Marshal(tags)
+
+	checkType := config.CheckType
+	if checkType == "" {
+		checkType = models.CheckTypeHTTP
+	}
+	dnsRecordType := strings.ToUpper(config.DNSRecordType)
+	if dnsRecordType == "" {
+		dnsRecordType = "A"
+	}
+	method := strings.ToUpper(config.Method)
+	if method == "" {
+		method = "GET"
+	}
+	failThreshold := config.FailThreshold
+	if failThreshold < 1 {
+		failThreshold = 1
+	}
+	recoverThreshold := config.RecoverThreshold
+	if recoverThreshold < 1 {
+		recoverThreshold = 1
+	}
 	
 	query := `UPDATE site_configs SET 
 			  check_interval = $2, timeout = $3, expected_status = $4, follow_redirects = $5,
@@ -171,6 +243,18 @@ func (db *DB) UpdateSiteConfig(config *models.SiteConfig) error {
 			  show_response_time = $25, show_content_length = $26, show_uptime = $27,
 			  show_ssl_info = $28, show_server_info = $29, show_performance = $30,
 			  show_redirect_info = $31, show_content_info = $32,
+			  check_type = $33, target = $34, connect_timeout = $35,
+			  tcp_send = $36, tcp_expect = $37,
+			  ping_count = $38, ping_interval = $39, max_packet_loss = $40,
+			  dns_record_type = $41, dns_resolver = $42, dns_expected = $43,
+			  tls_starttls = $44, tls_server_name = $45, tls_allow_untrusted = $46,
+			  steps = $47, json_assertions = $48,
+			  method = $49, request_body = $50, request_content_type = $51,
+			  expected_status_codes = $52, header_assertions = $53,
+			  fail_threshold = $54, recover_threshold = $55,
+			  retry_on_failure = $56, retry_delay = $57,
+			  tags = $58, escalation_policy_id = $59,
+			  ssl_alert_thresholds = $60, content_masks = $61,
 			  updated_at = CURRENT_TIMESTAMP
 			  WHERE site_id = $1`
 	
@@ -183,7 +267,19 @@ func (db *DB) UpdateSiteConfig(config *models.SiteConfig) error {
 		config.CollectSSLDetails, config.CollectServerInfo, config.CollectHeaders,
 		config.ShowResponseTime, config.ShowContentLength, config.ShowUptime,
 		config.ShowSSLInfo, config.ShowServerInfo, config.ShowPerformance,
-		config.ShowRedirectInfo, config.ShowContentInfo)
+		config.ShowRedirectInfo, config.ShowContentInfo,
+		checkType, config.Target, config.ConnectTimeout,
+		config.TCPSend, config.TCPExpect,
+		config.PingCount, config.PingInterval, config.MaxPacketLoss,
+		dnsRecordType, config.DNSResolver, config.DNSExpected,
+		strings.ToLower(config.TLSStartTLS), config.TLSServerName, config.TLSAllowUntrusted,
+		stepsJSON, config.JSONAssertions,
+		method, config.RequestBody, config.RequestContentType,
+		config.ExpectedStatusCodes, config.HeaderAssertions,
+		failThreshold, recoverThreshold,
+		config.RetryOnFailure, config.RetryDelay,
+		tagsJSON, config.EscalationPolicyID,
+		config.SSLAlertThresholds, config.ContentMasks)
 	
 	return err
 }
@@ -219,6 +315,9 @@ func (db *DB) GetAllSites() ([]models.Site, error) {
 				COALESCE(s.powered_by, '') as powered_by,
 				COALESCE(s.content_type, '') as content_type,
 				COALESCE(s.cache_control, '') as cache_control,
+				COALESCE(s.pending_status, '') as pending_status,
+				COALESCE(s.consecutive_failures, 0) as consecutive_failures,
+				COALESCE(s.consecutive_successes, 0) as consecutive_successes,
 				c.enabled
 			  FROM sites s
 			  LEFT JOIN site_configs c ON s.id = c.site_id
@@ -244,6 +343,7 @@ func (db *DB) GetAllSites() ([]models.Site, error) {
 			&site.ContentHash, &site.RedirectCount, &site.FinalURL,
 			&site.SSLKeyLength, &site.SSLAlgorithm, &site.SSLIssuer,
 			&site.ServerType, &site.PoweredBy, &site.ContentType, &site.CacheControl,
+			&site.PendingStatus, &site.ConsecutiveFailures, &site.ConsecutiveSuccesses,
 			&enabled)
 		if err != nil {
 			return nil, fmt.Errorf("Ошибка чтения данных сайта: %w", err)
@@ -260,7 +360,9 @@ func (db *DB) GetAllSites() ([]models.Site, error) {
 }
 
 func (db *DB) GetSiteHistory(siteID int, limit int) ([]models.SiteHistory, error) {
-    query := `SELECT id, site_id, status, status_code, response_time, error, checked_at 
+    query := `SELECT id, site_id, status, status_code, response_time, error,
+              COALESCE(failed_step, ''), COALESCE(step_results, '[]'),
+              COALESCE(maintenance, FALSE), checked_at 
               FROM site_history 
               WHERE site_id = $1 
               ORDER BY checked_at DESC 
@@ -275,10 +377,15 @@ func (db *DB) GetSiteHistory(siteID int, limit int) ([]models.SiteHistory, error
     var history []models.SiteHistory
     for rows.Next() {
         var h models.SiteHistory
-        err := rows.Scan(&h.ID, &h.SiteID, &h.Status, &h.StatusCode, &h.ResponseTime, &h.Error, &h.CheckedAt)
+        var stepsJSON []byte
+        err := rows.Scan(&h.ID, &h.SiteID, &h.Status, &h.StatusCode, &h.ResponseTime, &h.Error,
+            &h.FailedStep, &stepsJSON, &h.Maintenance, &h.CheckedAt)
         if err != nil {
             return nil, fmt.Errorf("Ошибка чтения истории: %w", err)
         }
+        if len(stepsJSON) > 0 {
+            json.Unmarshal(stepsJSON, &h.Steps)
+        }
         history = append(history, h)
     }
 
@@ -326,31 +433,58 @@ func (db *DB) TriggerCheck() error {
 }
 
 // Alert configuration functions
-func (db *DB) GetAlertConfig(name string) (*models.AlertConfig, error) {
-	var config models.AlertConfig
-	var webhookHeadersJSON []byte
 
-	query := `SELECT id, name, enabled, email_enabled, webhook_enabled, telegram_enabled,
+// alertConfigColumns is the column list shared by every alert_configs SELECT,
+// it must stay in sync with scanAlertConfig.
+const alertConfigColumns = `id, name, enabled, email_enabled, webhook_enabled, telegram_enabled,
 			  smtp_server, smtp_port, smtp_username, smtp_password, email_from, email_to,
 			  webhook_url, COALESCE(webhook_headers, '{}'), webhook_timeout,
 			  telegram_bot_token, telegram_chat_id,
 			  alert_on_down, alert_on_up, alert_on_ssl_expiry, ssl_expiry_days,
 			  alert_on_status_code_change, alert_on_response_time_threshold, response_time_threshold,
-			  created_at, updated_at FROM alert_configs WHERE name = $1`
+			  COALESCE(alert_on_packet_loss, FALSE), COALESCE(packet_loss_threshold, 0),
+			  COALESCE(escalation_policy_id, 0), COALESCE(oncall_schedule_id, 0),
+			  COALESCE(slack_enabled, FALSE), COALESCE(slack_webhook_url, ''), COALESCE(slack_bot_token, ''),
+			  COALESCE(slack_channel, ''),
+			  COALESCE(discord_enabled, FALSE), COALESCE(discord_webhook_url, ''),
+			  COALESCE(teams_enabled, FALSE), COALESCE(teams_webhook_url, ''),
+			  COALESCE(pagerduty_enabled, FALSE), COALESCE(pagerduty_routing_key, ''),
+			  COALESCE(opsgenie_enabled, FALSE), COALESCE(opsgenie_api_key, ''), COALESCE(opsgenie_region, 'us'),
+			  COALESCE(webhook_secret, ''),
+			  COALESCE(response_time_checks, 1), COALESCE(alert_on_content_change, FALSE),
+			  COALESCE(alert_on_redirect_change, FALSE), COALESCE(alert_on_keyword_lost, FALSE),
+			  COALESCE(alert_on_response_time_anomaly, FALSE), COALESCE(response_time_anomaly_sigmas, 3),
+			  created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
-	err := db.QueryRow(query, name).Scan(
+func scanAlertConfig(row rowScanner) (*models.AlertConfig, error) {
+	var config models.AlertConfig
+	var webhookHeadersJSON []byte
+
+	err := row.Scan(
 		&config.ID, &config.Name, &config.Enabled, &config.EmailEnabled, &config.WebhookEnabled, &config.TelegramEnabled,
 		&config.SMTPServer, &config.SMTPPort, &config.SMTPUsername, &config.SMTPPassword, &config.EmailFrom, &config.EmailTo,
 		&config.WebhookURL, &webhookHeadersJSON, &config.WebhookTimeout,
 		&config.TelegramBotToken, &config.TelegramChatID,
 		&config.AlertOnDown, &config.AlertOnUp, &config.AlertOnSSLExpiry, &config.SSLExpiryDays,
 		&config.AlertOnStatusCodeChange, &config.AlertOnResponseTimeThreshold, &config.ResponseTimeThreshold,
+		&config.AlertOnPacketLoss, &config.PacketLossThreshold,
+		&config.EscalationPolicyID, &config.OnCallScheduleID,
+		&config.SlackEnabled, &config.SlackWebhookURL, &config.SlackBotToken,
+		&config.SlackChannel,
+		&config.DiscordEnabled, &config.DiscordWebhookURL,
+		&config.TeamsEnabled, &config.TeamsWebhookURL,
+		&config.PagerDutyEnabled, &config.PagerDutyRoutingKey,
+		&config.OpsgenieEnabled, &config.OpsgenieAPIKey, &config.OpsgenieRegion,
+		&config.WebhookSecret,
+		&config.ResponseTimeChecks, &config.AlertOnContentChange,
+		&config.AlertOnRedirectChange, &config.AlertOnKeywordLost,
+		&config.AlertOnResponseTimeAnomaly, &config.ResponseTimeAnomalySigmas,
 		&config.CreatedAt, &config.UpdatedAt)
-
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("конфигурация алертов не найдена")
-		}
 		return nil, err
 	}
 
@@ -362,6 +496,20 @@ func (db *DB) GetAlertConfig(name string) (*models.AlertConfig, error) {
 	return &config, nil
 }
 
+func (db *DB) GetAlertConfig(name string) (*models.AlertConfig, error) {
+	query := `SELECT ` + alertConfigColumns + ` FROM alert_configs WHERE name = $1`
+
+	config, err := scanAlertConfig(db.QueryRow(query, name))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("конфигурация алертов не найдена")
+		}
+		return nil, err
+	}
+
+	return config, nil
+}
+
 func (db *DB) UpdateAlertConfig(config *models.AlertConfig) error {
 	webhookHeadersJSON, _ := json.Marshal(config.WebhookHeaders)
 
@@ -373,7 +521,18 @@ func (db *DB) UpdateAlertConfig(config *models.AlertConfig) error {
 			  telegram_bot_token = $15, telegram_chat_id = $16,
 			  alert_on_down = $17, alert_on_up = $18, alert_on_ssl_expiry = $19, ssl_expiry_days = $20,
 			  alert_on_status_code_change = $21, alert_on_response_time_threshold = $22,
-			  response_time_threshold = $23, updated_at = CURRENT_TIMESTAMP
+			  response_time_threshold = $23,
+			  alert_on_packet_loss = $24, packet_loss_threshold = $25,
+			  escalation_policy_id = $26, oncall_schedule_id = $27,
+			  slack_enabled = $28, slack_webhook_url = $29, slack_bot_token = $30, slack_channel = $31,
+			  discord_enabled = $32, discord_webhook_url = $33, teams_enabled = $34, teams_webhook_url = $35,
+			  pagerduty_enabled = $36, pagerduty_routing_key = $37,
+			  opsgenie_enabled = $38, opsgenie_api_key = $39, opsgenie_region = $40,
+			  webhook_secret = $41,
+			  response_time_checks = $42, alert_on_content_change = $43,
+			  alert_on_redirect_change = $44, alert_on_keyword_lost = $45,
+			  alert_on_response_time_anomaly = $46, response_time_anomaly_sigmas = $47,
+			  updated_at = CURRENT_TIMESTAMP
 			  WHERE name = $1`
 
 	_, err := db.Exec(query, config.Name, config.Enabled, config.EmailEnabled, config.WebhookEnabled, config.TelegramEnabled,
@@ -382,19 +541,23 @@ func (db *DB) UpdateAlertConfig(config *models.AlertConfig) error {
 		config.WebhookURL, webhookHeadersJSON, config.WebhookTimeout,
 		config.TelegramBotToken, config.TelegramChatID,
 		config.AlertOnDown, config.AlertOnUp, config.AlertOnSSLExpiry, config.SSLExpiryDays,
-		config.AlertOnStatusCodeChange, config.AlertOnResponseTimeThreshold, config.ResponseTimeThreshold)
+		config.AlertOnStatusCodeChange, config.AlertOnResponseTimeThreshold, config.ResponseTimeThreshold,
+		config.AlertOnPacketLoss, config.PacketLossThreshold,
+		config.EscalationPolicyID, config.OnCallScheduleID,
+		config.SlackEnabled, config.SlackWebhookURL, config.SlackBotToken, config.SlackChannel,
+		config.DiscordEnabled, config.DiscordWebhookURL, config.TeamsEnabled, config.TeamsWebhookURL,
+		config.PagerDutyEnabled, config.PagerDutyRoutingKey,
+		config.OpsgenieEnabled, config.OpsgenieAPIKey, config.OpsgenieRegion,
+		config.WebhookSecret,
+		config.ResponseTimeChecks, config.AlertOnContentChange,
+		config.AlertOnRedirectChange, config.AlertOnKeywordLost,
+		config.AlertOnResponseTimeAnomaly, config.ResponseTimeAnomalySigmas)
 
 	return err
 }
 
 func (db *DB) GetAllAlertConfigs() ([]models.AlertConfig, error) {
-	query := `SELECT id, name, enabled, email_enabled, webhook_enabled, telegram_enabled,
-			  smtp_server, smtp_port, smtp_username, smtp_password, email_from, email_to,
-			  webhook_url, COALESCE(webhook_headers, '{}'), webhook_timeout,
-			  telegram_bot_token, telegram_chat_id,
-			  alert_on_down, alert_on_up, alert_on_ssl_expiry, ssl_expiry_days,
-			  alert_on_status_code_change, alert_on_response_time_threshold, response_time_threshold,
-			  created_at, updated_at FROM alert_configs ORDER BY created_at`
+	query := `SELECT ` + alertConfigColumns + ` FROM alert_configs ORDER BY created_at`
 
 	rows, err := db.Query(query)
 	if err != nil {
@@ -404,27 +567,12 @@ func (db *DB) GetAllAlertConfigs() ([]models.AlertConfig, error) {
 
 	var configs []models.AlertConfig
 	for rows.Next() {
-		var config models.AlertConfig
-		var webhookHeadersJSON []byte
-
-		err := rows.Scan(
-			&config.ID, &config.Name, &config.Enabled, &config.EmailEnabled, &config.WebhookEnabled, &config.TelegramEnabled,
-			&config.SMTPServer, &config.SMTPPort, &config.SMTPUsername, &config.SMTPPassword, &config.EmailFrom, &config.EmailTo,
-			&config.WebhookURL, &webhookHeadersJSON, &config.WebhookTimeout,
-			&config.TelegramBotToken, &config.TelegramChatID,
-			&config.AlertOnDown, &config.AlertOnUp, &config.AlertOnSSLExpiry, &config.SSLExpiryDays,
-			&config.AlertOnStatusCodeChange, &config.AlertOnResponseTimeThreshold, &config.ResponseTimeThreshold,
-			&config.CreatedAt, &config.UpdatedAt)
+		config, err := scanAlertConfig(rows)
 		if err != nil {
 			return nil, fmt.Errorf("ошибка чтения данных конфигурации алертов: %w", err)
 		}
 
-		config.WebhookHeaders = make(map[string]string)
-		if len(webhookHeadersJSON) > 0 {
-			json.Unmarshal(webhookHeadersJSON, &config.WebhookHeaders)
-		}
-
-		configs = append(configs, config)
+		configs = append(configs, *config)
 	}
 
 	return configs, nil
@@ -439,8 +587,16 @@ func (db *DB) CreateAlertConfig(config *models.AlertConfig) error {
 			   webhook_url, webhook_headers, webhook_timeout,
 			   telegram_bot_token, telegram_chat_id,
 			   alert_on_down, alert_on_up, alert_on_ssl_expiry, ssl_expiry_days,
-			   alert_on_status_code_change, alert_on_response_time_threshold, response_time_threshold)
-			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)
+			   alert_on_status_code_change, alert_on_response_time_threshold, response_time_threshold,
+			   alert_on_packet_loss, packet_loss_threshold, escalation_policy_id,
+			   oncall_schedule_id, slack_enabled, slack_webhook_url, slack_bot_token, slack_channel,
+			   discord_enabled, discord_webhook_url, teams_enabled, teams_webhook_url,
+			   pagerduty_enabled, pagerduty_routing_key, opsgenie_enabled, opsgenie_api_key, opsgenie_region,
+			   webhook_secret, response_time_checks, alert_on_content_change,
+			   alert_on_redirect_change, alert_on_keyword_lost,
+			   alert_on_response_time_anomaly, response_time_anomaly_sigmas)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27,
+			          $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47)
 			  RETURNING id`
 
 	err := db.QueryRow(query, config.Name, config.Enabled, config.EmailEnabled, config.WebhookEnabled, config.TelegramEnabled,
@@ -449,7 +605,17 @@ func (db *DB) CreateAlertConfig(config *models.AlertConfig) error {
 		config.WebhookURL, webhookHeadersJSON, config.WebhookTimeout,
 		config.TelegramBotToken, config.TelegramChatID,
 		config.AlertOnDown, config.AlertOnUp, config.AlertOnSSLExpiry, config.SSLExpiryDays,
-		config.AlertOnStatusCodeChange, config.AlertOnResponseTimeThreshold, config.ResponseTimeThreshold).Scan(&config.ID)
+		config.AlertOnStatusCodeChange, config.AlertOnResponseTimeThreshold, config.ResponseTimeThreshold,
+		config.AlertOnPacketLoss, config.PacketLossThreshold,
+		config.EscalationPolicyID, config.OnCallScheduleID,
+		config.SlackEnabled, config.SlackWebhookURL, config.SlackBotToken, config.SlackChannel,
+		config.DiscordEnabled, config.DiscordWebhookURL, config.TeamsEnabled, config.TeamsWebhookURL,
+		config.PagerDutyEnabled, config.PagerDutyRoutingKey,
+		config.OpsgenieEnabled, config.OpsgenieAPIKey, config.OpsgenieRegion,
+		config.WebhookSecret,
+		config.ResponseTimeChecks, config.AlertOnContentChange,
+		config.AlertOnRedirectChange, config.AlertOnKeywordLost,
+		config.AlertOnResponseTimeAnomaly, config.ResponseTimeAnomalySigmas).Scan(&config.ID)
 
 	return err
 }
@@ -473,9 +639,63 @@ func (db *DB) DeleteAlertConfig(name string) error {
 	return nil
 }
 
+// GetSiteAlertConfigs returns the alert configurations assigned to the site.
+func (db *DB) GetSiteAlertConfigs(siteID int) ([]models.AlertConfig, error) {
+	query := `SELECT ` + alertConfigColumns + ` FROM alert_configs
+			  WHERE id IN (SELECT alert_config_id FROM site_alert_configs WHERE site_id = $1)
+			  ORDER BY name`
+
+	rows, err := db.Query(query, siteID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения конфигураций алертов сайта: %w", err)
+	}
+	defer rows.Close()
+
+	configs := []models.AlertConfig{}
+	for rows.Next() {
+		config, err := scanAlertConfig(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения данных конфигурации алертов: %w", err)
+		}
+		configs = append(configs, *config)
+	}
+
+	return configs, nil
+}
+
+// SetSiteAlertConfigs replaces the alert configurations of the site by the
+// ones with the given names.
+func (db *DB) SetSiteAlertConfigs(siteID int, names []string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM site_alert_configs WHERE site_id = $1`, siteID); err != nil {
+		return fmt.Errorf("ошибка удаления конфигураций алертов сайта: %w", err)
+	}
+
+	for _, name := range names {
+		result, err := tx.Exec(`INSERT INTO site_alert_configs (site_id, alert_config_id)
+				  SELECT $1, id FROM alert_configs WHERE name = $2
+				  ON CONFLICT DO NOTHING`, siteID, name)
+		if err != nil {
+			return fmt.Errorf("ошибка назначения конфигурации алертов: %w", err)
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return fmt.Errorf("конфигурация алертов '%s' не найдена", name)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LogAlert records a delivery attempt; siteID and alertConfigID are 0 for
+// alerts without a site or configuration.
 func (db *DB) LogAlert(siteID int, alertConfigID int, alertType, channel, status, message, errorMessage string) error {
 	query := `INSERT INTO alert_history (site_id, alert_config_id, alert_type, channel, status, message, error_message)
-			  VALUES ($1, $2, $3, $4, $5, $6, $7)`
+			  VALUES (NULLIF($1, 0), NULLIF($2, 0), $3, $4, $5, $6, $7)`
 
 	_, err := db.Exec(query, siteID, alertConfigID, alertType, channel, status, message, errorMessage)
 	return err
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"ping-tower/internal/models"
+)
+
+const contentSnapshotColumns = `id, site_id, content_hash, COALESCE(status_code, 0), size, original_size,
+			  COALESCE(truncated, FALSE), created_at`
+
+func scanContentSnapshot(row rowScanner, extra ...interface{}) (*models.ContentSnapshot, error) {
+	var s models.ContentSnapshot
+	dest := []interface{}{&s.ID, &s.SiteID, &s.ContentHash, &s.StatusCode, &s.Size, &s.OriginalSize,
+		&s.Truncated, &s.CreatedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetContentSnapshots returns the snapshots of the site without their content,
+// newest first.
+func (db *DB) GetContentSnapshots(siteID, limit int) ([]models.ContentSnapshot, error) {
+	rows, err := db.Query(`SELECT `+contentSnapshotColumns+` FROM content_snapshots
+			  WHERE site_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2`, siteID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения снимков контента: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []models.ContentSnapshot{}
+	for rows.Next() {
+		s, err := scanContentSnapshot(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения снимка контента: %w", err)
+		}
+		snapshots = append(snapshots, *s)
+	}
+
+	return snapshots, nil
+}
+
+// GetContentSnapshot returns a snapshot of the site with its compressed data.
+func (db *DB) GetContentSnapshot(siteID, id int) (*models.ContentSnapshot, error) {
+	var data []byte
+	s, err := scanContentSnapshot(db.QueryRow(`SELECT `+contentSnapshotColumns+`, data FROM content_snapshots
+			  WHERE site_id = $1 AND id = $2`, siteID, id), &data)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("снимок контента не найден")
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.Data = data
+	return s, nil
+}
+
+// GetLatestContentHashes returns the hash of the newest snapshot per site.
+func (db *DB) GetLatestContentHashes() (map[int]string, error) {
+	rows, err := db.Query(`SELECT DISTINCT ON (site_id) site_id, content_hash FROM content_snapshots
+			  ORDER BY site_id, created_at DESC, id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения хэшей снимков: %w", err)
+	}
+	defer rows.Close()
+
+	hashes := make(map[int]string)
+	for rows.Next() {
+		var siteID int
+		var hash string
+		if err := rows.Scan(&siteID, &hash); err != nil {
+			return nil, fmt.Errorf("ошибка чтения хэша снимка: %w", err)
+		}
+		hashes[siteID] = hash
+	}
+
+	return hashes, nil
+}
+
+func (db *DB) CreateContentSnapshot(s *models.ContentSnapshot) error {
+	query := `INSERT INTO content_snapshots (site_id, content_hash, status_code, size, original_size, truncated, data)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`
+
+	err := db.QueryRow(query, s.SiteID, s.ContentHash, s.StatusCode, s.Size, s.OriginalSize, s.Truncated, s.Data).
+		Scan(&s.ID, &s.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения снимка контента: %w", err)
+	}
+	return nil
+}
+
+// PruneContentSnapshots keeps the newest snapshots of the site.
+func (db *DB) PruneContentSnapshots(siteID, keep int) error {
+	_, err := db.Exec(`DELETE FROM content_snapshots WHERE site_id = $1 AND id NOT IN (
+			  SELECT id FROM content_snapshots WHERE site_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2)`,
+		siteID, keep)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления старых снимков контента: %w", err)
+	}
+	return nil
+}
+
+// GetPreviousContentSnapshotID returns the snapshot of the site stored before
+// the given one, or the newest one for 0; 0 if there is none.
+func (db *DB) GetPreviousContentSnapshotID(siteID, id int) (int, error) {
+	var previous int
+	err := db.QueryRow(`SELECT id FROM content_snapshots WHERE site_id = $1 AND ($2 = 0 OR id < $2)
+			  ORDER BY id DESC LIMIT 1`, siteID, id).Scan(&previous)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка получения снимка контента: %w", err)
+	}
+	return previous, nil
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"ping-tower/internal/models"
+)
+
+// GetSSLCertificates returns the sites with a known certificate expiry and
+// the expiry alert sent for them, soonest expiry first.
+func (db *DB) GetSSLCertificates() ([]models.SSLCertificate, error) {
+	query := `SELECT s.id, s.url, COALESCE(s.status, ''), s.ssl_expiry, COALESCE(s.ssl_issuer, ''),
+			  COALESCE(c.enabled, TRUE), COALESCE(c.check_ssl, TRUE), COALESCE(c.ssl_alert_days, 30),
+			  COALESCE(c.ssl_alert_thresholds, ''),
+			  a.ssl_expiry, COALESCE(a.threshold, 0), a.alerted_at
+			  FROM sites s
+			  LEFT JOIN site_configs c ON c.site_id = s.id
+			  LEFT JOIN ssl_expiry_alerts a ON a.site_id = s.id
+			  WHERE s.ssl_expiry IS NOT NULL
+			  ORDER BY s.ssl_expiry`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения SSL сертификатов: %w", err)
+	}
+	defer rows.Close()
+
+	var certificates []models.SSLCertificate
+	for rows.Next() {
+		var cert models.SSLCertificate
+		var alertExpiry, alertedAt sql.NullTime
+		var threshold int
+
+		err := rows.Scan(&cert.SiteID, &cert.SiteURL, &cert.SiteStatus, &cert.Expiry, &cert.Issuer,
+			&cert.Enabled, &cert.CheckSSL, &cert.AlertDays, &cert.Thresholds,
+			&alertExpiry, &threshold, &alertedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения SSL сертификата: %w", err)
+		}
+
+		if alertExpiry.Valid {
+			cert.Alert = &models.SSLExpiryAlert{
+				SiteID:    cert.SiteID,
+				SSLExpiry: alertExpiry.Time,
+				Threshold: threshold,
+				AlertedAt: alertedAt.Time,
+			}
+		}
+		certificates = append(certificates, cert)
+	}
+
+	return certificates, nil
+}
+
+// SaveSSLExpiryAlert records the threshold alerted for the certificate.
+func (db *DB) SaveSSLExpiryAlert(alert *models.SSLExpiryAlert) error {
+	query := `INSERT INTO ssl_expiry_alerts (site_id, ssl_expiry, threshold, alerted_at)
+			  VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (site_id) DO UPDATE SET
+			  ssl_expiry = EXCLUDED.ssl_expiry, threshold = EXCLUDED.threshold, alerted_at = EXCLUDED.alerted_at`
+
+	_, err := db.Exec(query, alert.SiteID, alert.SSLExpiry, alert.Threshold, alert.AlertedAt)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения SSL алерта: %w", err)
+	}
+	return nil
+}
+
+// DeleteSSLExpiryAlert forgets the alerts sent for the certificate of the site.
+func (db *DB) DeleteSSLExpiryAlert(siteID int) error {
+	_, err := db.Exec("DELETE FROM ssl_expiry_alerts WHERE site_id = $1", siteID)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления SSL алерта: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"ping-tower/internal/models"
+)
+
+const alertTemplateColumns = `id, channel, alert_type, format, COALESCE(subject, ''), body, enabled, created_at, updated_at`
+
+func scanAlertTemplate(row rowScanner) (*models.AlertTemplate, error) {
+	var t models.AlertTemplate
+	if err := row.Scan(&t.ID, &t.Channel, &t.AlertType, &t.Format, &t.Subject, &t.Body, &t.Enabled,
+		&t.CreatedAt, &t.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (db *DB) GetAlertTemplates() ([]models.AlertTemplate, error) {
+	rows, err := db.Query(`SELECT ` + alertTemplateColumns + ` FROM alert_templates ORDER BY channel, alert_type`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения шаблонов оповещений: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []models.AlertTemplate{}
+	for rows.Next() {
+		t, err := scanAlertTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения шаблона оповещения: %w", err)
+		}
+		templates = append(templates, *t)
+	}
+
+	return templates, nil
+}
+
+func (db *DB) GetAlertTemplate(id int) (*models.AlertTemplate, error) {
+	t, err := scanAlertTemplate(db.QueryRow(`SELECT `+alertTemplateColumns+` FROM alert_templates WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("шаблон оповещения не найден")
+	}
+	return t, err
+}
+
+// FindAlertTemplate returns the template of the channel and alert type or nil.
+func (db *DB) FindAlertTemplate(channel, alertType string) (*models.AlertTemplate, error) {
+	t, err := scanAlertTemplate(db.QueryRow(`SELECT `+alertTemplateColumns+` FROM alert_templates
+			  WHERE channel = $1 AND alert_type = $2`, channel, alertType))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return t, err
+}
+
+func (db *DB) CreateAlertTemplate(t *models.AlertTemplate) error {
+	query := `INSERT INTO alert_templates (channel, alert_type, format, subject, body, enabled)
+			  VALUES ($1, $2, $3, $4, $5, $6)
+			  RETURNING id, created_at, updated_at`
+
+	return db.QueryRow(query, t.Channel, t.AlertType, t.Format, t.Subject, t.Body, t.Enabled).
+		Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+}
+
+func (db *DB) UpdateAlertTemplate(t *models.AlertTemplate) error {
+	query := `UPDATE alert_templates SET
+			  channel = $2, alert_type = $3, format = $4, subject = $5, body = $6, enabled = $7,
+			  updated_at = CURRENT_TIMESTAMP
+			  WHERE id = $1`
+
+	result, err := db.Exec(query, t.ID, t.Channel, t.AlertType, t.Format, t.Subject, t.Body, t.Enabled)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("шаблон оповещения не найден")
+	}
+	return nil
+}
+
+func (db *DB) DeleteAlertTemplate(id int) error {
+	result, err := db.Exec(`DELETE FROM alert_templates WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления шаблона оповещения: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("шаблон оповещения не найден")
+	}
+	return nil
+}
@@ -0,0 +1,456 @@
+package escalation
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"ping-tower/internal/database"
+	"ping-tower/internal/models"
+	"ping-tower/internal/notifications"
+	"ping-tower/internal/oncall"
+	"ping-tower/internal/routing"
+)
+
+// MaxStepDelay limits the wait between two steps.
+const MaxStepDelay = 24 * 60
+
+// escalatedTypes start an escalation, recoveryTypes end it.
+var (
+	escalatedTypes = map[string]bool{"site_down": true, "heartbeat_missed": true, "heartbeat_failed": true}
+	recoveryTypes  = map[string]bool{"site_up": true, "heartbeat_recovered": true, "flapping_stopped": true}
+)
+
+type Service struct {
+	db           *database.DB
+	alertManager *notifications.AlertManager
+	oncall       *oncall.Service
+	router       *routing.Router
+
+	// mu serializes the interceptor and the worker so a step is never sent twice
+	mu sync.Mutex
+}
+
+func NewService(db *database.DB) *Service {
+	return &Service{
+		db:           db,
+		alertManager: nil, // Will be set externally
+	}
+}
+
+func (s *Service) SetAlertManager(alertManager *notifications.AlertManager) {
+	s.alertManager = alertManager
+}
+
+func (s *Service) SetOnCallService(service *oncall.Service) {
+	s.oncall = service
+}
+
+func (s *Service) SetRouter(router *routing.Router) {
+	s.router = router
+}
+
+// Validate checks the steps of the policy.
+func Validate(p *models.EscalationPolicy) error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(p.Steps) == 0 {
+		return fmt.Errorf("at least one step is required")
+	}
+
+	for i := range p.Steps {
+		step := &p.Steps[i]
+		if step.DelayMinutes < 0 || step.DelayMinutes > MaxStepDelay {
+			return fmt.Errorf("step %d: delay_minutes must be between 0 and %d", i+1, MaxStepDelay)
+		}
+		if step.OnCallScheduleID < 0 {
+			return fmt.Errorf("step %d: invalid oncall_schedule_id", i+1)
+		}
+		if len(step.Channels) == 0 {
+			return fmt.Errorf("step %d: at least one channel is required", i+1)
+		}
+		for _, channel := range step.Channels {
+			if !notifications.IsChannel(channel) {
+				return fmt.Errorf("step %d: unknown channel %q (supported: %s)", i+1, channel, strings.Join(notifications.Channels, ", "))
+			}
+		}
+
+		emails := []string{}
+		for _, email := range step.EmailTo {
+			if email = strings.TrimSpace(email); email != "" {
+				emails = append(emails, email)
+			}
+		}
+		step.EmailTo = emails
+	}
+
+	return nil
+}
+
+// PolicyFor returns the enabled policy for the alerts of the site and the
+// alert configuration whose credentials run its steps. The policy of the site
+// comes first, then those of the alert configurations assigned to the site
+// or, if it has none, of the global one. A policy of the site runs with the
+// first of these configurations.
+func (s *Service) PolicyFor(siteID int) (*models.EscalationPolicy, *models.AlertConfig) {
+	configs := s.configs(siteID)
+
+	if siteID > 0 {
+		if config, err := s.db.GetSiteConfig(siteID); err == nil {
+			if policy := s.policy(config.EscalationPolicyID); policy != nil {
+				if len(configs) == 0 {
+					return policy, nil
+				}
+				return policy, &configs[0]
+			}
+		}
+	}
+
+	for i := range configs {
+		if policy := s.policy(configs[i].EscalationPolicyID); policy != nil {
+			return policy, &configs[i]
+		}
+	}
+	return nil, nil
+}
+
+// configs returns the alert configurations the alerts of the site go to.
+func (s *Service) configs(siteID int) []models.AlertConfig {
+	if s.router != nil {
+		return s.router.ConditionConfigs(siteID)
+	}
+	if global, err := s.db.GetAlertConfig("global"); err == nil {
+		return []models.AlertConfig{*global}
+	}
+	return nil
+}
+
+// policy returns the policy if it is enabled and has steps.
+func (s *Service) policy(policyID int) *models.EscalationPolicy {
+	if policyID == 0 {
+		return nil
+	}
+
+	policy, err := s.db.GetEscalationPolicy(policyID)
+	if err != nil {
+		log.Printf("⚠️ Политика эскалации #%d не загружена: %v", policyID, err)
+		return nil
+	}
+	if !policy.Enabled || len(policy.Steps) == 0 {
+		return nil
+	}
+	return policy
+}
+
+// Intercept is registered with notifications.AddInterceptor. A failure alert
+// of a source with a policy starts an escalation instead of going to the
+// channels of the alert configuration that owns the policy; repeated failure
+// alerts are absorbed while it runs. The recovery alert ends the escalation
+// and goes to everyone who was notified. Other configurations of the source
+// get the alerts as usual.
+func (s *Service) Intercept(alertData notifications.AlertData) []string {
+	if s.alertManager == nil || (!escalatedTypes[alertData.AlertType] && !recoveryTypes[alertData.AlertType]) {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	open, err := s.db.GetOpenEscalation(alertData.SiteURL)
+	if err != nil {
+		log.Printf("❌ Ошибка получения эскалации для %s: %v", alertData.SiteURL, err)
+		return nil
+	}
+
+	if recoveryTypes[alertData.AlertType] {
+		if open == nil {
+			return nil
+		}
+		s.resolve(open, alertData)
+		return handled(open)
+	}
+
+	if open != nil {
+		log.Printf("🔁 Алерт %s для %s поглощен эскалацией #%d", alertData.AlertType, alertData.SiteURL, open.ID)
+		return handled(open)
+	}
+
+	policy, config := s.PolicyFor(alertData.SiteID)
+	if policy == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(alertData)
+	if err != nil {
+		return nil
+	}
+
+	next := time.Now().Add(time.Duration(policy.Steps[0].DelayMinutes) * time.Minute)
+	e := &models.Escalation{
+		PolicyID:         policy.ID,
+		ConfigName:       configName(config),
+		SiteID:           alertData.SiteID,
+		SiteURL:          alertData.SiteURL,
+		AlertType:        alertData.AlertType,
+		Alert:            payload,
+		Status:           models.EscalationStatusActive,
+		NotifiedChannels: []string{},
+		NextStepAt:       &next,
+	}
+	if err := s.db.CreateEscalation(e); err != nil {
+		log.Printf("❌ Ошибка создания эскалации для %s: %v", alertData.SiteURL, err)
+		return nil
+	}
+
+	if e.ConfigName != "" {
+		log.Printf("📶 Запущена эскалация #%d для %s по политике '%s' через конфигурацию '%s'", e.ID, alertData.SiteURL, policy.Name, e.ConfigName)
+	} else {
+		log.Printf("📶 Запущена эскалация #%d для %s по политике '%s'", e.ID, alertData.SiteURL, policy.Name)
+	}
+
+	if policy.Steps[0].DelayMinutes == 0 {
+		s.runStep(e, policy)
+	}
+	return handled(e)
+}
+
+// ProcessDue runs the steps whose time has come. The state is read from the
+// database, so escalations continue after a restart.
+func (s *Service) ProcessDue() error {
+	if s.alertManager == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due, err := s.db.GetDueEscalations(time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to load due escalations: %w", err)
+	}
+
+	for i := range due {
+		e := &due[i]
+
+		policy, err := s.db.GetEscalationPolicy(e.PolicyID)
+		if err != nil || !policy.Enabled || e.CurrentStep >= len(policy.Steps) {
+			s.finish(e, models.EscalationStatusCompleted)
+			continue
+		}
+
+		// Подтвержденный инцидент останавливает эскалацию
+		if e.SiteID > 0 {
+			if inc, err := s.db.GetActiveIncident(e.SiteID); err == nil && inc != nil && inc.Status == models.IncidentStatusAcknowledged {
+				e.AcknowledgedAt = inc.AcknowledgedAt
+				e.AcknowledgedBy = inc.AcknowledgedBy
+				s.finish(e, models.EscalationStatusAcknowledged)
+				log.Printf("✋ Эскалация #%d остановлена: инцидент #%d подтвержден", e.ID, inc.ID)
+				continue
+			}
+		}
+
+		s.runStep(e, policy)
+	}
+
+	return nil
+}
+
+// CreateJob returns a scheduler job that runs due escalation steps.
+func (s *Service) CreateJob() func() error {
+	return func() error {
+		return s.ProcessDue()
+	}
+}
+
+// Acknowledge stops the escalation, no further steps are sent.
+func (s *Service) Acknowledge(id int, by string) (*models.Escalation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, err := s.db.GetEscalation(id)
+	if err != nil {
+		return nil, err
+	}
+	if e.Status != models.EscalationStatusActive {
+		return nil, fmt.Errorf("escalation is %s", e.Status)
+	}
+
+	now := time.Now()
+	e.AcknowledgedAt = &now
+	e.AcknowledgedBy = by
+	e.Status = models.EscalationStatusAcknowledged
+	e.NextStepAt = nil
+	if err := s.db.UpdateEscalationState(e); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✋ Эскалация #%d для %s подтверждена", e.ID, e.SiteURL)
+	return e, nil
+}
+
+func (s *Service) runStep(e *models.Escalation, policy *models.EscalationPolicy) {
+	step := policy.Steps[e.CurrentStep]
+
+	var alertData notifications.AlertData
+	if err := json.Unmarshal(e.Alert, &alertData); err != nil {
+		log.Printf("❌ Эскалация #%d: поврежденные данные алерта: %v", e.ID, err)
+		s.finish(e, models.EscalationStatusCompleted)
+		return
+	}
+
+	// Шаг, который никого не оповестил бы, ждет следующего запуска обработчика
+	manager := s.managerFor(e)
+	if manager == nil || manager.Suppressed(alertData) {
+		log.Printf("⏸️ Эскалация #%d: шаг %d/%d отложен, оповещение не отправлено", e.ID, e.CurrentStep+1, len(policy.Steps))
+		return
+	}
+
+	log.Printf("📶 Эскалация #%d: шаг %d/%d (%s)", e.ID, e.CurrentStep+1, len(policy.Steps), strings.Join(step.Channels, ", "))
+	if err := manager.SendAlertTo(alertData, s.targetFor(policy.Steps[e.CurrentStep:e.CurrentStep+1])); err != nil {
+		log.Printf("⚠️ Эскалация #%d: %v", e.ID, err)
+	}
+
+	for _, channel := range step.Channels {
+		if !contains(e.NotifiedChannels, channel) {
+			e.NotifiedChannels = append(e.NotifiedChannels, channel)
+		}
+	}
+
+	e.CurrentStep++
+	if e.CurrentStep < len(policy.Steps) {
+		next := time.Now().Add(time.Duration(policy.Steps[e.CurrentStep].DelayMinutes) * time.Minute)
+		e.NextStepAt = &next
+	} else {
+		e.Status = models.EscalationStatusCompleted
+		e.NextStepAt = nil
+	}
+
+	if err := s.db.UpdateEscalationState(e); err != nil {
+		log.Printf("❌ Ошибка сохранения эскалации #%d: %v", e.ID, err)
+	}
+}
+
+// resolve ends the escalation on recovery and sends the recovery alert to
+// the recipients of every step that ran.
+func (s *Service) resolve(e *models.Escalation, alertData notifications.AlertData) {
+	s.finish(e, models.EscalationStatusResolved)
+	log.Printf("✅ Эскалация #%d для %s завершена восстановлением", e.ID, e.SiteURL)
+
+	policy, err := s.db.GetEscalationPolicy(e.PolicyID)
+	if err != nil || e.CurrentStep == 0 {
+		return
+	}
+
+	manager := s.managerFor(e)
+	if manager == nil {
+		return
+	}
+
+	steps := policy.Steps
+	if e.CurrentStep < len(steps) {
+		steps = steps[:e.CurrentStep]
+	}
+	if err := manager.SendAlertTo(alertData, s.targetFor(steps)); err != nil {
+		log.Printf("⚠️ Ошибка отправки восстановления по эскалации #%d: %v", e.ID, err)
+	}
+}
+
+// managerFor returns the manager of the alert configuration the escalation
+// runs with, the global one if it has none, or nil if the configuration is
+// gone or disabled.
+func (s *Service) managerFor(e *models.Escalation) *notifications.AlertManager {
+	if e.ConfigName == "" {
+		return s.alertManager
+	}
+
+	cfg, err := s.db.GetAlertConfig(e.ConfigName)
+	if err != nil {
+		log.Printf("⚠️ Эскалация #%d: конфигурация оповещений '%s' не загружена: %v", e.ID, e.ConfigName, err)
+		return nil
+	}
+	if !cfg.Enabled {
+		log.Printf("⚠️ Эскалация #%d: конфигурация оповещений '%s' отключена", e.ID, e.ConfigName)
+		return nil
+	}
+
+	if s.router != nil {
+		return notifications.NewRouteManager(s.router.Route(*cfg))
+	}
+	return notifications.NewRouteManager(notifications.Route{Name: cfg.Name, Config: routing.AlertsConfig(cfg)})
+}
+
+// handled returns the alert configuration whose delivery the escalation
+// replaces.
+func handled(e *models.Escalation) []string {
+	if e.ConfigName == "" {
+		return []string{"global"}
+	}
+	return []string{e.ConfigName}
+}
+
+// configName returns the name of the configuration as stored with the
+// escalation, empty for the global one.
+func configName(cfg *models.AlertConfig) string {
+	if cfg == nil || cfg.Name == "global" {
+		return ""
+	}
+	return cfg.Name
+}
+
+func (s *Service) finish(e *models.Escalation, status string) {
+	e.Status = status
+	e.NextStepAt = nil
+	if err := s.db.UpdateEscalationState(e); err != nil {
+		log.Printf("❌ Ошибка сохранения эскалации #%d: %v", e.ID, err)
+	}
+}
+
+// targetFor merges the channels and recipients of the steps, including the
+// person currently on call for steps with a schedule.
+func (s *Service) targetFor(steps []models.EscalationStep) notifications.Target {
+	var target notifications.Target
+	for _, step := range steps {
+		if step.OnCallScheduleID > 0 && s.oncall != nil {
+			if onCall := s.oncall.TargetFor(step.OnCallScheduleID); onCall != nil {
+				step.EmailTo = append(step.EmailTo, onCall.EmailTo...)
+				if onCall.WebhookURL != "" {
+					step.WebhookURL = onCall.WebhookURL
+				}
+				if onCall.TelegramChatID != "" {
+					step.TelegramChatID = onCall.TelegramChatID
+				}
+			}
+		}
+
+		for _, channel := range step.Channels {
+			if !contains(target.Channels, channel) {
+				target.Channels = append(target.Channels, channel)
+			}
+		}
+		for _, email := range step.EmailTo {
+			if !contains(target.EmailTo, email) {
+				target.EmailTo = append(target.EmailTo, email)
+			}
+		}
+		if step.WebhookURL != "" {
+			target.WebhookURL = step.WebhookURL
+		}
+		if step.TelegramChatID != "" {
+			target.TelegramChatID = step.TelegramChatID
+		}
+	}
+	return target
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"ping-tower/internal/alerting"
+)
+
+var alertEngine *alerting.Engine
+
+func SetAlertEngine(engine *alerting.Engine) {
+	alertEngine = engine
+}
+
+// GetAlertEngineStatusHandler - состояние движка оповещений
+// @Summary Получить состояние движка оповещений
+// @Description Открытые (дедуплицированные) оповещения, нестабильные сайты, лимиты каналов и ожидающие дайджесты
+// @Tags alerts
+// @Produce json
+// @Success 200 {object} alerting.Status "Состояние движка"
+// @Router /alerts/engine [get]
+func GetAlertEngineStatusHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if alertEngine == nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Alert engine is not available"})
+			return
+		}
+
+		json.NewEncoder(w).Encode(alertEngine.Status())
+	}
+}
@@ -392,6 +392,9 @@ const alertConfigTemplate = `<!DOCTYPE html>
                 <a href="/metrics" class="nav-link">
                     <i class="fas fa-chart-line"></i> Метрики
                 </a>
+                <a href="/incidents" class="nav-link">
+                    <i class="fas fa-fire"></i> Инциденты
+                </a>
                 <a href="/alerts" class="nav-link active">
                     <i class="fas fa-bell"></i> Оповещения
                 </a>
@@ -404,7 +407,7 @@ const alertConfigTemplate = `<!DOCTYPE html>
         <!-- Заголовок -->
         <div class="header">
             <h1><i class="fas fa-bell"></i> Конфигурация оповещений</h1>
-            <p>Настройка системы уведомлений: Email, Webhook, Telegram</p>
+            <p>Настройка системы уведомлений: Email, Webhook, Telegram, Slack, Discord, Teams, PagerDuty, Opsgenie</p>
         </div>
 
         <!-- Список существующих конфигураций -->
@@ -423,6 +426,25 @@ const alertConfigTemplate = `<!DOCTYPE html>
             </div>
         </div>
 
+        <!-- Очередь доставки: оповещения, которые не удалось отправить -->
+        <div class="config-list">
+            <div style="display: flex; justify-content: space-between; align-items: center; margin-bottom: 20px;">
+                <h3 style="color: white; margin: 0;">
+                    <i class="fas fa-inbox"></i> Недоставленные оповещения
+                </h3>
+                <select class="form-input" id="queueStatus" style="width: auto;" onchange="loadQueue()">
+                    <option value="dead">Не доставлены (dead)</option>
+                    <option value="pending">Ожидают повтора</option>
+                    <option value="superseded">Вытеснены более новым алертом</option>
+                    <option value="">Все</option>
+                </select>
+            </div>
+
+            <div id="queue-items">
+                <!-- Очередь будет загружена через JavaScript -->
+            </div>
+        </div>
+
         <!-- Модальное окно для создания/редактирования конфигурации -->
         <div id="configModal" class="modal">
             <div class="modal-content">
@@ -454,6 +476,21 @@ const alertConfigTemplate = `<!DOCTYPE html>
                         <button type="button" class="tab" data-tab="telegram">
                             <i class="fas fa-paper-plane"></i> Telegram
                         </button>
+                        <button type="button" class="tab" data-tab="slack">
+                            <i class="fab fa-slack"></i> Slack
+                        </button>
+                        <button type="button" class="tab" data-tab="discord">
+                            <i class="fab fa-discord"></i> Discord
+                        </button>
+                        <button type="button" class="tab" data-tab="teams">
+                            <i class="fab fa-microsoft"></i> Teams
+                        </button>
+                        <button type="button" class="tab" data-tab="pagerduty">
+                            <i class="fas fa-pager"></i> PagerDuty
+                        </button>
+                        <button type="button" class="tab" data-tab="opsgenie">
+                            <i class="fas fa-bell"></i> Opsgenie
+                        </button>
                         <button type="button" class="tab" data-tab="conditions">
                             <i class="fas fa-filter"></i> Условия
                         </button>
@@ -516,6 +553,10 @@ const alertConfigTemplate = `<!DOCTYPE html>
                                 <label class="form-label">Таймаут (сек)</label>
                                 <input type="number" class="form-input" id="webhookTimeout" placeholder="10" value="10" min="1" max="60">
                             </div>
+                            <div class="form-group">
+                                <label class="form-label">Секрет подписи (HMAC-SHA256)</label>
+                                <input type="password" class="form-input" id="webhookSecret" placeholder="Пусто - без подписи">
+                            </div>
                         </div>
 
                         <div class="form-group">
@@ -550,6 +591,114 @@ const alertConfigTemplate = `<!DOCTYPE html>
                         </div>
                     </div>
 
+                    <!-- Slack настройки -->
+                    <div id="slack-tab" class="tab-content">
+                        <div class="form-checkbox">
+                            <input type="checkbox" id="slackEnabled">
+                            <label for="slackEnabled">Включить Slack оповещения</label>
+                        </div>
+
+                        <div class="form-group">
+                            <label class="form-label">Bot Token</label>
+                            <input type="text" class="form-input" id="slackBotToken" placeholder="xoxb-...">
+                        </div>
+
+                        <div class="form-group">
+                            <label class="form-label">Канал</label>
+                            <input type="text" class="form-input" id="slackChannel" placeholder="#alerts или C0123456789">
+                        </div>
+
+                        <div class="form-group">
+                            <label class="form-label">Incoming Webhook URL</label>
+                            <input type="url" class="form-input" id="slackWebhookUrl" placeholder="https://hooks.slack.com/services/...">
+                        </div>
+
+                        <div style="background: rgba(255, 255, 255, 0.1); padding: 15px; border-radius: 8px; margin-top: 15px;">
+                            <p style="color: rgba(255, 255, 255, 0.8); font-size: 14px; margin: 0;">
+                                <i class="fas fa-info-circle"></i>
+                                С Bot Token (scope chat:write) сообщения отправляются через chat.postMessage,
+                                а восстановление публикуется в треде сообщения о падении.
+                                Без токена используется Incoming Webhook - без тредов.
+                            </p>
+                        </div>
+                    </div>
+
+                    <!-- Discord настройки -->
+                    <div id="discord-tab" class="tab-content">
+                        <div class="form-checkbox">
+                            <input type="checkbox" id="discordEnabled">
+                            <label for="discordEnabled">Включить Discord оповещения</label>
+                        </div>
+
+                        <div class="form-group">
+                            <label class="form-label">Webhook URL</label>
+                            <input type="url" class="form-input" id="discordWebhookUrl" placeholder="https://discord.com/api/webhooks/...">
+                        </div>
+                    </div>
+
+                    <!-- Teams настройки -->
+                    <div id="teams-tab" class="tab-content">
+                        <div class="form-checkbox">
+                            <input type="checkbox" id="teamsEnabled">
+                            <label for="teamsEnabled">Включить Microsoft Teams оповещения</label>
+                        </div>
+
+                        <div class="form-group">
+                            <label class="form-label">Webhook URL</label>
+                            <input type="url" class="form-input" id="teamsWebhookUrl" placeholder="https://...webhook.office.com/... или URL Workflows">
+                        </div>
+
+                        <div style="background: rgba(255, 255, 255, 0.1); padding: 15px; border-radius: 8px; margin-top: 15px;">
+                            <p style="color: rgba(255, 255, 255, 0.8); font-size: 14px; margin: 0;">
+                                <i class="fas fa-info-circle"></i>
+                                Оповещения отправляются как Adaptive Card. Подходит Incoming Webhook канала
+                                или Workflows "Post to a channel when a webhook request is received".
+                            </p>
+                        </div>
+                    </div>
+
+                    <!-- PagerDuty настройки -->
+                    <div id="pagerduty-tab" class="tab-content">
+                        <div class="form-checkbox">
+                            <input type="checkbox" id="pagerdutyEnabled">
+                            <label for="pagerdutyEnabled">Включить PagerDuty</label>
+                        </div>
+
+                        <div class="form-group">
+                            <label class="form-label">Integration (Routing) Key</label>
+                            <input type="text" class="form-input" id="pagerdutyRoutingKey" placeholder="Events API v2 integration key">
+                        </div>
+                    </div>
+
+                    <!-- Opsgenie настройки -->
+                    <div id="opsgenie-tab" class="tab-content">
+                        <div class="form-checkbox">
+                            <input type="checkbox" id="opsgenieEnabled">
+                            <label for="opsgenieEnabled">Включить Opsgenie</label>
+                        </div>
+
+                        <div class="form-group">
+                            <label class="form-label">API Key</label>
+                            <input type="text" class="form-input" id="opsgenieApiKey" placeholder="Ключ API интеграции">
+                        </div>
+
+                        <div class="form-group">
+                            <label class="form-label">Регион</label>
+                            <select class="form-input" id="opsgenieRegion">
+                                <option value="us">US (api.opsgenie.com)</option>
+                                <option value="eu">EU (api.eu.opsgenie.com)</option>
+                            </select>
+                        </div>
+
+                        <div style="background: rgba(255, 255, 255, 0.1); padding: 15px; border-radius: 8px; margin-top: 15px;">
+                            <p style="color: rgba(255, 255, 255, 0.8); font-size: 14px; margin: 0;">
+                                <i class="fas fa-info-circle"></i>
+                                Падение сайта открывает инцидент, восстановление закрывает его.
+                                Ключ дедупликации постоянен для сайта, повторные оповещения не создают новых инцидентов.
+                            </p>
+                        </div>
+                    </div>
+
                     <!-- Условия оповещений -->
                     <div id="conditions-tab" class="tab-content">
                         <h4 style="color: white; margin-bottom: 15px;">Когда отправлять оповещения:</h4>
@@ -583,6 +732,61 @@ const alertConfigTemplate = `<!DOCTYPE html>
                             <label class="form-label">Максимальное время отклика (мс)</label>
                             <input type="number" class="form-input" id="responseTimeThreshold" value="5000" min="100">
                         </div>
+
+                        <div class="form-group">
+                            <label class="form-label">Проверок подряд с превышением</label>
+                            <input type="number" class="form-input" id="responseTimeChecks" value="1" min="1" max="100">
+                        </div>
+
+                        <div class="form-checkbox">
+                            <input type="checkbox" id="alertOnResponseTimeAnomaly">
+                            <label for="alertOnResponseTimeAnomaly">При аномальном времени отклика (относительно обычного для этого часа)</label>
+                        </div>
+
+                        <div class="form-group">
+                            <label class="form-label">Порог аномалии (сигм)</label>
+                            <input type="number" class="form-input" id="responseTimeAnomalySigmas" value="3" min="1" max="20" step="0.5">
+                        </div>
+
+                        <div class="form-checkbox">
+                            <input type="checkbox" id="alertOnStatusCodeChange">
+                            <label for="alertOnStatusCodeChange">При смене кода ответа</label>
+                        </div>
+
+                        <div class="form-checkbox">
+                            <input type="checkbox" id="alertOnContentChange">
+                            <label for="alertOnContentChange">При изменении содержимого (хеш)</label>
+                        </div>
+
+                        <div class="form-checkbox">
+                            <input type="checkbox" id="alertOnRedirectChange">
+                            <label for="alertOnRedirectChange">При смене адреса редиректа</label>
+                        </div>
+
+                        <div class="form-checkbox">
+                            <input type="checkbox" id="alertOnKeywordLost">
+                            <label for="alertOnKeywordLost">При пропаже ключевого слова</label>
+                        </div>
+
+                        <div class="form-checkbox">
+                            <input type="checkbox" id="alertOnPacketLoss">
+                            <label for="alertOnPacketLoss">При потере пакетов (ICMP)</label>
+                        </div>
+
+                        <div class="form-group">
+                            <label class="form-label">Порог потери пакетов (%)</label>
+                            <input type="number" class="form-input" id="packetLossThreshold" value="20" min="0" max="100" step="0.1">
+                        </div>
+
+                        <div class="form-group">
+                            <label class="form-label">ID политики эскалации (0 - без эскалации)</label>
+                            <input type="number" class="form-input" id="escalationPolicyId" value="0" min="0">
+                        </div>
+
+                        <div class="form-group">
+                            <label class="form-label">ID расписания дежурств (0 - получатели выше)</label>
+                            <input type="number" class="form-input" id="onCallScheduleId" value="0" min="0">
+                        </div>
                     </div>
 
                     <div style="margin-top: 30px; display: flex; gap: 10px;">
@@ -622,6 +826,71 @@ const alertConfigTemplate = `<!DOCTYPE html>
 
         // Загрузка конфигураций при загрузке страницы
         window.addEventListener('load', loadConfigs);
+        window.addEventListener('load', loadQueue);
+
+        function escapeHtml(text) {
+            const div = document.createElement('div');
+            div.textContent = text == null ? '' : String(text);
+            return div.innerHTML;
+        }
+
+        function loadQueue() {
+            const status = document.getElementById('queueStatus').value;
+            fetch('/api/notifications/queue?status=' + encodeURIComponent(status))
+                .then(response => response.json())
+                .then(items => renderQueue(items || []))
+                .catch(error => {
+                    console.error('Error loading queue:', error);
+                    document.getElementById('queue-items').innerHTML =
+                        '<p style="color: rgba(255, 255, 255, 0.7);">Ошибка загрузки очереди</p>';
+                });
+        }
+
+        function renderQueue(items) {
+            const container = document.getElementById('queue-items');
+
+            if (items.length === 0) {
+                container.innerHTML = '<p style="color: rgba(255, 255, 255, 0.7);">Очередь пуста</p>';
+                return;
+            }
+
+            container.innerHTML = items.map(item => {
+                const statusClass = item.status === 'sent' ? 'status-enabled' : 'status-disabled';
+                const next = item.next_attempt_at ? ' · следующая попытка ' + new Date(item.next_attempt_at).toLocaleString('ru-RU') : '';
+                const resendButton = item.status !== 'sent' && item.status !== 'superseded' ?
+                    "<button class='btn btn-primary' onclick='resendNotification(" + item.id + ")'>" +
+                        "<i class='fas fa-redo'></i> Отправить" +
+                    "</button>" : '';
+
+                return "<div class='config-item'>" +
+                    "<div class='config-info'>" +
+                        "<h4>" +
+                            "<span class='status-indicator " + statusClass + "'></span>" +
+                            "#" + item.id + " " + escapeHtml(item.channel) + " · " + escapeHtml(item.alert_type) +
+                        "</h4>" +
+                        "<p>" + escapeHtml(item.site_url) + (item.config_name ? " · " + escapeHtml(item.config_name) : "") + "</p>" +
+                        "<p>Попыток: " + item.attempts + "/" + item.max_attempts + next + "</p>" +
+                        (item.last_error ? "<p>" + escapeHtml(item.last_error) + "</p>" : "") +
+                    "</div>" +
+                    "<div class='config-actions'>" + resendButton + "</div>" +
+                "</div>";
+            }).join('');
+        }
+
+        function resendNotification(id) {
+            fetch('/api/notifications/queue/' + id + '/resend', { method: 'POST' })
+                .then(response => response.json().then(data => ({ ok: response.ok, data: data })))
+                .then(result => {
+                    if (!result.ok) {
+                        throw new Error(result.data.error || 'Ошибка отправки');
+                    }
+                    alert(result.data.status === 'sent' ?
+                        'Оповещение доставлено' :
+                        'Не удалось доставить: ' + result.data.last_error);
+                    loadQueue();
+                })
+                .catch(error => alert(error.message));
+        }
 
         function loadConfigs() {
             fetch('/api/alerts/configs')
@@ -650,6 +919,11 @@ const alertConfigTemplate = `<!DOCTYPE html>
                 if (config.email_enabled) enabledChannels.push('Email');
                 if (config.webhook_enabled) enabledChannels.push('Webhook');
                 if (config.telegram_enabled) enabledChannels.push('Telegram');
+                if (config.slack_enabled) enabledChannels.push('Slack');
+                if (config.discord_enabled) enabledChannels.push('Discord');
+                if (config.teams_enabled) enabledChannels.push('Teams');
+                if (config.pagerduty_enabled) enabledChannels.push('PagerDuty');
+                if (config.opsgenie_enabled) enabledChannels.push('Opsgenie');
 
                 const channelsText = enabledChannels.length > 0 ? enabledChannels.join(', ') : 'Нет активных каналов';
                 const statusClass = config.enabled ? 'status-enabled' : 'status-disabled';
@@ -711,6 +985,7 @@ const alertConfigTemplate = `<!DOCTYPE html>
             document.getElementById('webhookEnabled').checked = config.webhook_enabled;
             document.getElementById('webhookUrl').value = config.webhook_url || '';
             document.getElementById('webhookTimeout').value = config.webhook_timeout || 10;
+            document.getElementById('webhookSecret').value = config.webhook_secret || '';
 
             // Преобразовать headers обратно в текст
             const headersText = Object.entries(config.webhook_headers || {})
@@ -723,6 +998,25 @@ const alertConfigTemplate = `<!DOCTYPE html>
             document.getElementById('telegramBotToken').value = config.telegram_bot_token || '';
             document.getElementById('telegramChatId').value = config.telegram_chat_id || '';
 
+            // Slack настройки
+            document.getElementById('slackEnabled').checked = config.slack_enabled;
+            document.getElementById('slackBotToken').value = config.slack_bot_token || '';
+            document.getElementById('slackChannel').value = config.slack_channel || '';
+            document.getElementById('slackWebhookUrl').value = config.slack_webhook_url || '';
+
+            // Discord и Teams настройки
+            document.getElementById('discordEnabled').checked = config.discord_enabled;
+            document.getElementById('discordWebhookUrl').value = config.discord_webhook_url || '';
+            document.getElementById('teamsEnabled').checked = config.teams_enabled;
+            document.getElementById('teamsWebhookUrl').value = config.teams_webhook_url || '';
+
+            // PagerDuty и Opsgenie настройки
+            document.getElementById('pagerdutyEnabled').checked = config.pagerduty_enabled;
+            document.getElementById('pagerdutyRoutingKey').value = config.pagerduty_routing_key || '';
+            document.getElementById('opsgenieEnabled').checked = config.opsgenie_enabled;
+            document.getElementById('opsgenieApiKey').value = config.opsgenie_api_key || '';
+            document.getElementById('opsgenieRegion').value = config.opsgenie_region || 'us';
+
             // Условия
             document.getElementById('alertOnDown').checked = config.alert_on_down;
             document.getElementById('alertOnUp').checked = config.alert_on_up;
@@ -730,6 +1024,17 @@ const alertConfigTemplate = `<!DOCTYPE html>
             document.getElementById('sslExpiryDays').value = config.ssl_expiry_days || 30;
             document.getElementById('alertOnResponseTime').checked = config.alert_on_response_time_threshold;
             document.getElementById('responseTimeThreshold').value = config.response_time_threshold || 5000;
+            document.getElementById('responseTimeChecks').value = config.response_time_checks || 1;
+            document.getElementById('alertOnResponseTimeAnomaly').checked = config.alert_on_response_time_anomaly;
+            document.getElementById('responseTimeAnomalySigmas').value = config.response_time_anomaly_sigmas || 3;
+            document.getElementById('alertOnStatusCodeChange').checked = config.alert_on_status_code_change;
+            document.getElementById('alertOnContentChange').checked = config.alert_on_content_change;
+            document.getElementById('alertOnRedirectChange').checked = config.alert_on_redirect_change;
+            document.getElementById('alertOnKeywordLost').checked = config.alert_on_keyword_lost;
+            document.getElementById('alertOnPacketLoss').checked = config.alert_on_packet_loss;
+            document.getElementById('packetLossThreshold').value = config.packet_loss_threshold || 20;
+            document.getElementById('escalationPolicyId').value = config.escalation_policy_id || 0;
+            document.getElementById('onCallScheduleId').value = config.oncall_schedule_id || 0;
 
             document.getElementById('testBtn').style.display = 'inline-flex';
             document.getElementById('configModal').style.display = 'block';
@@ -776,16 +1081,16 @@ const alertConfigTemplate = `<!DOCTYPE html>
                 },
                 body: JSON.stringify(testData)
             })
-            .then(response => {
+            .then(response => response.json().then(data => {
                 if (response.ok) {
-                    alert('Тестовое оповещение отправлено!');
+                    alert('Тестовое оповещение отправлено! ' + (data.message || ''));
                 } else {
-                    throw new Error('Ошибка отправки тестового оповещения');
+                    throw new Error(data.error || 'Ошибка отправки тестового оповещения');
                 }
-            })
+            }))
             .catch(error => {
                 console.error('Error sending test alert:', error);
-                alert('Ошибка отправки тестового оповещения');
+                alert('Ошибка отправки тестового оповещения: ' + error.message);
             });
         }
 
@@ -811,6 +1116,11 @@ const alertConfigTemplate = `<!DOCTYPE html>
                 email_enabled: document.getElementById('emailEnabled').checked,
                 webhook_enabled: document.getElementById('webhookEnabled').checked,
                 telegram_enabled: document.getElementById('telegramEnabled').checked,
+                slack_enabled: document.getElementById('slackEnabled').checked,
+                discord_enabled: document.getElementById('discordEnabled').checked,
+                teams_enabled: document.getElementById('teamsEnabled').checked,
+                pagerduty_enabled: document.getElementById('pagerdutyEnabled').checked,
+                opsgenie_enabled: document.getElementById('opsgenieEnabled').checked,
 
                 // Email settings
                 smtp_server: document.getElementById('smtpServer').value,
@@ -824,18 +1134,44 @@ const alertConfigTemplate = `<!DOCTYPE html>
                 webhook_url: document.getElementById('webhookUrl').value,
                 webhook_headers: webhookHeaders,
                 webhook_timeout: parseInt(document.getElementById('webhookTimeout').value) || 10,
+                webhook_secret: document.getElementById('webhookSecret').value,
 
                 // Telegram settings
                 telegram_bot_token: document.getElementById('telegramBotToken').value,
                 telegram_chat_id: document.getElementById('telegramChatId').value,
 
+                // Slack settings
+                slack_webhook_url: document.getElementById('slackWebhookUrl').value,
+                slack_bot_token: document.getElementById('slackBotToken').value,
+                slack_channel: document.getElementById('slackChannel').value,
+
+                // Discord and Teams settings
+                discord_webhook_url: document.getElementById('discordWebhookUrl').value,
+                teams_webhook_url: document.getElementById('teamsWebhookUrl').value,
+
+                // PagerDuty and Opsgenie settings
+                pagerduty_routing_key: document.getElementById('pagerdutyRoutingKey').value,
+                opsgenie_api_key: document.getElementById('opsgenieApiKey').value,
+                opsgenie_region: document.getElementById('opsgenieRegion').value,
+
                 // Alert conditions
                 alert_on_down: document.getElementById('alertOnDown').checked,
                 alert_on_up: document.getElementById('alertOnUp').checked,
                 alert_on_ssl_expiry: document.getElementById('alertOnSslExpiry').checked,
                 ssl_expiry_days: parseInt(document.getElementById('sslExpiryDays').value) || 30,
                 alert_on_response_time_threshold: document.getElementById('alertOnResponseTime').checked,
-                response_time_threshold: parseInt(document.getElementById('responseTimeThreshold').value) || 5000
+                response_time_threshold: parseInt(document.getElementById('responseTimeThreshold').value) || 5000,
+                response_time_checks: parseInt(document.getElementById('responseTimeChecks').value) || 1,
+                alert_on_response_time_anomaly: document.getElementById('alertOnResponseTimeAnomaly').checked,
+                response_time_anomaly_sigmas: parseFloat(document.getElementById('responseTimeAnomalySigmas').value) || 3,
+                alert_on_status_code_change: document.getElementById('alertOnStatusCodeChange').checked,
+                alert_on_content_change: document.getElementById('alertOnContentChange').checked,
+                alert_on_redirect_change: document.getElementById('alertOnRedirectChange').checked,
+                alert_on_keyword_lost: document.getElementById('alertOnKeywordLost').checked,
+                alert_on_packet_loss: document.getElementById('alertOnPacketLoss').checked,
+                packet_loss_threshold: parseFloat(document.getElementById('packetLossThreshold').value) || 20,
+                escalation_policy_id: parseInt(document.getElementById('escalationPolicyId').value) || 0,
+                oncall_schedule_id: parseInt(document.getElementById('onCallScheduleId').value) || 0
             };
 
             const url = currentConfigName ?
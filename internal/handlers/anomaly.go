@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"ping-tower/internal/anomaly"
+	"ping-tower/internal/routing"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var anomalyService *anomaly.Service
+
+func SetAnomalyService(service *anomaly.Service) {
+	anomalyService = service
+}
+
+// BaselineResponse is the response time band of a site for charting.
+type BaselineResponse struct {
+	SiteID    int                 `json:"site_id"`
+	Sigmas    float64             `json:"sigmas"`
+	UpdatedAt time.Time           `json:"updated_at"`
+	Points    []anomaly.BandPoint `json:"points"`
+}
+
+// GetSiteBaselineHandler - базовая линия времени отклика
+// @Summary Получить базовую линию времени отклика сайта
+// @Description Обычное время отклика по часам: медиана прошлых часов того же часа и дня недели (или того же часа любого дня, если недель мало) и отклонение по MAD. Полоса lower..upper - медиана ± sigmas отклонений; response_time - измеренное среднее часа
+// @Tags metrics
+// @Produce json
+// @Param id path int true "ID сайта"
+// @Param hours query int false "Часов назад" default(24)
+// @Param ahead query int false "Часов вперед" default(0)
+// @Param sigmas query number false "Ширина полосы в отклонениях" default(3)
+// @Success 200 {object} BaselineResponse "Полоса по часам"
+// @Failure 503 {object} ErrorResponse "ClickHouse метрики отключены"
+// @Router /metrics/sites/{id}/baseline [get]
+func GetSiteBaselineHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if anomalyService == nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Response time baselines require ClickHouse metrics"})
+			return
+		}
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid site ID"})
+			return
+		}
+
+		hours := 24
+		if value, err := strconv.Atoi(r.URL.Query().Get("hours")); err == nil && value > 0 && value <= 31*24 {
+			hours = value
+		}
+		ahead := 0
+		if value, err := strconv.Atoi(r.URL.Query().Get("ahead")); err == nil && value >= 0 && value <= 7*24 {
+			ahead = value
+		}
+		sigmas := routing.DefaultAnomalySigmas
+		if value := r.URL.Query().Get("sigmas"); value != "" {
+			sigmas, err = strconv.ParseFloat(value, 64)
+			if err != nil || sigmas <= 0 || sigmas > 20 {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(ErrorResponse{Error: "sigmas must be between 0 and 20"})
+				return
+			}
+		}
+
+		now := time.Now()
+		points, updatedAt := anomalyService.Bands(id, now.Add(-time.Duration(hours)*time.Hour), now.Add(time.Duration(ahead)*time.Hour), sigmas)
+
+		json.NewEncoder(w).Encode(BaselineResponse{
+			SiteID:    id,
+			Sigmas:    sigmas,
+			UpdatedAt: updatedAt,
+			Points:    points,
+		})
+	}
+}
@@ -33,15 +33,18 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
-	"ping-tower/internal/config"
 	"ping-tower/internal/database"
+	"ping-tower/internal/maintenance"
 	"ping-tower/internal/models"
 	"ping-tower/internal/monitor"
 	"ping-tower/internal/notifications"
+	"ping-tower/internal/routing"
+	"ping-tower/internal/sslexpiry"
 	"strconv"
 	"strings"
 	"time"
@@ -103,6 +106,7 @@ func RegisterRoutes(r *mux.Router, db *database.DB) {
 	r.HandleFunc("/demo", DemoHandler()).Methods("GET")
 	r.HandleFunc("/metrics", MetricsWebHandler()).Methods("GET")
 	r.HandleFunc("/alerts", AlertsWebHandler()).Methods("GET")
+	r.HandleFunc("/incidents", IncidentsWebHandler()).Methods("GET")
 
 	// Swagger documentation
 	r.HandleFunc("/swagger", SwaggerUIHandler()).Methods("GET")
@@ -118,6 +122,9 @@ func RegisterRoutes(r *mux.Router, db *database.DB) {
 	r.HandleFunc("/api/sites/{id}/history", GetSiteHistoryHandler(db)).Methods("GET")
 	r.HandleFunc("/api/sites/{id}/config", GetSiteConfigHandler(db)).Methods("GET")
 	r.HandleFunc("/api/sites/{id}/config", UpdateSiteConfigHandler(db)).Methods("PUT")
+	r.HandleFunc("/api/sites/{id}/snapshots", GetSiteSnapshotsHandler(db)).Methods("GET")
+	r.HandleFunc("/api/sites/{id}/snapshots/diff", GetSiteSnapshotDiffHandler()).Methods("GET")
+	r.HandleFunc("/api/sites/{id}/snapshots/{snapshotId:[0-9]+}", GetSiteSnapshotHandler()).Methods("GET")
 
 	// Dashboard and monitoring
 	r.HandleFunc("/api/dashboard/stats", GetDashboardStatsHandler(db)).Methods("GET")
@@ -135,10 +142,79 @@ func RegisterRoutes(r *mux.Router, db *database.DB) {
 	r.HandleFunc("/api/alerts/configs", CreateAlertConfigHandler(db)).Methods("POST")
 	r.HandleFunc("/api/alerts/configs/{name}", DeleteAlertConfigHandler(db)).Methods("DELETE")
 	r.HandleFunc("/api/alerts/test", TestAlertHandler(db)).Methods("POST")
+	r.HandleFunc("/api/alerts/engine", GetAlertEngineStatusHandler()).Methods("GET")
+	r.HandleFunc("/api/sites/{id}/alert-configs", GetSiteAlertConfigsHandler(db)).Methods("GET")
+	r.HandleFunc("/api/sites/{id}/alert-configs", UpdateSiteAlertConfigsHandler(db)).Methods("PUT")
+
+	// Heartbeat (push) monitors
+	r.HandleFunc("/api/heartbeat/{token}", HeartbeatPingHandler(models.HeartbeatPingSuccess)).Methods("POST", "GET")
+	r.HandleFunc("/api/heartbeat/{token}/start", HeartbeatPingHandler(models.HeartbeatPingStart)).Methods("POST", "GET")
+	r.HandleFunc("/api/heartbeat/{token}/fail", HeartbeatPingHandler(models.HeartbeatPingFail)).Methods("POST", "GET")
+	r.HandleFunc("/api/heartbeats", GetHeartbeatsHandler(db)).Methods("GET")
+	r.HandleFunc("/api/heartbeats", CreateHeartbeatHandler(db)).Methods("POST")
+	r.HandleFunc("/api/heartbeats/{id}", GetHeartbeatHandler(db)).Methods("GET")
+	r.HandleFunc("/api/heartbeats/{id}", UpdateHeartbeatHandler(db)).Methods("PUT")
+	r.HandleFunc("/api/heartbeats/{id}", DeleteHeartbeatHandler(db)).Methods("DELETE")
+	r.HandleFunc("/api/heartbeats/{id}/pings", GetHeartbeatPingsHandler(db)).Methods("GET")
+
+	// Incidents
+	r.HandleFunc("/api/incidents", GetIncidentsHandler(db)).Methods("GET")
+	r.HandleFunc("/api/incidents/{id}", GetIncidentHandler(db)).Methods("GET")
+	r.HandleFunc("/api/incidents/{id}/acknowledge", AcknowledgeIncidentHandler()).Methods("POST")
+	r.HandleFunc("/api/incidents/{id}/assign", AssignIncidentHandler()).Methods("POST")
+	r.HandleFunc("/api/incidents/{id}/comments", CommentIncidentHandler()).Methods("POST")
+
+	// Maintenance windows
+	r.HandleFunc("/api/maintenance", GetMaintenanceWindowsHandler(db)).Methods("GET")
+	r.HandleFunc("/api/maintenance", CreateMaintenanceWindowHandler(db)).Methods("POST")
+	r.HandleFunc("/api/maintenance/{id}", GetMaintenanceWindowHandler(db)).Methods("GET")
+	r.HandleFunc("/api/maintenance/{id}", UpdateMaintenanceWindowHandler(db)).Methods("PUT")
+	r.HandleFunc("/api/maintenance/{id}", DeleteMaintenanceWindowHandler(db)).Methods("DELETE")
+
+	// Escalation policies
+	r.HandleFunc("/api/escalation-policies", GetEscalationPoliciesHandler(db)).Methods("GET")
+	r.HandleFunc("/api/escalation-policies", CreateEscalationPolicyHandler(db)).Methods("POST")
+	r.HandleFunc("/api/escalation-policies/{id}", GetEscalationPolicyHandler(db)).Methods("GET")
+	r.HandleFunc("/api/escalation-policies/{id}", UpdateEscalationPolicyHandler(db)).Methods("PUT")
+	r.HandleFunc("/api/escalation-policies/{id}", DeleteEscalationPolicyHandler(db)).Methods("DELETE")
+	r.HandleFunc("/api/escalations", GetEscalationsHandler(db)).Methods("GET")
+	r.HandleFunc("/api/escalations/{id}", GetEscalationHandler(db)).Methods("GET")
+	r.HandleFunc("/api/escalations/{id}/acknowledge", AcknowledgeEscalationHandler()).Methods("POST")
+
+	// On-call schedules
+	r.HandleFunc("/api/oncall/users", GetOnCallUsersHandler(db)).Methods("GET")
+	r.HandleFunc("/api/oncall/users", CreateOnCallUserHandler(db)).Methods("POST")
+	r.HandleFunc("/api/oncall/users/{id}", UpdateOnCallUserHandler(db)).Methods("PUT")
+	r.HandleFunc("/api/oncall/users/{id}", DeleteOnCallUserHandler(db)).Methods("DELETE")
+	r.HandleFunc("/api/oncall/schedules", GetOnCallSchedulesHandler(db)).Methods("GET")
+	r.HandleFunc("/api/oncall/schedules", CreateOnCallScheduleHandler(db)).Methods("POST")
+	r.HandleFunc("/api/oncall/schedules/{id}", GetOnCallScheduleHandler(db)).Methods("GET")
+	r.HandleFunc("/api/oncall/schedules/{id}", UpdateOnCallScheduleHandler(db)).Methods("PUT")
+	r.HandleFunc("/api/oncall/schedules/{id}", DeleteOnCallScheduleHandler(db)).Methods("DELETE")
+	r.HandleFunc("/api/oncall/schedules/{id}/oncall", WhoIsOnCallHandler()).Methods("GET")
+	r.HandleFunc("/api/oncall/schedules/{id}/shifts", GetOnCallShiftsHandler()).Methods("GET")
+	r.HandleFunc("/api/oncall/schedules/{id}/ical", OnCallICalHandler()).Methods("GET")
+	r.HandleFunc("/api/oncall/schedules/{id}/overrides", GetOnCallOverridesHandler(db)).Methods("GET")
+	r.HandleFunc("/api/oncall/schedules/{id}/overrides", CreateOnCallOverrideHandler(db)).Methods("POST")
+	r.HandleFunc("/api/oncall/schedules/{id}/overrides/{overrideId}", DeleteOnCallOverrideHandler(db)).Methods("DELETE")
+
+	// Notification queue
+	r.HandleFunc("/api/notifications/queue", GetQueuedNotificationsHandler(db)).Methods("GET")
+	r.HandleFunc("/api/notifications/queue/{id}", GetQueuedNotificationHandler(db)).Methods("GET")
+	r.HandleFunc("/api/notifications/queue/{id}/resend", ResendNotificationHandler(db)).Methods("POST")
+
+	// Alert templates
+	r.HandleFunc("/api/alert-templates", GetAlertTemplatesHandler(db)).Methods("GET")
+	r.HandleFunc("/api/alert-templates", CreateAlertTemplateHandler(db)).Methods("POST")
+	r.HandleFunc("/api/alert-templates/preview", PreviewAlertTemplateHandler(db)).Methods("POST")
+	r.HandleFunc("/api/alert-templates/{id}", GetAlertTemplateHandler(db)).Methods("GET")
+	r.HandleFunc("/api/alert-templates/{id}", UpdateAlertTemplateHandler(db)).Methods("PUT")
+	r.HandleFunc("/api/alert-templates/{id}", DeleteAlertTemplateHandler(db)).Methods("DELETE")
 
 	// Metrics API endpoints - real data from database
 	r.HandleFunc("/api/metrics/sites/{id}/hourly", HandleGetHourlyMetricsFromDB(db)).Methods("GET")
 	r.HandleFunc("/api/metrics/sites/{id}/performance", HandleGetPerformanceSummaryFromDB(db)).Methods("GET")
+	r.HandleFunc("/api/metrics/sites/{id}/baseline", GetSiteBaselineHandler()).Methods("GET")
 	r.HandleFunc("/api/metrics/aggregated", HandleGetAggregatedMetricsFromDB(db)).Methods("GET")
 	r.HandleFunc("/api/metrics/health", HandleGetSystemHealthFromDB(db)).Methods("GET")
 	r.HandleFunc("/api/metrics/stats", HandleGetMetricsStatsFromDB(db)).Methods("GET")
@@ -167,13 +243,14 @@ func HandleGetSSLAlertsFromDB(db *database.DB) http.HandlerFunc {
 		}
 
 		// Получаем сайты с истекающими SSL сертификатами из PostgreSQL
-		query := `SELECT id, url, ssl_issuer, ssl_expiry, ssl_valid
-				  FROM sites
-				  WHERE url LIKE 'https://%'
-				  AND ssl_expiry IS NOT NULL
-				  AND ssl_expiry > NOW()
-				  AND ssl_expiry <= NOW() + ($1 || ' days')::INTERVAL
-				  ORDER BY ssl_expiry ASC`
+		query := `SELECT s.id, s.url, s.ssl_issuer, s.ssl_expiry, s.ssl_valid, a.threshold
+				  FROM sites s
+				  LEFT JOIN ssl_expiry_alerts a ON a.site_id = s.id AND a.ssl_expiry = s.ssl_expiry
+				  WHERE s.url LIKE 'https://%'
+				  AND s.ssl_expiry IS NOT NULL
+				  AND s.ssl_expiry > NOW()
+				  AND s.ssl_expiry <= NOW() + ($1 || ' days')::INTERVAL
+				  ORDER BY s.ssl_expiry ASC`
 
 		rows, err := db.Query(query, days)
 		if err != nil {
@@ -192,8 +269,9 @@ func HandleGetSSLAlertsFromDB(db *database.DB) http.HandlerFunc {
 			var url, issuer string
 			var expiry time.Time
 			var valid bool
+			var alerted sql.NullInt64
 
-			if err := rows.Scan(&id, &url, &issuer, &expiry, &valid); err != nil {
+			if err := rows.Scan(&id, &url, &issuer, &expiry, &valid, &alerted); err != nil {
 				continue
 			}
 
@@ -205,6 +283,8 @@ func HandleGetSSLAlertsFromDB(db *database.DB) http.HandlerFunc {
 				"ssl_expiry":        expiry,
 				"days_until_expiry": daysUntilExpiry,
 				"is_valid":          valid,
+				// Наименьший порог, о котором уже отправлено оповещение
+				"alerted_threshold": alerted.Int64,
 			})
 		}
 
@@ -1004,7 +1084,7 @@ func AddSiteHandler(db *database.DB) http.HandlerFunc {
 				result := checker.CheckSiteWithConfig(req.URL, config)
 
 				// Обновляем статус в базе данных
-				checker.UpdateSiteStatus(&monitor.Site{ID: site.ID, URL: req.URL}, result)
+				checker.UpdateSiteStatus(&monitor.Site{ID: site.ID, URL: req.URL}, config, &result)
 				checker.SaveCheckHistory(site.ID, result)
 
 				// Отправляем SSE уведомление о проверке
@@ -1268,6 +1348,83 @@ func UpdateSiteConfigHandler(db *database.DB) http.HandlerFunc {
 			return
 		}
 
+		if !models.IsValidCheckType(config.CheckType) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Unsupported check type: " + config.CheckType})
+			return
+		}
+
+		if !models.IsValidDNSRecordType(config.DNSRecordType) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Unsupported DNS record type: " + config.DNSRecordType})
+			return
+		}
+
+		if !models.IsValidStartTLS(config.TLSStartTLS) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Unsupported STARTTLS protocol: " + config.TLSStartTLS})
+			return
+		}
+
+		if err := models.ValidateSteps(config.Steps); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := monitor.ValidateJSONAssertions(config.JSONAssertions); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if !models.IsValidHTTPMethod(config.Method) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Unsupported HTTP method: " + config.Method})
+			return
+		}
+
+		if err := monitor.ValidateStatusCodes(config.ExpectedStatusCodes); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := monitor.ValidateHeaderAssertions(config.HeaderAssertions); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if _, err := sslexpiry.ParseThresholds(config.SSLAlertThresholds); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := monitor.ValidateContentMasks(config.ContentMasks); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		// 0 - значение по умолчанию, его присылают клиенты, не задающие эти поля
+		if config.FailThreshold < 0 || config.FailThreshold > 100 ||
+			config.RecoverThreshold < 0 || config.RecoverThreshold > 100 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "fail_threshold and recover_threshold must be between 0 and 100 (0 means 1)"})
+			return
+		}
+
+		if config.RetryDelay < 0 || config.RetryDelay > monitor.MaxRetryDelay {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: fmt.Sprintf("retry_delay must be between 0 and %d seconds (0 means %d)",
+				monitor.MaxRetryDelay, monitor.DefaultRetryDelay)})
+			return
+		}
+
+		config.Tags = maintenance.NormalizeTags(config.Tags)
+
 		config.SiteID = id
 		err := db.UpdateSiteConfig(&config)
 		if err != nil {
@@ -1478,9 +1635,17 @@ func DeleteAlertConfigHandler(db *database.DB) http.HandlerFunc {
 	}
 }
 
+// TestAlertRequest - параметры тестового алерта
+type TestAlertRequest struct {
+	ConfigName string   `json:"config_name"`
+	TestURL    string   `json:"test_url"`
+	// Каналы для проверки, по умолчанию все включенные в конфигурации
+	Channels   []string `json:"channels,omitempty"`
+}
+
 // TestAlertHandler - тестовая отправка алерта
 // @Summary Тестовая отправка алерта
-// @Description Отправляет тестовый алерт для проверки конфигурации
+// @Description Отправляет тестовый алерт по всем включенным или указанным каналам конфигурации
 // @Tags alerts
 // @Accept json
 // @Produce json
@@ -1492,10 +1657,7 @@ func TestAlertHandler(db *database.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
-		var request struct {
-			ConfigName string `json:"config_name"`
-			TestURL    string `json:"test_url"`
-		}
+		var request TestAlertRequest
 
 		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 			http.Error(w, `{"error": "Invalid JSON"}`, http.StatusBadRequest)
@@ -1515,7 +1677,7 @@ func TestAlertHandler(db *database.DB) http.HandlerFunc {
 		}
 
 		// Convert AlertConfig to notifications config format
-		alertsConfig := convertToNotificationsConfig(alertConfig)
+		alertsConfig := routing.AlertsConfig(alertConfig)
 
 		// Create test alert manager
 		alertManager := notifications.NewAlertManager(alertsConfig)
@@ -1525,6 +1687,21 @@ func TestAlertHandler(db *database.DB) http.HandlerFunc {
 			testURL = "https://example.com"
 		}
 
+		channels := request.Channels
+		if len(channels) == 0 {
+			channels = alertManager.EnabledChannels()
+		}
+		if len(channels) == 0 {
+			http.Error(w, `{"error": "No channels enabled in config"}`, http.StatusBadRequest)
+			return
+		}
+		for _, channel := range channels {
+			if !notifications.IsChannel(channel) {
+				http.Error(w, fmt.Sprintf(`{"error": "Unknown channel: %s"}`, channel), http.StatusBadRequest)
+				return
+			}
+		}
+
 		// Create test result
 		testResult := notifications.CheckResult{
 			Status:       "down",
@@ -1534,50 +1711,120 @@ func TestAlertHandler(db *database.DB) http.HandlerFunc {
 		}
 
 		// Send test alert
-		err = alertManager.SendAlert(0, testURL, testResult, "test")
+		err = alertManager.SendAlertTo(notifications.AlertData{
+			SiteURL:      testURL,
+			Status:       testResult.Status,
+			StatusCode:   testResult.StatusCode,
+			ResponseTime: testResult.ResponseTime,
+			Error:        testResult.Error,
+			Timestamp:    time.Now(),
+			AlertType:    "test",
+			CheckResult:  &testResult,
+		}, notifications.Target{Channels: channels})
 		if err != nil {
 			log.Printf("❌ Ошибка отправки тестового алерта: %v", err)
 			http.Error(w, fmt.Sprintf(`{"error": "Failed to send test alert: %v"}`, err), http.StatusInternalServerError)
 			return
 		}
 
-		log.Printf("✅ Тестовый алерт отправлен для конфигурации: %s", request.ConfigName)
-		json.NewEncoder(w).Encode(SuccessResponse{Message: "Test alert sent successfully"})
+		log.Printf("✅ Тестовый алерт отправлен для конфигурации: %s (%s)", request.ConfigName, strings.Join(channels, ", "))
+		json.NewEncoder(w).Encode(SuccessResponse{Message: "Test alert sent via: " + strings.Join(channels, ", ")})
 	}
 }
 
-// convertToNotificationsConfig converts database AlertConfig to notifications AlertsConfig
-func convertToNotificationsConfig(dbConfig *models.AlertConfig) *config.AlertsConfig {
-	// Parse email recipients
-	var emailTo []string
-	if dbConfig.EmailTo != "" {
-		emailTo = strings.Split(dbConfig.EmailTo, ",")
-		for i := range emailTo {
-			emailTo[i] = strings.TrimSpace(emailTo[i])
+// SiteAlertConfigsRequest - конфигурации алертов, назначенные сайту
+type SiteAlertConfigsRequest struct {
+	AlertConfigs []string `json:"alert_configs"`
+}
+
+// GetSiteAlertConfigsHandler - конфигурации алертов сайта
+// @Summary Получить конфигурации алертов сайта
+// @Description Оповещения сайта отправляются по каждой назначенной включенной конфигурации с ее каналами и условиями. Без назначений действует конфигурация global
+// @Tags alerts
+// @Produce json
+// @Param id path int true "ID сайта"
+// @Success 200 {array} models.AlertConfig "Конфигурации сайта"
+// @Router /sites/{id}/alert-configs [get]
+func GetSiteAlertConfigsHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid site ID"})
+			return
 		}
+
+		configs, err := db.GetSiteAlertConfigs(id)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(configs)
 	}
+}
+
+// UpdateSiteAlertConfigsHandler - назначить конфигурации алертов сайту
+// @Summary Назначить конфигурации алертов сайту
+// @Description Заменяет список конфигураций сайта. Пустой список возвращает сайт к конфигурации global
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param id path int true "ID сайта"
+// @Param request body SiteAlertConfigsRequest true "Имена конфигураций"
+// @Success 200 {array} models.AlertConfig "Конфигурации сайта"
+// @Failure 400 {object} ErrorResponse "Конфигурация не найдена"
+// @Router /sites/{id}/alert-configs [put]
+func UpdateSiteAlertConfigsHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
 
-	return &config.AlertsConfig{
-		Enabled: dbConfig.Enabled,
-		Email: config.EmailAlertConfig{
-			Enabled:    dbConfig.EmailEnabled,
-			SMTPServer: dbConfig.SMTPServer,
-			Port:       dbConfig.SMTPPort,
-			Username:   dbConfig.SMTPUsername,
-			Password:   dbConfig.SMTPPassword,
-			From:       dbConfig.EmailFrom,
-			To:         emailTo,
-		},
-		Webhook: config.WebhookAlertConfig{
-			Enabled: dbConfig.WebhookEnabled,
-			URL:     dbConfig.WebhookURL,
-			Headers: dbConfig.WebhookHeaders,
-			Timeout: dbConfig.WebhookTimeout,
-		},
-		Telegram: config.TelegramAlertConfig{
-			Enabled:  dbConfig.TelegramEnabled,
-			BotToken: dbConfig.TelegramBotToken,
-			ChatID:   dbConfig.TelegramChatID,
-		},
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid site ID"})
+			return
+		}
+
+		var request SiteAlertConfigsRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON"})
+			return
+		}
+
+		if _, err := db.GetSiteConfig(id); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		seen := map[string]bool{}
+		names := []string{}
+		for _, name := range request.AlertConfigs {
+			if name = strings.TrimSpace(name); name != "" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+
+		if err := db.SetSiteAlertConfigs(id, names); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		configs, err := db.GetSiteAlertConfigs(id)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("✅ Сайту %d назначены конфигурации алертов: %v", id, names)
+		json.NewEncoder(w).Encode(configs)
 	}
 }
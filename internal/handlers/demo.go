@@ -490,6 +490,9 @@ const demoTemplate = `<!DOCTYPE html>
                 <a href="/metrics" class="nav-link metrics">
                     <i class="fas fa-chart-bar"></i> Детальные метрики
                 </a>
+                <a href="/incidents" class="nav-link">
+                    <i class="fas fa-fire"></i> Инциденты
+                </a>
                 <a href="/alerts" class="nav-link">
                     <i class="fas fa-bell"></i> Оповещения
                 </a>
@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"ping-tower/internal/database"
+	"ping-tower/internal/escalation"
+	"ping-tower/internal/models"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+var escalationService *escalation.Service
+
+func SetEscalationService(service *escalation.Service) {
+	escalationService = service
+}
+
+// GetEscalationPoliciesHandler - список политик эскалации
+// @Summary Получить политики эскалации
+// @Tags escalations
+// @Produce json
+// @Success 200 {array} models.EscalationPolicy "Политики эскалации"
+// @Router /escalation-policies [get]
+func GetEscalationPoliciesHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		policies, err := db.GetEscalationPolicies()
+		if err != nil {
+			log.Printf("❌ Ошибка получения политик эскалации: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(policies)
+	}
+}
+
+// GetEscalationPolicyHandler - политика эскалации
+// @Summary Получить политику эскалации
+// @Tags escalations
+// @Produce json
+// @Param id path int true "ID политики"
+// @Success 200 {object} models.EscalationPolicy "Политика эскалации"
+// @Failure 404 {object} ErrorResponse "Политика не найдена"
+// @Router /escalation-policies/{id} [get]
+func GetEscalationPolicyHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid escalation policy ID"})
+			return
+		}
+
+		policy, err := db.GetEscalationPolicy(id)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(policy)
+	}
+}
+
+// CreateEscalationPolicyHandler - создать политику эскалации
+// @Summary Создать политику эскалации
+// @Description Шаги выполняются по очереди, пока оповещение не подтверждено: каждый шаг ждет delay_minutes после предыдущего и отправляет оповещение в свои каналы
+// @Tags escalations
+// @Accept json
+// @Produce json
+// @Param policy body models.EscalationPolicy true "Политика эскалации"
+// @Success 201 {object} models.EscalationPolicy "Политика создана"
+// @Failure 400 {object} ErrorResponse "Неверные данные"
+// @Router /escalation-policies [post]
+func CreateEscalationPolicyHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		policy := models.EscalationPolicy{Enabled: true}
+		if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON"})
+			return
+		}
+
+		if err := escalation.Validate(&policy); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := db.CreateEscalationPolicy(&policy); err != nil {
+			log.Printf("❌ Ошибка создания политики эскалации: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to create escalation policy"})
+			return
+		}
+
+		log.Printf("✅ Создана политика эскалации '%s'", policy.Name)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(policy)
+	}
+}
+
+// UpdateEscalationPolicyHandler - обновить политику эскалации
+// @Summary Обновить политику эскалации
+// @Description Запущенные эскалации продолжаются по новым шагам
+// @Tags escalations
+// @Accept json
+// @Produce json
+// @Param id path int true "ID политики"
+// @Param policy body models.EscalationPolicy true "Новые параметры"
+// @Success 200 {object} models.EscalationPolicy "Политика обновлена"
+// @Failure 400 {object} ErrorResponse "Неверные данные"
+// @Router /escalation-policies/{id} [put]
+func UpdateEscalationPolicyHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid escalation policy ID"})
+			return
+		}
+
+		policy, err := db.GetEscalationPolicy(id)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(policy); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON"})
+			return
+		}
+		policy.ID = id
+
+		if err := escalation.Validate(policy); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := db.UpdateEscalationPolicy(policy); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("✅ Обновлена политика эскалации '%s'", policy.Name)
+		json.NewEncoder(w).Encode(policy)
+	}
+}
+
+// DeleteEscalationPolicyHandler - удалить политику эскалации
+// @Summary Удалить политику эскалации
+// @Tags escalations
+// @Produce json
+// @Param id path int true "ID политики"
+// @Success 200 {object} SuccessResponse "Политика удалена"
+// @Failure 404 {object} ErrorResponse "Политика не найдена"
+// @Router /escalation-policies/{id} [delete]
+func DeleteEscalationPolicyHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid escalation policy ID"})
+			return
+		}
+
+		if err := db.DeleteEscalationPolicy(id); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(SuccessResponse{Message: "Escalation policy deleted successfully"})
+	}
+}
+
+// GetEscalationsHandler - список эскалаций
+// @Summary Получить эскалации
+// @Description Возвращает запущенные и завершенные эскалации, начиная с последних
+// @Tags escalations
+// @Produce json
+// @Param status query string false "active, acknowledged, resolved или completed"
+// @Param limit query int false "Количество записей" default(50)
+// @Success 200 {array} models.Escalation "Список эскалаций"
+// @Router /escalations [get]
+func GetEscalationsHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		status := r.URL.Query().Get("status")
+		switch status {
+		case "", models.EscalationStatusActive, models.EscalationStatusAcknowledged,
+			models.EscalationStatusResolved, models.EscalationStatusCompleted:
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "status must be active, acknowledged, resolved or completed"})
+			return
+		}
+
+		limit := 50
+		if value, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && value > 0 && value <= 500 {
+			limit = value
+		}
+
+		escalations, err := db.GetEscalations(status, limit)
+		if err != nil {
+			log.Printf("❌ Ошибка получения эскалаций: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(escalations)
+	}
+}
+
+// GetEscalationHandler - эскалация
+// @Summary Получить эскалацию
+// @Tags escalations
+// @Produce json
+// @Param id path int true "ID эскалации"
+// @Success 200 {object} models.Escalation "Эскалация"
+// @Failure 404 {object} ErrorResponse "Эскалация не найдена"
+// @Router /escalations/{id} [get]
+func GetEscalationHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid escalation ID"})
+			return
+		}
+
+		e, err := db.GetEscalation(id)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(e)
+	}
+}
+
+// AcknowledgeEscalationHandler - подтвердить эскалацию
+// @Summary Подтвердить эскалацию
+// @Description Останавливает эскалацию: следующие шаги не выполняются. Подтверждение инцидента сайта останавливает эскалацию так же
+// @Tags escalations
+// @Accept json
+// @Produce json
+// @Param id path int true "ID эскалации"
+// @Param request body IncidentActionRequest false "Кто подтверждает"
+// @Success 200 {object} models.Escalation "Эскалация подтверждена"
+// @Failure 409 {object} ErrorResponse "Эскалация уже остановлена"
+// @Router /escalations/{id}/acknowledge [post]
+func AcknowledgeEscalationHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if escalationService == nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Escalation service is not available"})
+			return
+		}
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid escalation ID"})
+			return
+		}
+
+		var req IncidentActionRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON"})
+				return
+			}
+		}
+
+		e, err := escalationService.Acknowledge(id, req.User)
+		if err != nil {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(e)
+	}
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"ping-tower/internal/database"
+	"ping-tower/internal/heartbeat"
+	"ping-tower/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var heartbeatService *heartbeat.Service
+
+func SetHeartbeatService(service *heartbeat.Service) {
+	heartbeatService = service
+}
+
+// HeartbeatPingHandler - прием пинга от задания
+// @Summary Пинг heartbeat монитора
+// @Description Задание вызывает URL при каждом запуске. /start отмечает начало, /fail - ошибку. Тело запроса сохраняется как выдержка из лога
+// @Tags heartbeats
+// @Accept plain
+// @Produce json
+// @Param token path string true "Токен монитора"
+// @Success 200 {object} models.Heartbeat "Пинг принят"
+// @Failure 404 {object} ErrorResponse "Монитор не найден"
+// @Router /heartbeat/{token} [post]
+func HeartbeatPingHandler(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if heartbeatService == nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Heartbeat service is not available"})
+			return
+		}
+
+		body, _ := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+
+		hb, err := heartbeatService.RecordPing(mux.Vars(r)["token"], kind, string(body), r.RemoteAddr)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":      hb.Status,
+			"next_due_at": hb.NextDueAt,
+		})
+	}
+}
+
+// GetHeartbeatsHandler - список heartbeat мониторов
+// @Summary Получить heartbeat мониторы
+// @Tags heartbeats
+// @Produce json
+// @Success 200 {array} models.Heartbeat "Список мониторов"
+// @Router /heartbeats [get]
+func GetHeartbeatsHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		heartbeats, err := db.GetHeartbeats()
+		if err != nil {
+			log.Printf("❌ Ошибка получения heartbeat мониторов: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(heartbeats)
+	}
+}
+
+// GetHeartbeatHandler - heartbeat монитор с последними пингами
+// @Summary Получить heartbeat монитор
+// @Tags heartbeats
+// @Produce json
+// @Param id path int true "ID монитора"
+// @Success 200 {object} models.Heartbeat "Монитор"
+// @Failure 404 {object} ErrorResponse "Монитор не найден"
+// @Router /heartbeats/{id} [get]
+func GetHeartbeatHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid heartbeat ID"})
+			return
+		}
+
+		hb, err := db.GetHeartbeat(id)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(hb)
+	}
+}
+
+// GetHeartbeatPingsHandler - история пингов
+// @Summary Получить пинги heartbeat монитора
+// @Tags heartbeats
+// @Produce json
+// @Param id path int true "ID монитора"
+// @Param limit query int false "Количество записей" default(50)
+// @Success 200 {array} models.HeartbeatPing "Пинги"
+// @Router /heartbeats/{id}/pings [get]
+func GetHeartbeatPingsHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid heartbeat ID"})
+			return
+		}
+
+		limit := 50
+		if value, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && value > 0 && value <= 1000 {
+			limit = value
+		}
+
+		pings, err := db.GetHeartbeatPings(id, limit)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(pings)
+	}
+}
+
+// CreateHeartbeatHandler - создать heartbeat монитор
+// @Summary Создать heartbeat монитор
+// @Description Создает монитор и генерирует токен для URL /api/heartbeat/{token}
+// @Tags heartbeats
+// @Accept json
+// @Produce json
+// @Param heartbeat body models.Heartbeat true "Имя, период или cron и время ожидания"
+// @Success 201 {object} models.Heartbeat "Монитор создан"
+// @Failure 400 {object} ErrorResponse "Неверные данные"
+// @Router /heartbeats [post]
+func CreateHeartbeatHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		hb := models.Heartbeat{Enabled: true}
+		if err := json.NewDecoder(r.Body).Decode(&hb); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON"})
+			return
+		}
+
+		if err := heartbeat.Validate(&hb); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		token, err := heartbeat.GenerateToken()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to generate token"})
+			return
+		}
+		hb.Token = token
+		hb.Status = models.HeartbeatStatusNew
+		hb.NextDueAt = heartbeat.NextDue(&hb, time.Now())
+
+		if err := db.CreateHeartbeat(&hb); err != nil {
+			log.Printf("❌ Ошибка создания heartbeat монитора: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to create heartbeat"})
+			return
+		}
+
+		log.Printf("✅ Создан heartbeat монитор '%s'", hb.Name)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(hb)
+	}
+}
+
+// UpdateHeartbeatHandler - обновить heartbeat монитор
+// @Summary Обновить heartbeat монитор
+// @Tags heartbeats
+// @Accept json
+// @Produce json
+// @Param id path int true "ID монитора"
+// @Param heartbeat body models.Heartbeat true "Новые параметры"
+// @Success 200 {object} models.Heartbeat "Монитор обновлен"
+// @Failure 400 {object} ErrorResponse "Неверные данные"
+// @Router /heartbeats/{id} [put]
+func UpdateHeartbeatHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid heartbeat ID"})
+			return
+		}
+
+		hb, err := db.GetHeartbeat(id)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(hb); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON"})
+			return
+		}
+		hb.ID = id
+
+		if err := heartbeat.Validate(hb); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		from := hb.CreatedAt
+		if hb.LastPingAt != nil {
+			from = *hb.LastPingAt
+		}
+		hb.NextDueAt = heartbeat.NextDue(hb, from)
+
+		if err := db.UpdateHeartbeat(hb); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("✅ Обновлен heartbeat монитор '%s'", hb.Name)
+		json.NewEncoder(w).Encode(hb)
+	}
+}
+
+// DeleteHeartbeatHandler - удалить heartbeat монитор
+// @Summary Удалить heartbeat монитор
+// @Tags heartbeats
+// @Produce json
+// @Param id path int true "ID монитора"
+// @Success 200 {object} SuccessResponse "Монитор удален"
+// @Failure 404 {object} ErrorResponse "Монитор не найден"
+// @Router /heartbeats/{id} [delete]
+func DeleteHeartbeatHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid heartbeat ID"})
+			return
+		}
+
+		if err := db.DeleteHeartbeat(id); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(SuccessResponse{Message: "Heartbeat deleted successfully"})
+	}
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"ping-tower/internal/database"
+	"ping-tower/internal/incident"
+	"ping-tower/internal/models"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+var incidentService *incident.Service
+
+func SetIncidentService(service *incident.Service) {
+	incidentService = service
+}
+
+// IncidentActionRequest - тело запросов подтверждения, назначения и комментария
+type IncidentActionRequest struct {
+	User     string `json:"user"`
+	Assignee string `json:"assignee,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// GetIncidentsHandler - список инцидентов
+// @Summary Получить инциденты
+// @Description Возвращает инциденты, начиная с последних. status=active - все незакрытые
+// @Tags incidents
+// @Produce json
+// @Param status query string false "open, acknowledged, resolved или active"
+// @Param site_id query int false "ID сайта"
+// @Param limit query int false "Количество записей" default(50)
+// @Success 200 {array} models.Incident "Список инцидентов"
+// @Router /incidents [get]
+func GetIncidentsHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		status := r.URL.Query().Get("status")
+		switch status {
+		case "", "active", models.IncidentStatusOpen, models.IncidentStatusAcknowledged, models.IncidentStatusResolved:
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "status must be open, acknowledged, resolved or active"})
+			return
+		}
+
+		siteID, _ := strconv.Atoi(r.URL.Query().Get("site_id"))
+
+		limit := 50
+		if value, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && value > 0 && value <= 500 {
+			limit = value
+		}
+
+		incidents, err := db.GetIncidents(status, siteID, limit)
+		if err != nil {
+			log.Printf("❌ Ошибка получения инцидентов: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(incidents)
+	}
+}
+
+// GetIncidentHandler - инцидент с хронологией
+// @Summary Получить инцидент
+// @Description Возвращает инцидент и его хронологию: проверки, отправленные оповещения, действия пользователей
+// @Tags incidents
+// @Produce json
+// @Param id path int true "ID инцидента"
+// @Success 200 {object} models.Incident "Инцидент"
+// @Failure 404 {object} ErrorResponse "Инцидент не найден"
+// @Router /incidents/{id} [get]
+func GetIncidentHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid incident ID"})
+			return
+		}
+
+		inc, err := db.GetIncident(id)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		inc.Events, err = db.GetIncidentEvents(id)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(inc)
+	}
+}
+
+// AcknowledgeIncidentHandler - подтвердить инцидент
+// @Summary Подтвердить инцидент
+// @Description Оповещения по сайту не отправляются, пока инцидент не будет закрыт
+// @Tags incidents
+// @Accept json
+// @Produce json
+// @Param id path int true "ID инцидента"
+// @Param request body IncidentActionRequest false "Кто подтверждает"
+// @Success 200 {object} models.Incident "Инцидент подтвержден"
+// @Failure 409 {object} ErrorResponse "Инцидент уже подтвержден или закрыт"
+// @Router /incidents/{id}/acknowledge [post]
+func AcknowledgeIncidentHandler() http.HandlerFunc {
+	return incidentAction(func(id int, req IncidentActionRequest) (interface{}, error) {
+		return incidentService.Acknowledge(id, req.User)
+	})
+}
+
+// AssignIncidentHandler - назначить ответственного
+// @Summary Назначить ответственного за инцидент
+// @Tags incidents
+// @Accept json
+// @Produce json
+// @Param id path int true "ID инцидента"
+// @Param request body IncidentActionRequest true "assignee - ответственный"
+// @Success 200 {object} models.Incident "Ответственный назначен"
+// @Failure 409 {object} ErrorResponse "Инцидент не найден"
+// @Router /incidents/{id}/assign [post]
+func AssignIncidentHandler() http.HandlerFunc {
+	return incidentAction(func(id int, req IncidentActionRequest) (interface{}, error) {
+		return incidentService.Assign(id, req.Assignee, req.User)
+	})
+}
+
+// CommentIncidentHandler - добавить комментарий
+// @Summary Добавить комментарий к инциденту
+// @Tags incidents
+// @Accept json
+// @Produce json
+// @Param id path int true "ID инцидента"
+// @Param request body IncidentActionRequest true "message - текст комментария"
+// @Success 200 {object} models.IncidentEvent "Комментарий добавлен"
+// @Failure 409 {object} ErrorResponse "Инцидент не найден"
+// @Router /incidents/{id}/comments [post]
+func CommentIncidentHandler() http.HandlerFunc {
+	return incidentAction(func(id int, req IncidentActionRequest) (interface{}, error) {
+		return incidentService.Comment(id, req.User, req.Message)
+	})
+}
+
+func incidentAction(action func(id int, req IncidentActionRequest) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if incidentService == nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Incident service is not available"})
+			return
+		}
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid incident ID"})
+			return
+		}
+
+		var req IncidentActionRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON"})
+				return
+			}
+		}
+
+		result, err := action(id, req)
+		if err != nil {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(result)
+	}
+}
@@ -0,0 +1,539 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+)
+
+const incidentsTemplate = `<!DOCTYPE html>
+<html lang="ru">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Site Monitor - Инциденты</title>
+    <link href="https://cdnjs.cloudflare.com/ajax/libs/font-awesome/6.0.0/css/all.min.css" rel="stylesheet">
+    <style>
+        * {
+            margin: 0;
+            padding: 0;
+            box-sizing: border-box;
+        }
+
+        body {
+            font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif;
+            background: linear-gradient(135deg, #2c3e50 0%, #34495e 100%);
+            min-height: 100vh;
+            color: white;
+        }
+
+        .container {
+            max-width: 1200px;
+            margin: 0 auto;
+            padding: 20px;
+        }
+
+        .navigation {
+            background: rgba(255, 255, 255, 0.1);
+            backdrop-filter: blur(15px);
+            border-radius: 15px;
+            padding: 15px 30px;
+            margin-bottom: 30px;
+            display: flex;
+            justify-content: space-between;
+            align-items: center;
+            gap: 10px;
+            box-shadow: 0 4px 20px rgba(0, 0, 0, 0.1);
+            border: 1px solid rgba(255, 255, 255, 0.1);
+        }
+
+        .nav-brand {
+            display: flex;
+            align-items: center;
+            gap: 10px;
+            font-weight: bold;
+            font-size: 1.1em;
+        }
+
+        .nav-links {
+            display: flex;
+            gap: 20px;
+        }
+
+        .nav-link {
+            color: rgba(255, 255, 255, 0.8);
+            text-decoration: none;
+            padding: 8px 16px;
+            border-radius: 8px;
+            transition: all 0.3s ease;
+            display: flex;
+            align-items: center;
+            gap: 8px;
+        }
+
+        .nav-link.active {
+            background: rgba(255, 255, 255, 0.2);
+            color: white;
+        }
+
+        .nav-link:hover {
+            background: rgba(255, 255, 255, 0.15);
+            color: white;
+        }
+
+        .header {
+            background: rgba(255, 255, 255, 0.1);
+            backdrop-filter: blur(15px);
+            border-radius: 20px;
+            padding: 30px;
+            margin-bottom: 30px;
+            text-align: center;
+            box-shadow: 0 8px 32px rgba(0, 0, 0, 0.1);
+            border: 1px solid rgba(255, 255, 255, 0.1);
+        }
+
+        .header h1 {
+            font-size: 2.5em;
+            margin-bottom: 10px;
+        }
+
+        .header p {
+            color: rgba(255, 255, 255, 0.8);
+            font-size: 1.2em;
+        }
+
+        .layout {
+            display: grid;
+            grid-template-columns: 2fr 3fr;
+            gap: 30px;
+        }
+
+        .panel {
+            background: rgba(255, 255, 255, 0.1);
+            backdrop-filter: blur(15px);
+            border-radius: 15px;
+            padding: 25px;
+            box-shadow: 0 8px 32px rgba(0, 0, 0, 0.1);
+            border: 1px solid rgba(255, 255, 255, 0.1);
+        }
+
+        .panel h3 {
+            margin-bottom: 20px;
+            display: flex;
+            align-items: center;
+            gap: 10px;
+        }
+
+        .filters {
+            display: flex;
+            gap: 8px;
+            margin-bottom: 15px;
+            flex-wrap: wrap;
+        }
+
+        .filter {
+            padding: 6px 12px;
+            border-radius: 8px;
+            border: 1px solid rgba(255, 255, 255, 0.2);
+            background: transparent;
+            color: rgba(255, 255, 255, 0.8);
+            cursor: pointer;
+        }
+
+        .filter.active {
+            background: rgba(255, 255, 255, 0.2);
+            color: white;
+        }
+
+        .incident-item {
+            padding: 15px;
+            border-radius: 10px;
+            margin-bottom: 10px;
+            background: rgba(255, 255, 255, 0.05);
+            cursor: pointer;
+            transition: all 0.3s ease;
+        }
+
+        .incident-item:hover, .incident-item.selected {
+            background: rgba(255, 255, 255, 0.15);
+        }
+
+        .incident-title {
+            font-weight: bold;
+            margin-bottom: 6px;
+            word-break: break-all;
+        }
+
+        .incident-meta {
+            color: rgba(255, 255, 255, 0.7);
+            font-size: 0.9em;
+        }
+
+        .badge {
+            display: inline-block;
+            padding: 2px 10px;
+            border-radius: 10px;
+            font-size: 0.8em;
+            font-weight: bold;
+            text-transform: uppercase;
+            margin-right: 8px;
+        }
+
+        .badge-open {
+            background: #e74c3c;
+        }
+
+        .badge-acknowledged {
+            background: #f39c12;
+        }
+
+        .badge-resolved {
+            background: #27ae60;
+        }
+
+        .btn {
+            padding: 10px 18px;
+            border: none;
+            border-radius: 10px;
+            font-size: 14px;
+            font-weight: bold;
+            cursor: pointer;
+            transition: all 0.3s ease;
+            display: inline-flex;
+            align-items: center;
+            gap: 8px;
+            color: white;
+        }
+
+        .btn-primary {
+            background: linear-gradient(45deg, #3498db, #2980b9);
+        }
+
+        .btn-warning {
+            background: linear-gradient(45deg, #f39c12, #e67e22);
+        }
+
+        .btn:hover {
+            transform: translateY(-2px);
+        }
+
+        .actions {
+            display: flex;
+            gap: 10px;
+            flex-wrap: wrap;
+            margin: 20px 0;
+        }
+
+        .form-input {
+            width: 100%;
+            padding: 10px 14px;
+            border: 1px solid rgba(255, 255, 255, 0.2);
+            border-radius: 8px;
+            background: rgba(255, 255, 255, 0.1);
+            color: white;
+            font-size: 14px;
+            margin-bottom: 10px;
+        }
+
+        .form-input::placeholder {
+            color: rgba(255, 255, 255, 0.6);
+        }
+
+        .details-grid {
+            display: grid;
+            grid-template-columns: 1fr 1fr;
+            gap: 10px;
+            color: rgba(255, 255, 255, 0.85);
+        }
+
+        .timeline {
+            margin-top: 20px;
+            border-left: 2px solid rgba(255, 255, 255, 0.2);
+            padding-left: 20px;
+        }
+
+        .timeline-event {
+            position: relative;
+            margin-bottom: 15px;
+        }
+
+        .timeline-event::before {
+            content: '';
+            position: absolute;
+            left: -27px;
+            top: 4px;
+            width: 12px;
+            height: 12px;
+            border-radius: 50%;
+            background: #3498db;
+        }
+
+        .timeline-event.event-opened::before, .timeline-event.status-down::before, .timeline-event.status-failed::before {
+            background: #e74c3c;
+        }
+
+        .timeline-event.event-resolved::before, .timeline-event.status-up::before {
+            background: #27ae60;
+        }
+
+        .timeline-event.event-acknowledged::before, .timeline-event.event-comment::before {
+            background: #f39c12;
+        }
+
+        .timeline-time {
+            color: rgba(255, 255, 255, 0.6);
+            font-size: 0.85em;
+        }
+
+        .timeline-message {
+            word-break: break-word;
+        }
+
+        .empty {
+            color: rgba(255, 255, 255, 0.6);
+            text-align: center;
+            padding: 30px;
+        }
+
+        @media (max-width: 900px) {
+            .layout {
+                grid-template-columns: 1fr;
+            }
+        }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <!-- Навигационная панель -->
+        <nav class="navigation">
+            <div class="nav-brand">
+                <i class="fas fa-globe"></i>
+                Site Monitor
+            </div>
+            <div class="nav-links">
+                <a href="/" class="nav-link">
+                    <i class="fas fa-tachometer-alt"></i> Дашборд
+                </a>
+                <a href="/metrics" class="nav-link">
+                    <i class="fas fa-chart-line"></i> Метрики
+                </a>
+                <a href="/incidents" class="nav-link active">
+                    <i class="fas fa-fire"></i> Инциденты
+                </a>
+                <a href="/alerts" class="nav-link">
+                    <i class="fas fa-bell"></i> Оповещения
+                </a>
+            </div>
+        </nav>
+
+        <div class="header">
+            <h1><i class="fas fa-fire"></i> Инциденты</h1>
+            <p>Сбои сайтов: подтверждение, назначение ответственного и хронология</p>
+        </div>
+
+        <div class="layout">
+            <div class="panel">
+                <h3><i class="fas fa-list"></i> Список инцидентов</h3>
+                <div class="filters">
+                    <button class="filter active" data-status="active" onclick="setFilter(this)">Активные</button>
+                    <button class="filter" data-status="open" onclick="setFilter(this)">Открытые</button>
+                    <button class="filter" data-status="acknowledged" onclick="setFilter(this)">Подтвержденные</button>
+                    <button class="filter" data-status="resolved" onclick="setFilter(this)">Закрытые</button>
+                    <button class="filter" data-status="" onclick="setFilter(this)">Все</button>
+                </div>
+                <div id="incidentList"><div class="empty">Загрузка...</div></div>
+            </div>
+
+            <div class="panel" id="incidentDetails">
+                <div class="empty">Выберите инцидент</div>
+            </div>
+        </div>
+    </div>
+
+    <script>
+        let currentFilter = 'active';
+        let selectedIncident = null;
+
+        const statusLabels = {
+            open: 'Открыт',
+            acknowledged: 'Подтвержден',
+            resolved: 'Закрыт'
+        };
+
+        function escapeHtml(text) {
+            const div = document.createElement('div');
+            div.textContent = text == null ? '' : String(text);
+            return div.innerHTML;
+        }
+
+        function formatTime(value) {
+            return value ? new Date(value).toLocaleString('ru-RU') : '-';
+        }
+
+        function formatDuration(from, to) {
+            const seconds = Math.max(0, Math.round(((to ? new Date(to) : new Date()) - new Date(from)) / 1000));
+            if (seconds < 60) return seconds + ' с';
+            if (seconds < 3600) return Math.floor(seconds / 60) + ' мин';
+            return Math.floor(seconds / 3600) + ' ч ' + Math.floor((seconds % 3600) / 60) + ' мин';
+        }
+
+        function badge(status) {
+            return '<span class="badge badge-' + status + '">' + (statusLabels[status] || status) + '</span>';
+        }
+
+        function setFilter(button) {
+            document.querySelectorAll('.filter').forEach(function(b) { b.classList.remove('active'); });
+            button.classList.add('active');
+            currentFilter = button.dataset.status;
+            loadIncidents();
+        }
+
+        async function loadIncidents() {
+            const list = document.getElementById('incidentList');
+            try {
+                const response = await fetch('/api/incidents?status=' + encodeURIComponent(currentFilter));
+                const incidents = await response.json();
+                if (!response.ok) throw new Error(incidents.error || response.statusText);
+
+                if (incidents.length === 0) {
+                    list.innerHTML = '<div class="empty">Инцидентов нет</div>';
+                    return;
+                }
+
+                list.innerHTML = incidents.map(function(inc) {
+                    return '<div class="incident-item' + (inc.id === selectedIncident ? ' selected' : '') +
+                        '" onclick="showIncident(' + inc.id + ')">' +
+                        '<div class="incident-title">' + badge(inc.status) + '#' + inc.id + ' ' + escapeHtml(inc.title) + '</div>' +
+                        '<div class="incident-meta">' + formatTime(inc.started_at) +
+                        ' · длительность ' + formatDuration(inc.started_at, inc.resolved_at) +
+                        (inc.assigned_to ? ' · ' + escapeHtml(inc.assigned_to) : '') + '</div>' +
+                        '</div>';
+                }).join('');
+            } catch (error) {
+                list.innerHTML = '<div class="empty">Ошибка загрузки: ' + escapeHtml(error.message) + '</div>';
+            }
+        }
+
+        async function showIncident(id) {
+            selectedIncident = id;
+            const panel = document.getElementById('incidentDetails');
+            try {
+                const response = await fetch('/api/incidents/' + id);
+                const inc = await response.json();
+                if (!response.ok) throw new Error(inc.error || response.statusText);
+
+                let html = '<h3>' + badge(inc.status) + '#' + inc.id + ' ' + escapeHtml(inc.title) + '</h3>' +
+                    '<div class="details-grid">' +
+                    '<div>Начало: ' + formatTime(inc.started_at) + '</div>' +
+                    '<div>Закрыт: ' + formatTime(inc.resolved_at) + '</div>' +
+                    '<div>Проверок: ' + inc.check_count + ' (неудачных: ' + inc.failed_checks + ')</div>' +
+                    '<div>Длительность: ' + formatDuration(inc.started_at, inc.resolved_at) + '</div>' +
+                    '<div>Подтвердил: ' + escapeHtml(inc.acknowledged_by || '-') + '</div>' +
+                    '<div>Ответственный: ' + escapeHtml(inc.assigned_to || '-') + '</div>' +
+                    '</div>' +
+                    '<p style="margin-top: 15px;">Причина: ' + escapeHtml(inc.cause || '-') + '</p>';
+
+                if (inc.status !== 'resolved') {
+                    html += '<div class="actions">' +
+                        (inc.status === 'open' ? '<button class="btn btn-warning" onclick="acknowledgeIncident(' + inc.id + ')"><i class="fas fa-check"></i> Подтвердить</button>' : '') +
+                        '<button class="btn btn-primary" onclick="assignIncident(' + inc.id + ')"><i class="fas fa-user"></i> Назначить</button>' +
+                        '</div>';
+                }
+
+                html += '<input class="form-input" id="incidentUser" placeholder="Ваше имя" value="' + escapeHtml(localStorage.getItem('incidentUser') || '') + '">' +
+                    '<textarea class="form-input" id="incidentComment" rows="3" placeholder="Комментарий"></textarea>' +
+                    '<button class="btn btn-primary" onclick="commentIncident(' + inc.id + ')"><i class="fas fa-comment"></i> Добавить комментарий</button>';
+
+                html += '<div class="timeline">' + (inc.events || []).map(function(e) {
+                    let details = '';
+                    if (e.type === 'check' || e.type === 'opened') {
+                        details = (e.status ? e.status.toUpperCase() : '') +
+                            (e.status_code ? ' · HTTP ' + e.status_code : '') +
+                            (e.response_time ? ' · ' + e.response_time + ' мс' : '');
+                    } else if (e.type === 'alert') {
+                        details = escapeHtml(e.channel) + ' · ' + escapeHtml(e.status);
+                    }
+                    return '<div class="timeline-event event-' + e.type + (e.status ? ' status-' + e.status : '') + '">' +
+                        '<div class="timeline-time">' + formatTime(e.created_at) + ' · ' + escapeHtml(e.type) +
+                        (e.author ? ' · ' + escapeHtml(e.author) : '') + '</div>' +
+                        '<div class="timeline-message">' + escapeHtml(e.message) + '</div>' +
+                        (details ? '<div class="timeline-time">' + details + '</div>' : '') +
+                        '</div>';
+                }).join('') + '</div>';
+
+                panel.innerHTML = html;
+                loadIncidents();
+            } catch (error) {
+                panel.innerHTML = '<div class="empty">Ошибка загрузки: ' + escapeHtml(error.message) + '</div>';
+            }
+        }
+
+        function currentUser() {
+            const user = document.getElementById('incidentUser').value.trim();
+            localStorage.setItem('incidentUser', user);
+            return user;
+        }
+
+        async function incidentAction(id, action, body) {
+            const response = await fetch('/api/incidents/' + id + '/' + action, {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(body)
+            });
+            if (!response.ok) {
+                const result = await response.json();
+                alert('Ошибка: ' + (result.error || response.statusText));
+                return;
+            }
+            showIncident(id);
+        }
+
+        function acknowledgeIncident(id) {
+            incidentAction(id, 'acknowledge', { user: currentUser() });
+        }
+
+        function assignIncident(id) {
+            const assignee = prompt('Ответственный:');
+            if (assignee) {
+                incidentAction(id, 'assign', { user: currentUser(), assignee: assignee });
+            }
+        }
+
+        function commentIncident(id) {
+            const message = document.getElementById('incidentComment').value.trim();
+            if (message) {
+                incidentAction(id, 'comments', { user: currentUser(), message: message });
+            }
+        }
+
+        const eventSource = new EventSource('/api/sse');
+        eventSource.onmessage = function(event) {
+            try {
+                const message = JSON.parse(event.data);
+                if (message.type === 'incident_updated') {
+                    loadIncidents();
+                    if (message.data.id === selectedIncident) {
+                        showIncident(selectedIncident);
+                    }
+                }
+            } catch (error) {
+                console.error('Ошибка парсинга SSE сообщения:', error);
+            }
+        };
+
+        loadIncidents();
+    </script>
+</body>
+</html>`
+
+func IncidentsWebHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		tmpl, err := template.New("incidents").Parse(incidentsTemplate)
+		if err != nil {
+			http.Error(w, "Error parsing template", http.StatusInternalServerError)
+			return
+		}
+
+		tmpl.Execute(w, nil)
+	}
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"ping-tower/internal/database"
+	"ping-tower/internal/maintenance"
+	"ping-tower/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// GetMaintenanceWindowsHandler - список окон обслуживания
+// @Summary Получить окна обслуживания
+// @Description Поле active показывает, действует ли окно сейчас
+// @Tags maintenance
+// @Produce json
+// @Success 200 {array} models.MaintenanceWindow "Окна обслуживания"
+// @Router /maintenance [get]
+func GetMaintenanceWindowsHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		windows, err := db.GetMaintenanceWindows()
+		if err != nil {
+			log.Printf("❌ Ошибка получения окон обслуживания: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		maintenance.MarkActive(windows, time.Now())
+		json.NewEncoder(w).Encode(windows)
+	}
+}
+
+// GetMaintenanceWindowHandler - окно обслуживания
+// @Summary Получить окно обслуживания
+// @Tags maintenance
+// @Produce json
+// @Param id path int true "ID окна"
+// @Success 200 {object} models.MaintenanceWindow "Окно обслуживания"
+// @Failure 404 {object} ErrorResponse "Окно не найдено"
+// @Router /maintenance/{id} [get]
+func GetMaintenanceWindowHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid maintenance window ID"})
+			return
+		}
+
+		window, err := db.GetMaintenanceWindow(id)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		window.Active = maintenance.IsActive(window, time.Now())
+		json.NewEncoder(w).Encode(window)
+	}
+}
+
+// CreateMaintenanceWindowHandler - создать окно обслуживания
+// @Summary Создать окно обслуживания
+// @Description Разовое окно задается starts_at/ends_at, повторяющееся - cron_schedule и duration_minutes. Область: all, sites (site_ids) или tags
+// @Tags maintenance
+// @Accept json
+// @Produce json
+// @Param window body models.MaintenanceWindow true "Окно обслуживания"
+// @Success 201 {object} models.MaintenanceWindow "Окно создано"
+// @Failure 400 {object} ErrorResponse "Неверные данные"
+// @Router /maintenance [post]
+func CreateMaintenanceWindowHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		window := models.MaintenanceWindow{Enabled: true}
+		if err := json.NewDecoder(r.Body).Decode(&window); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON"})
+			return
+		}
+
+		if err := maintenance.Validate(&window); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := db.CreateMaintenanceWindow(&window); err != nil {
+			log.Printf("❌ Ошибка создания окна обслуживания: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to create maintenance window"})
+			return
+		}
+
+		window.Active = maintenance.IsActive(&window, time.Now())
+		log.Printf("✅ Создано окно обслуживания '%s'", window.Name)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(window)
+	}
+}
+
+// UpdateMaintenanceWindowHandler - обновить окно обслуживания
+// @Summary Обновить окно обслуживания
+// @Tags maintenance
+// @Accept json
+// @Produce json
+// @Param id path int true "ID окна"
+// @Param window body models.MaintenanceWindow true "Новые параметры"
+// @Success 200 {object} models.MaintenanceWindow "Окно обновлено"
+// @Failure 400 {object} ErrorResponse "Неверные данные"
+// @Router /maintenance/{id} [put]
+func UpdateMaintenanceWindowHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid maintenance window ID"})
+			return
+		}
+
+		window, err := db.GetMaintenanceWindow(id)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(window); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON"})
+			return
+		}
+		window.ID = id
+
+		if err := maintenance.Validate(window); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := db.UpdateMaintenanceWindow(window); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		window.Active = maintenance.IsActive(window, time.Now())
+		log.Printf("✅ Обновлено окно обслуживания '%s'", window.Name)
+		json.NewEncoder(w).Encode(window)
+	}
+}
+
+// DeleteMaintenanceWindowHandler - удалить окно обслуживания
+// @Summary Удалить окно обслуживания
+// @Tags maintenance
+// @Produce json
+// @Param id path int true "ID окна"
+// @Success 200 {object} SuccessResponse "Окно удалено"
+// @Failure 404 {object} ErrorResponse "Окно не найдено"
+// @Router /maintenance/{id} [delete]
+func DeleteMaintenanceWindowHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid maintenance window ID"})
+			return
+		}
+
+		if err := db.DeleteMaintenanceWindow(id); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(SuccessResponse{Message: "Maintenance window deleted successfully"})
+	}
+}
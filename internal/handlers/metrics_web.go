@@ -141,6 +141,9 @@ const metricsTemplate = `<!DOCTYPE html>
                 <a href="/metrics" class="nav-link active">
                     <i class="fas fa-chart-bar"></i> Метрики
                 </a>
+                <a href="/incidents" class="nav-link">
+                    <i class="fas fa-fire"></i> Инциденты
+                </a>
                 <a href="/alerts" class="nav-link">
                     <i class="fas fa-bell"></i> Оповещения
                 </a>
@@ -410,6 +413,13 @@ const metricsTemplate = `<!DOCTYPE html>
                         option.textContent = site.url;
                         select.appendChild(option);
                     });
+
+                    // Ссылки из оповещений открывают метрики сайта: /metrics?site=ID
+                    const siteParam = new URLSearchParams(window.location.search).get('site');
+                    if (siteParam && select.querySelector('option[value="' + siteParam + '"]')) {
+                        select.value = siteParam;
+                        updateMetrics();
+                    }
                 })
                 .catch(error => console.error('Ошибка загрузки сайтов:', error));
         }
@@ -0,0 +1,591 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"ping-tower/internal/database"
+	"ping-tower/internal/models"
+	"ping-tower/internal/oncall"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var onCallService *oncall.Service
+
+func SetOnCallService(service *oncall.Service) {
+	onCallService = service
+}
+
+// GetOnCallUsersHandler - список дежурных
+// @Summary Получить дежурных
+// @Tags oncall
+// @Produce json
+// @Success 200 {array} models.OnCallUser "Дежурные"
+// @Router /oncall/users [get]
+func GetOnCallUsersHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		users, err := db.GetOnCallUsers()
+		if err != nil {
+			log.Printf("❌ Ошибка получения дежурных: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(users)
+	}
+}
+
+// CreateOnCallUserHandler - добавить дежурного
+// @Summary Добавить дежурного
+// @Description Оповещения отправляются на контакты дежурного: email, Telegram чат, webhook
+// @Tags oncall
+// @Accept json
+// @Produce json
+// @Param user body models.OnCallUser true "Имя и контакты"
+// @Success 201 {object} models.OnCallUser "Дежурный добавлен"
+// @Failure 400 {object} ErrorResponse "Неверные данные"
+// @Router /oncall/users [post]
+func CreateOnCallUserHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var user models.OnCallUser
+		if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON"})
+			return
+		}
+
+		if err := oncall.ValidateUser(&user); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := db.CreateOnCallUser(&user); err != nil {
+			log.Printf("❌ Ошибка добавления дежурного: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to create on-call user"})
+			return
+		}
+
+		log.Printf("✅ Добавлен дежурный '%s'", user.Name)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(user)
+	}
+}
+
+// UpdateOnCallUserHandler - обновить дежурного
+// @Summary Обновить дежурного
+// @Tags oncall
+// @Accept json
+// @Produce json
+// @Param id path int true "ID дежурного"
+// @Param user body models.OnCallUser true "Имя и контакты"
+// @Success 200 {object} models.OnCallUser "Дежурный обновлен"
+// @Failure 400 {object} ErrorResponse "Неверные данные"
+// @Router /oncall/users/{id} [put]
+func UpdateOnCallUserHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid user ID"})
+			return
+		}
+
+		user, err := db.GetOnCallUser(id)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(user); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON"})
+			return
+		}
+		user.ID = id
+
+		if err := oncall.ValidateUser(user); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := db.UpdateOnCallUser(user); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(user)
+	}
+}
+
+// DeleteOnCallUserHandler - удалить дежурного
+// @Summary Удалить дежурного
+// @Description Замены дежурного удаляются, из слоев расписаний его нужно убрать отдельно
+// @Tags oncall
+// @Produce json
+// @Param id path int true "ID дежурного"
+// @Success 200 {object} SuccessResponse "Дежурный удален"
+// @Failure 404 {object} ErrorResponse "Дежурный не найден"
+// @Router /oncall/users/{id} [delete]
+func DeleteOnCallUserHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid user ID"})
+			return
+		}
+
+		if err := db.DeleteOnCallUser(id); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(SuccessResponse{Message: "On-call user deleted successfully"})
+	}
+}
+
+// GetOnCallSchedulesHandler - список расписаний дежурств
+// @Summary Получить расписания дежурств
+// @Tags oncall
+// @Produce json
+// @Success 200 {array} models.OnCallSchedule "Расписания"
+// @Router /oncall/schedules [get]
+func GetOnCallSchedulesHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		schedules, err := db.GetOnCallSchedules()
+		if err != nil {
+			log.Printf("❌ Ошибка получения расписаний дежурств: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(schedules)
+	}
+}
+
+// GetOnCallScheduleHandler - расписание дежурств
+// @Summary Получить расписание дежурств
+// @Tags oncall
+// @Produce json
+// @Param id path int true "ID расписания"
+// @Success 200 {object} models.OnCallSchedule "Расписание"
+// @Failure 404 {object} ErrorResponse "Расписание не найдено"
+// @Router /oncall/schedules/{id} [get]
+func GetOnCallScheduleHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid schedule ID"})
+			return
+		}
+
+		schedule, err := db.GetOnCallSchedule(id)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(schedule)
+	}
+}
+
+// CreateOnCallScheduleHandler - создать расписание дежурств
+// @Summary Создать расписание дежурств
+// @Description Слои ротируют дежурных ежедневно или еженедельно с передачей смены во время start_at. Из нескольких слоев действует последний, в котором кто-то дежурит
+// @Tags oncall
+// @Accept json
+// @Produce json
+// @Param schedule body models.OnCallSchedule true "Расписание"
+// @Success 201 {object} models.OnCallSchedule "Расписание создано"
+// @Failure 400 {object} ErrorResponse "Неверные данные"
+// @Router /oncall/schedules [post]
+func CreateOnCallScheduleHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		schedule := models.OnCallSchedule{Enabled: true}
+		if err := json.NewDecoder(r.Body).Decode(&schedule); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON"})
+			return
+		}
+
+		if err := oncall.Validate(&schedule); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := db.CreateOnCallSchedule(&schedule); err != nil {
+			log.Printf("❌ Ошибка создания расписания дежурств: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to create on-call schedule"})
+			return
+		}
+
+		log.Printf("✅ Создано расписание дежурств '%s'", schedule.Name)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(schedule)
+	}
+}
+
+// UpdateOnCallScheduleHandler - обновить расписание дежурств
+// @Summary Обновить расписание дежурств
+// @Tags oncall
+// @Accept json
+// @Produce json
+// @Param id path int true "ID расписания"
+// @Param schedule body models.OnCallSchedule true "Новые параметры"
+// @Success 200 {object} models.OnCallSchedule "Расписание обновлено"
+// @Failure 400 {object} ErrorResponse "Неверные данные"
+// @Router /oncall/schedules/{id} [put]
+func UpdateOnCallScheduleHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid schedule ID"})
+			return
+		}
+
+		schedule, err := db.GetOnCallSchedule(id)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(schedule); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON"})
+			return
+		}
+		schedule.ID = id
+
+		if err := oncall.Validate(schedule); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := db.UpdateOnCallSchedule(schedule); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		log.Printf("✅ Обновлено расписание дежурств '%s'", schedule.Name)
+		json.NewEncoder(w).Encode(schedule)
+	}
+}
+
+// DeleteOnCallScheduleHandler - удалить расписание дежурств
+// @Summary Удалить расписание дежурств
+// @Tags oncall
+// @Produce json
+// @Param id path int true "ID расписания"
+// @Success 200 {object} SuccessResponse "Расписание удалено"
+// @Failure 404 {object} ErrorResponse "Расписание не найдено"
+// @Router /oncall/schedules/{id} [delete]
+func DeleteOnCallScheduleHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid schedule ID"})
+			return
+		}
+
+		if err := db.DeleteOnCallSchedule(id); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(SuccessResponse{Message: "On-call schedule deleted successfully"})
+	}
+}
+
+// GetOnCallOverridesHandler - замены в расписании
+// @Summary Получить замены
+// @Description Возвращает текущие и будущие замены
+// @Tags oncall
+// @Produce json
+// @Param id path int true "ID расписания"
+// @Success 200 {array} models.OnCallOverride "Замены"
+// @Router /oncall/schedules/{id}/overrides [get]
+func GetOnCallOverridesHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid schedule ID"})
+			return
+		}
+
+		overrides, err := db.GetOnCallOverrides(id, time.Now(), time.Now().Add(oncall.MaxRange))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(overrides)
+	}
+}
+
+// CreateOnCallOverrideHandler - добавить замену
+// @Summary Добавить замену
+// @Description На время замены дежурит указанный пользователь вместо всех слоев
+// @Tags oncall
+// @Accept json
+// @Produce json
+// @Param id path int true "ID расписания"
+// @Param override body models.OnCallOverride true "Пользователь и период"
+// @Success 201 {object} models.OnCallOverride "Замена добавлена"
+// @Failure 400 {object} ErrorResponse "Неверные данные"
+// @Router /oncall/schedules/{id}/overrides [post]
+func CreateOnCallOverrideHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid schedule ID"})
+			return
+		}
+
+		var override models.OnCallOverride
+		if err := json.NewDecoder(r.Body).Decode(&override); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON"})
+			return
+		}
+		override.ScheduleID = id
+
+		if err := oncall.ValidateOverride(&override); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if _, err := db.GetOnCallSchedule(id); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+		if _, err := db.GetOnCallUser(override.UserID); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := db.CreateOnCallOverride(&override); err != nil {
+			log.Printf("❌ Ошибка добавления замены: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to create override"})
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(override)
+	}
+}
+
+// DeleteOnCallOverrideHandler - удалить замену
+// @Summary Удалить замену
+// @Tags oncall
+// @Produce json
+// @Param id path int true "ID расписания"
+// @Param overrideId path int true "ID замены"
+// @Success 200 {object} SuccessResponse "Замена удалена"
+// @Failure 404 {object} ErrorResponse "Замена не найдена"
+// @Router /oncall/schedules/{id}/overrides/{overrideId} [delete]
+func DeleteOnCallOverrideHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		vars := mux.Vars(r)
+		id, err1 := strconv.Atoi(vars["id"])
+		overrideID, err2 := strconv.Atoi(vars["overrideId"])
+		if err1 != nil || err2 != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid schedule or override ID"})
+			return
+		}
+
+		if err := db.DeleteOnCallOverride(id, overrideID); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(SuccessResponse{Message: "Override deleted successfully"})
+	}
+}
+
+// WhoIsOnCallHandler - кто дежурит
+// @Summary Кто дежурит в момент времени
+// @Description Возвращает смену, которая включает момент at (по умолчанию - сейчас), с дежурным и его контактами
+// @Tags oncall
+// @Produce json
+// @Param id path int true "ID расписания"
+// @Param at query string false "Момент времени в RFC3339"
+// @Success 200 {object} models.OnCallShift "Смена"
+// @Failure 404 {object} ErrorResponse "Никто не дежурит"
+// @Router /oncall/schedules/{id}/oncall [get]
+func WhoIsOnCallHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if onCallService == nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "On-call service is not available"})
+			return
+		}
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid schedule ID"})
+			return
+		}
+
+		at := time.Now()
+		if value := r.URL.Query().Get("at"); value != "" {
+			if at, err = time.Parse(time.RFC3339, value); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(ErrorResponse{Error: "at must be an RFC3339 time"})
+				return
+			}
+		}
+
+		shift, err := onCallService.WhoIsOnCall(id, at)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+		if shift == nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Nobody is on call at this time"})
+			return
+		}
+
+		json.NewEncoder(w).Encode(shift)
+	}
+}
+
+// GetOnCallShiftsHandler - итоговое расписание
+// @Summary Получить смены
+// @Description Итоговое расписание с учетом слоев и замен
+// @Tags oncall
+// @Produce json
+// @Param id path int true "ID расписания"
+// @Param from query string false "Начало периода в RFC3339 (по умолчанию - сейчас)"
+// @Param to query string false "Конец периода в RFC3339 (по умолчанию - через 14 дней)"
+// @Success 200 {array} models.OnCallShift "Смены"
+// @Router /oncall/schedules/{id}/shifts [get]
+func GetOnCallShiftsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		_, shifts, status, err := onCallShifts(r, 14)
+		if err != nil {
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(shifts)
+	}
+}
+
+// OnCallICalHandler - экспорт расписания в iCal
+// @Summary Экспорт расписания в iCal
+// @Description Календарь смен для подписки в Google Calendar, Outlook и т.п.
+// @Tags oncall
+// @Produce text/calendar
+// @Param id path int true "ID расписания"
+// @Param from query string false "Начало периода в RFC3339 (по умолчанию - сейчас)"
+// @Param to query string false "Конец периода в RFC3339 (по умолчанию - через 90 дней)"
+// @Success 200 {string} string "Календарь"
+// @Router /oncall/schedules/{id}/ical [get]
+func OnCallICalHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		schedule, shifts, status, err := onCallShifts(r, 90)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=oncall-%d.ics", schedule.ID))
+		w.Write([]byte(oncall.ICal(schedule, shifts)))
+	}
+}
+
+func onCallShifts(r *http.Request, defaultDays int) (*models.OnCallSchedule, []models.OnCallShift, int, error) {
+	if onCallService == nil {
+		return nil, nil, http.StatusServiceUnavailable, fmt.Errorf("On-call service is not available")
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, nil, http.StatusBadRequest, fmt.Errorf("Invalid schedule ID")
+	}
+
+	from := time.Now()
+	if value := r.URL.Query().Get("from"); value != "" {
+		if from, err = time.Parse(time.RFC3339, value); err != nil {
+			return nil, nil, http.StatusBadRequest, fmt.Errorf("from must be an RFC3339 time")
+		}
+	}
+	to := from.AddDate(0, 0, defaultDays)
+	if value := r.URL.Query().Get("to"); value != "" {
+		if to, err = time.Parse(time.RFC3339, value); err != nil {
+			return nil, nil, http.StatusBadRequest, fmt.Errorf("to must be an RFC3339 time")
+		}
+	}
+
+	schedule, shifts, err := onCallService.Shifts(id, from, to)
+	if err != nil {
+		return nil, nil, http.StatusBadRequest, err
+	}
+	return schedule, shifts, http.StatusOK, nil
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"ping-tower/internal/database"
+	"ping-tower/internal/models"
+	"ping-tower/internal/queue"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+var queueService *queue.Service
+
+func SetQueueService(service *queue.Service) {
+	queueService = service
+}
+
+// GetQueuedNotificationsHandler - очередь доставки оповещений
+// @Summary Получить очередь доставки оповещений
+// @Description Неудачные доставки повторяются с экспоненциальной задержкой; исчерпавшие попытки получают статус dead, вытесненные более новым доставленным алертом сайта того же семейства - superseded
+// @Tags notifications
+// @Produce json
+// @Param status query string false "pending, sent, dead или superseded"
+// @Param limit query int false "Количество записей" default(50)
+// @Success 200 {array} models.QueuedNotification "Оповещения в очереди"
+// @Router /notifications/queue [get]
+func GetQueuedNotificationsHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		status := r.URL.Query().Get("status")
+		switch status {
+		case "", models.NotificationStatusPending, models.NotificationStatusSent, models.NotificationStatusDead,
+			models.NotificationStatusSuperseded:
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "status must be pending, sent, dead or superseded"})
+			return
+		}
+
+		limit := 50
+		if value, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && value > 0 && value <= 500 {
+			limit = value
+		}
+
+		notifications, err := db.GetQueuedNotifications(status, limit)
+		if err != nil {
+			log.Printf("❌ Ошибка получения очереди оповещений: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(notifications)
+	}
+}
+
+// GetQueuedNotificationHandler - оповещение в очереди
+// @Summary Получить оповещение из очереди доставки
+// @Tags notifications
+// @Produce json
+// @Param id path int true "ID оповещения"
+// @Success 200 {object} models.QueuedNotification "Оповещение"
+// @Failure 404 {object} ErrorResponse "Оповещение не найдено"
+// @Router /notifications/queue/{id} [get]
+func GetQueuedNotificationHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid notification ID"})
+			return
+		}
+
+		n, err := db.GetQueuedNotification(id)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(n)
+	}
+}
+
+// ResendNotificationHandler - повторить доставку оповещения
+// @Summary Повторить доставку оповещения
+// @Description Сразу отправляет оповещение из очереди, например после исправления настроек канала. Возвращает оповещение с результатом попытки
+// @Tags notifications
+// @Produce json
+// @Param id path int true "ID оповещения"
+// @Success 200 {object} models.QueuedNotification "Результат попытки"
+// @Failure 404 {object} ErrorResponse "Оповещение не найдено"
+// @Failure 409 {object} ErrorResponse "Оповещение уже доставлено или вытеснено более новым алертом"
+// @Router /notifications/queue/{id}/resend [post]
+func ResendNotificationHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if queueService == nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Notification queue is not available"})
+			return
+		}
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid notification ID"})
+			return
+		}
+
+		if _, err := db.GetQueuedNotification(id); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		n, err := queueService.Resend(id)
+		if err != nil {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(n)
+	}
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"ping-tower/internal/database"
+	"ping-tower/internal/snapshots"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+var snapshotService *snapshots.Service
+
+func SetSnapshotService(service *snapshots.Service) {
+	snapshotService = service
+}
+
+// GetSiteSnapshotsHandler - снимки контента сайта
+// @Summary Получить снимки контента сайта
+// @Description Снимок сохраняется при каждом изменении хэша контента (collect_content_hash) с учетом масок content_masks. Содержимое не возвращается
+// @Tags sites
+// @Produce json
+// @Param id path int true "ID сайта"
+// @Param limit query int false "Количество снимков" default(50)
+// @Success 200 {array} models.ContentSnapshot "Снимки, новые первыми"
+// @Router /sites/{id}/snapshots [get]
+func GetSiteSnapshotsHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid site ID"})
+			return
+		}
+
+		limit := 50
+		if value, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && value > 0 && value <= 500 {
+			limit = value
+		}
+
+		siteSnapshots, err := db.GetContentSnapshots(id, limit)
+		if err != nil {
+			log.Printf("❌ Ошибка получения снимков контента: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(siteSnapshots)
+	}
+}
+
+// GetSiteSnapshotHandler - снимок контента сайта
+// @Summary Получить снимок контента сайта с содержимым
+// @Tags sites
+// @Produce json
+// @Param id path int true "ID сайта"
+// @Param snapshotId path int true "ID снимка"
+// @Success 200 {object} models.ContentSnapshot "Снимок"
+// @Failure 404 {object} ErrorResponse "Снимок не найден"
+// @Router /sites/{id}/snapshots/{snapshotId} [get]
+func GetSiteSnapshotHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if snapshotService == nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Content snapshots are not available"})
+			return
+		}
+
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid site ID"})
+			return
+		}
+		snapshotID, err := strconv.Atoi(vars["snapshotId"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid snapshot ID"})
+			return
+		}
+
+		snapshot, err := snapshotService.Get(id, snapshotID)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(snapshot)
+	}
+}
+
+// GetSiteSnapshotDiffHandler - сравнение снимков контента
+// @Summary Сравнить два снимка контента сайта
+// @Description Возвращает unified diff между снимками. Без параметров сравнивается последний снимок с предыдущим, без from - снимок to с предыдущим. Длинные строки минифицированных страниц разбиваются по тегам
+// @Tags sites
+// @Produce json
+// @Produce plain
+// @Param id path int true "ID сайта"
+// @Param from query int false "ID исходного снимка"
+// @Param to query int false "ID нового снимка"
+// @Param format query string false "text - вернуть только diff как text/plain"
+// @Success 200 {object} models.ContentSnapshotDiff "Сравнение снимков"
+// @Failure 404 {object} ErrorResponse "Снимок не найден"
+// @Router /sites/{id}/snapshots/diff [get]
+func GetSiteSnapshotDiffHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if snapshotService == nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Content snapshots are not available"})
+			return
+		}
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid site ID"})
+			return
+		}
+
+		var fromID, toID int
+		for name, target := range map[string]*int{"from": &fromID, "to": &toID} {
+			value := r.URL.Query().Get(name)
+			if value == "" {
+				continue
+			}
+			if *target, err = strconv.Atoi(value); err != nil || *target <= 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid snapshot ID in " + name})
+				return
+			}
+		}
+
+		diff, err := snapshotService.Diff(id, fromID, toID)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if r.URL.Query().Get("format") == "text" {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Write([]byte(diff.Diff))
+			return
+		}
+
+		json.NewEncoder(w).Encode(diff)
+	}
+}
@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"ping-tower/internal/database"
+	"ping-tower/internal/models"
+	"ping-tower/internal/notifications"
+	"ping-tower/internal/templates"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+var templateService *templates.Service
+
+func SetTemplateService(service *templates.Service) {
+	templateService = service
+}
+
+// AlertTemplatePreviewRequest - шаблон и пример оповещения для предпросмотра
+type AlertTemplatePreviewRequest struct {
+	models.AlertTemplate
+	// ID сохраненного шаблона вместо полей шаблона
+	TemplateID int    `json:"template_id,omitempty"`
+	SiteURL    string `json:"site_url,omitempty"`
+}
+
+// AlertTemplatePreview - результат рендеринга шаблона
+type AlertTemplatePreview struct {
+	Subject     string                  `json:"subject"`
+	Body        string                  `json:"body"`
+	ContentType string                  `json:"content_type"`
+	Alert       notifications.AlertData `json:"alert"`
+}
+
+func reloadTemplates() {
+	if templateService == nil {
+		return
+	}
+	if err := templateService.Reload(); err != nil {
+		log.Printf("⚠️ Ошибка загрузки шаблонов оповещений: %v", err)
+	}
+}
+
+// checkTemplateConflict reports a template of the same channel and alert type.
+func checkTemplateConflict(db *database.DB, t *models.AlertTemplate) error {
+	existing, err := db.FindAlertTemplate(t.Channel, t.AlertType)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != t.ID {
+		return fmt.Errorf("template #%d already exists for channel %s and alert type '%s'", existing.ID, t.Channel, t.AlertType)
+	}
+	return nil
+}
+
+// GetAlertTemplatesHandler - список шаблонов оповещений
+// @Summary Получить шаблоны оповещений
+// @Tags templates
+// @Produce json
+// @Success 200 {array} models.AlertTemplate "Шаблоны"
+// @Router /alert-templates [get]
+func GetAlertTemplatesHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		list, err := db.GetAlertTemplates()
+		if err != nil {
+			log.Printf("❌ Ошибка получения шаблонов оповещений: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(list)
+	}
+}
+
+// GetAlertTemplateHandler - шаблон оповещения
+// @Summary Получить шаблон оповещения
+// @Tags templates
+// @Produce json
+// @Param id path int true "ID шаблона"
+// @Success 200 {object} models.AlertTemplate "Шаблон"
+// @Failure 404 {object} ErrorResponse "Шаблон не найден"
+// @Router /alert-templates/{id} [get]
+func GetAlertTemplateHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid template ID"})
+			return
+		}
+
+		t, err := db.GetAlertTemplate(id)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		json.NewEncoder(w).Encode(t)
+	}
+}
+
+// CreateAlertTemplateHandler - создать шаблон оповещения
+// @Summary Создать шаблон оповещения
+// @Description Шаблон заменяет стандартное сообщение канала для типа оповещения (пустой alert_type - для всех типов). Данные шаблона - AlertData с CheckResult
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param template body models.AlertTemplate true "Шаблон"
+// @Success 201 {object} models.AlertTemplate "Шаблон создан"
+// @Failure 400 {object} ErrorResponse "Неверный шаблон"
+// @Failure 409 {object} ErrorResponse "Шаблон для канала и типа уже существует"
+// @Router /alert-templates [post]
+func CreateAlertTemplateHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		t := models.AlertTemplate{Enabled: true}
+		if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON"})
+			return
+		}
+
+		if err := templates.Validate(&t); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := checkTemplateConflict(db, &t); err != nil {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := db.CreateAlertTemplate(&t); err != nil {
+			log.Printf("❌ Ошибка создания шаблона оповещения: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to create alert template"})
+			return
+		}
+		reloadTemplates()
+
+		log.Printf("✅ Создан шаблон оповещения #%d (%s, '%s')", t.ID, t.Channel, t.AlertType)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(t)
+	}
+}
+
+// UpdateAlertTemplateHandler - обновить шаблон оповещения
+// @Summary Обновить шаблон оповещения
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param id path int true "ID шаблона"
+// @Param template body models.AlertTemplate true "Новые параметры"
+// @Success 200 {object} models.AlertTemplate "Шаблон обновлен"
+// @Failure 400 {object} ErrorResponse "Неверный шаблон"
+// @Failure 404 {object} ErrorResponse "Шаблон не найден"
+// @Router /alert-templates/{id} [put]
+func UpdateAlertTemplateHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid template ID"})
+			return
+		}
+
+		t, err := db.GetAlertTemplate(id)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(t); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON"})
+			return
+		}
+		t.ID = id
+
+		if err := templates.Validate(t); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := checkTemplateConflict(db, t); err != nil {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := db.UpdateAlertTemplate(t); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+		reloadTemplates()
+
+		log.Printf("✅ Обновлен шаблон оповещения #%d", t.ID)
+		json.NewEncoder(w).Encode(t)
+	}
+}
+
+// DeleteAlertTemplateHandler - удалить шаблон оповещения
+// @Summary Удалить шаблон оповещения
+// @Description Канал возвращается к стандартному сообщению
+// @Tags templates
+// @Produce json
+// @Param id path int true "ID шаблона"
+// @Success 200 {object} SuccessResponse "Шаблон удален"
+// @Failure 404 {object} ErrorResponse "Шаблон не найден"
+// @Router /alert-templates/{id} [delete]
+func DeleteAlertTemplateHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid template ID"})
+			return
+		}
+
+		if err := db.DeleteAlertTemplate(id); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+		reloadTemplates()
+
+		json.NewEncoder(w).Encode(SuccessResponse{Message: "Alert template deleted successfully"})
+	}
+}
+
+// PreviewAlertTemplateHandler - предпросмотр шаблона
+// @Summary Предпросмотр шаблона оповещения
+// @Description Рендерит переданный или сохраненный (template_id) шаблон на примере оповещения его типа
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param preview body AlertTemplatePreviewRequest true "Шаблон"
+// @Success 200 {object} AlertTemplatePreview "Результат"
+// @Failure 400 {object} ErrorResponse "Ошибка шаблона"
+// @Router /alert-templates/preview [post]
+func PreviewAlertTemplateHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var request AlertTemplatePreviewRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON"})
+			return
+		}
+
+		t := &request.AlertTemplate
+		if request.TemplateID > 0 {
+			stored, err := db.GetAlertTemplate(request.TemplateID)
+			if err != nil {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+				return
+			}
+			t = stored
+		}
+
+		if err := templates.Validate(t); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		alert := notifications.SampleAlert(t.AlertType, request.SiteURL)
+		subject, body, err := notifications.RenderTemplate(t, alert)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		contentType := "text/plain"
+		if t.Format == models.TemplateFormatHTML {
+			contentType = "text/html"
+		}
+
+		json.NewEncoder(w).Encode(AlertTemplatePreview{
+			Subject:     subject,
+			Body:        body,
+			ContentType: contentType,
+			Alert:       alert,
+		})
+	}
+}
@@ -260,6 +260,11 @@ const webTemplate = `<!DOCTYPE html>
             color: #e74c3c;
         }
 
+        .site-status.pending {
+            background: rgba(243, 156, 18, 0.2);
+            color: #f39c12;
+        }
+
         .site-details {
             display: grid;
             grid-template-columns: repeat(auto-fit, minmax(150px, 1fr));
@@ -751,6 +756,9 @@ const webTemplate = `<!DOCTYPE html>
                 <a href="/metrics" class="nav-link metrics">
                     <i class="fas fa-chart-bar"></i> Метрики
                 </a>
+                <a href="/incidents" class="nav-link">
+                    <i class="fas fa-fire"></i> Инциденты
+                </a>
                 <a href="/alerts" class="nav-link">
                     <i class="fas fa-bell"></i> Оповещения
                 </a>
@@ -876,6 +884,147 @@ const webTemplate = `<!DOCTYPE html>
                     </div>
                 </div>
 
+                <!-- HTTP Request Settings -->
+                <div class="config-section">
+                    <h4><i class="fas fa-paper-plane"></i> HTTP запрос</h4>
+                    <div class="form-row">
+                        <div class="form-field">
+                            <label class="form-label">Метод</label>
+                            <select class="form-control" id="method" name="method">
+                                <option value="GET">GET</option>
+                                <option value="HEAD">HEAD</option>
+                                <option value="POST">POST</option>
+                                <option value="PUT">PUT</option>
+                                <option value="PATCH">PATCH</option>
+                                <option value="DELETE">DELETE</option>
+                                <option value="OPTIONS">OPTIONS</option>
+                            </select>
+                        </div>
+                        <div class="form-field">
+                            <label class="form-label">Content-Type тела</label>
+                            <input type="text" class="form-control" id="requestContentType" name="requestContentType" placeholder="application/json">
+                        </div>
+                    </div>
+
+                    <div class="form-field full-width">
+                        <label class="form-label">Тело запроса</label>
+                        <textarea class="form-control" id="requestBody" name="requestBody" rows="3" placeholder='{"ping": true}'></textarea>
+                    </div>
+
+                    <div class="form-field full-width">
+                        <label class="form-label">Допустимые коды ответа (заменяют ожидаемый статус)</label>
+                        <input type="text" class="form-control" id="expectedStatusCodes" name="expectedStatusCodes" placeholder="200-299,301">
+                    </div>
+
+                    <div class="form-field full-width">
+                        <label class="form-label">Проверки заголовков ответа (по одной на строку)</label>
+                        <textarea class="form-control" id="headerAssertions" name="headerAssertions" rows="3" placeholder="Content-Type contains application/json&#10;X-Cache exists"></textarea>
+                    </div>
+                </div>
+
+                <!-- Check Type Settings -->
+                <div class="config-section">
+                    <h4><i class="fas fa-plug"></i> Тип проверки</h4>
+                    <div class="form-row">
+                        <div class="form-field">
+                            <label class="form-label">Тип</label>
+                            <select class="form-control" id="checkType" name="checkType">
+                                <option value="http">HTTP(S)</option>
+                                <option value="tcp">TCP порт</option>
+                                <option value="icmp">ICMP ping</option>
+                                <option value="dns">DNS запись</option>
+                                <option value="tls">TLS сертификат (host:port)</option>
+                                <option value="multistep">Многошаговый HTTP сценарий</option>
+                            </select>
+                        </div>
+                        <div class="form-field">
+                            <label class="form-label">Адрес host:port (пусто - из URL)</label>
+                            <input type="text" class="form-control" id="target" name="target" placeholder="db.internal:5432">
+                        </div>
+                    </div>
+
+                    <div class="form-row">
+                        <div class="form-field">
+                            <label class="form-label">Таймаут соединения (сек)</label>
+                            <input type="number" class="form-control" id="connectTimeout" name="connectTimeout" min="1" max="300">
+                        </div>
+                        <div class="form-field">
+                            <label class="form-label">Отправить после соединения</label>
+                            <input type="text" class="form-control" id="tcpSend" name="tcpSend" placeholder="PING\r\n">
+                        </div>
+                    </div>
+
+                    <div class="form-field full-width">
+                        <label class="form-label">Ожидаемый баннер/ответ</label>
+                        <input type="text" class="form-control" id="tcpExpect" name="tcpExpect" placeholder="+PONG">
+                    </div>
+
+                    <div class="form-row">
+                        <div class="form-field">
+                            <label class="form-label">ICMP пакетов</label>
+                            <input type="number" class="form-control" id="pingCount" name="pingCount" min="1" max="20">
+                        </div>
+                        <div class="form-field">
+                            <label class="form-label">Интервал между пакетами (мс)</label>
+                            <input type="number" class="form-control" id="pingInterval" name="pingInterval" min="200" max="10000">
+                        </div>
+                    </div>
+
+                    <div class="form-field full-width">
+                        <label class="form-label">Допустимая потеря пакетов, % (0 - только полная потеря)</label>
+                        <input type="number" class="form-control" id="maxPacketLoss" name="maxPacketLoss" min="0" max="100" step="0.1">
+                    </div>
+
+                    <div class="form-row">
+                        <div class="form-field">
+                            <label class="form-label">Тип DNS записи</label>
+                            <select class="form-control" id="dnsRecordType" name="dnsRecordType">
+                                <option value="A">A</option>
+                                <option value="AAAA">AAAA</option>
+                                <option value="CNAME">CNAME</option>
+                                <option value="MX">MX</option>
+                                <option value="TXT">TXT</option>
+                                <option value="NS">NS</option>
+                            </select>
+                        </div>
+                        <div class="form-field">
+                            <label class="form-label">DNS резолвер (пусто - системный)</label>
+                            <input type="text" class="form-control" id="dnsResolver" name="dnsResolver" placeholder="1.1.1.1:53">
+                        </div>
+                    </div>
+
+                    <div class="form-field full-width">
+                        <label class="form-label">Ожидаемые ответы (по одному на строку)</label>
+                        <textarea class="form-control" id="dnsExpected" name="dnsExpected" rows="3" placeholder="93.184.216.34"></textarea>
+                    </div>
+
+                    <div class="form-row">
+                        <div class="form-field">
+                            <label class="form-label">STARTTLS</label>
+                            <select class="form-control" id="tlsStartTLS" name="tlsStartTLS">
+                                <option value="">Нет (TLS сразу)</option>
+                                <option value="smtp">SMTP</option>
+                                <option value="imap">IMAP</option>
+                                <option value="postgres">PostgreSQL</option>
+                            </select>
+                        </div>
+                        <div class="form-field">
+                            <label class="form-label">Имя хоста для SNI (пусто - из адреса)</label>
+                            <input type="text" class="form-control" id="tlsServerName" name="tlsServerName" placeholder="mail.example.com">
+                        </div>
+                    </div>
+
+                    <div class="checkbox-field">
+                        <input type="checkbox" id="tlsAllowUntrusted" name="tlsAllowUntrusted">
+                        <label for="tlsAllowUntrusted">Допускать сертификаты частного CA</label>
+                    </div>
+
+                    <div class="form-field full-width">
+                        <label class="form-label">Шаги сценария (JSON массив)</label>
+                        <textarea class="form-control" id="steps" name="steps" rows="6" placeholder='[{"name": "login", "method": "POST", "url": "/api/login", "body": "{}", "captures": [{"name": "token", "source": "json", "expression": "$.token"}], "assertions": [{"source": "status", "operator": "==", "value": "200"}]}]'></textarea>
+                    </div>
+                </div>
+
                 <!-- Metric Collection Settings -->
                 <div class="config-section">
                     <h4><i class="fas fa-chart-line"></i> Сбор метрик</h4>
@@ -917,6 +1066,11 @@ const webTemplate = `<!DOCTYPE html>
                             <label for="collectHeaders">HTTP заголовки</label>
                         </div>
                     </div>
+
+                    <div class="form-field full-width">
+                        <label class="form-label">Маски контента: скрываются из хэша и снимков (CSS селектор или regex:шаблон, по одной на строку)</label>
+                        <textarea class="form-control" id="contentMasks" name="contentMasks" rows="3" placeholder="#clock&#10;meta[name=csrf-token]&#10;regex:nonce=&quot;[^&quot;]+&quot;"></textarea>
+                    </div>
                 </div>
 
                 <!-- Display Settings -->
@@ -976,11 +1130,60 @@ const webTemplate = `<!DOCTYPE html>
                             <input type="text" class="form-control" id="avoidKeywords" name="avoidKeywords" placeholder="error, 404">
                         </div>
                     </div>
+
+                    <div class="form-field full-width">
+                        <label class="form-label">JSON проверки ответа (по одной на строку)</label>
+                        <textarea class="form-control" id="jsonAssertions" name="jsonAssertions" rows="3" placeholder='$.status == "ok"&#10;$.db.latency_ms < 200&#10;len($.queue) < 1000'></textarea>
+                    </div>
                     
+                    <div class="form-row">
+                        <div class="form-field">
+                            <label class="form-label">Неудач подряд до DOWN</label>
+                            <input type="number" class="form-control" id="failThreshold" name="failThreshold" min="1" max="20">
+                        </div>
+                        <div class="form-field">
+                            <label class="form-label">Успехов подряд до UP</label>
+                            <input type="number" class="form-control" id="recoverThreshold" name="recoverThreshold" min="1" max="20">
+                        </div>
+                    </div>
+
+                    <div class="form-row">
+                        <div class="form-field">
+                            <div class="checkbox-field">
+                                <input type="checkbox" id="retryOnFailure" name="retryOnFailure">
+                                <label for="retryOnFailure">Повторить неудачную проверку</label>
+                            </div>
+                        </div>
+                        <div class="form-field">
+                            <label class="form-label">Задержка повтора (сек)</label>
+                            <input type="number" class="form-control" id="retryDelay" name="retryDelay" min="1" max="30">
+                        </div>
+                    </div>
+
+                    <div class="form-field full-width">
+                        <label class="form-label">Теги (через запятую, для окон обслуживания)</label>
+                        <input type="text" class="form-control" id="siteTags" name="siteTags" placeholder="production, database">
+                    </div>
+
+                    <div class="form-field">
+                        <label class="form-label">ID политики эскалации (0 - из глобальных настроек)</label>
+                        <input type="number" class="form-control" id="escalationPolicyId" name="escalationPolicyId" min="0">
+                    </div>
+
+                    <div class="form-field full-width">
+                        <label class="form-label">Конфигурации оповещений (без выбора действует global)</label>
+                        <div id="siteAlertConfigs"></div>
+                    </div>
+
                     <div class="form-field">
                         <label class="form-label">SSL предупреждение за (дней)</label>
                         <input type="number" class="form-control" id="sslAlertDays" name="sslAlertDays" min="1" max="365">
                     </div>
+
+                    <div class="form-field">
+                        <label class="form-label">Пороги SSL оповещений (дней)</label>
+                        <input type="text" class="form-control" id="sslAlertThresholds" name="sslAlertThresholds" placeholder="30,14,7,1">
+                    </div>
                 </div>
 
                 <!-- Notification Settings -->
@@ -1021,6 +1224,7 @@ const webTemplate = `<!DOCTYPE html>
     <script>
         let statusChart = null;
         let eventSource = null;
+        let currentSiteConfig = {};
         
         function connectSSE() {
             if (eventSource) {
@@ -1056,8 +1260,16 @@ const webTemplate = `<!DOCTYPE html>
                     console.log('Сайт проверен:', message.data);
                     loadSites();
                     loadDashboardStats();
-                    showNotification('Проверен сайт: ' + message.data.url + ' - ' + message.data.status.toUpperCase(), 
-                        message.data.status === 'up' ? 'success' : 'error');
+                    if (message.data.maintenance) {
+                        showNotification('Проверен сайт: ' + message.data.url + ' - ' + (message.data.raw_status || message.data.status).toUpperCase() +
+                            ' (обслуживание)', 'info');
+                    } else if (message.data.pending) {
+                        showNotification('Проверен сайт: ' + message.data.url + ' - ' + message.data.raw_status.toUpperCase() +
+                            ' (ожидает подтверждения)', 'warning');
+                    } else {
+                        showNotification('Проверен сайт: ' + message.data.url + ' - ' + message.data.status.toUpperCase(), 
+                            message.data.status === 'up' ? 'success' : 'error');
+                    }
                     break;
                 case 'site_added':
                     console.log('Сайт добавлен:', message.data);
@@ -1071,6 +1283,11 @@ const webTemplate = `<!DOCTYPE html>
                     loadDashboardStats();
                     showNotification('Удален сайт: ' + message.data.url, 'warning');
                     break;
+                case 'incident_updated':
+                    if (message.data.status === 'open' && message.data.check_count === 1) {
+                        showNotification('Открыт инцидент #' + message.data.id + ': ' + message.data.title, 'error');
+                    }
+                    break;
                 case 'check_started':
                     console.log('Проверка запущена');
                     showNotification('Проверка всех сайтов запущена', 'info');
@@ -1300,26 +1517,100 @@ const webTemplate = `<!DOCTYPE html>
             }
         }
 
+        function loadSiteAlertConfigs(siteId) {
+            const container = document.getElementById('siteAlertConfigs');
+            container.innerHTML = '';
+
+            Promise.all([
+                fetch('/api/alerts/configs').then(response => response.json()),
+                fetch('/api/sites/' + siteId + '/alert-configs').then(response => response.json())
+            ]).then(([all, assigned]) => {
+                const selected = (assigned || []).map(config => config.name);
+                (all || []).forEach(config => {
+                    const field = document.createElement('div');
+                    field.className = 'checkbox-field';
+
+                    const input = document.createElement('input');
+                    input.type = 'checkbox';
+                    input.id = 'siteAlertConfig-' + config.id;
+                    input.value = config.name;
+                    input.checked = selected.indexOf(config.name) !== -1;
+
+                    const label = document.createElement('label');
+                    label.htmlFor = input.id;
+                    label.textContent = config.name + (config.enabled ? '' : ' (отключена)');
+
+                    field.appendChild(input);
+                    field.appendChild(label);
+                    container.appendChild(field);
+                });
+            }).catch(error => {
+                console.error('Ошибка загрузки конфигураций оповещений:', error);
+            });
+        }
+
+        function saveSiteAlertConfigs(siteId) {
+            const names = Array.from(document.querySelectorAll('#siteAlertConfigs input:checked')).map(input => input.value);
+            return fetch('/api/sites/' + siteId + '/alert-configs', {
+                method: 'PUT',
+                headers: {
+                    'Content-Type': 'application/json'
+                },
+                body: JSON.stringify({ alert_configs: names })
+            }).then(response => response.json());
+        }
+
         function openConfigModal(siteId) {
             document.getElementById('configSiteId').value = siteId;
+            loadSiteAlertConfigs(siteId);
             
             fetch('/api/sites/' + siteId + '/config')
                 .then(response => response.json())
                 .then(config => {
+                    currentSiteConfig = config;
                     document.getElementById('checkInterval').value = config.check_interval || 30;
                     document.getElementById('timeout').value = config.timeout || 30;
                     document.getElementById('expectedStatus').value = config.expected_status || 200;
+                    document.getElementById('method').value = config.method || 'GET';
+                    document.getElementById('requestContentType').value = config.request_content_type || '';
+                    document.getElementById('requestBody').value = config.request_body || '';
+                    document.getElementById('expectedStatusCodes').value = config.expected_status_codes || '';
+                    document.getElementById('headerAssertions').value = config.header_assertions || '';
                     document.getElementById('maxRedirects').value = config.max_redirects || 10;
                     document.getElementById('userAgent').value = config.user_agent || 'Site-Monitor/1.0';
                     document.getElementById('checkKeywords').value = config.check_keywords || '';
                     document.getElementById('avoidKeywords').value = config.avoid_keywords || '';
+                    document.getElementById('jsonAssertions').value = config.json_assertions || '';
+                    document.getElementById('failThreshold').value = config.fail_threshold || 1;
+                    document.getElementById('recoverThreshold').value = config.recover_threshold || 1;
+                    document.getElementById('retryOnFailure').checked = config.retry_on_failure || false;
+                    document.getElementById('retryDelay').value = config.retry_delay || 5;
+                    document.getElementById('siteTags').value = (config.tags || []).join(', ');
+                    document.getElementById('escalationPolicyId').value = config.escalation_policy_id || 0;
                     document.getElementById('sslAlertDays').value = config.ssl_alert_days || 30;
+                    document.getElementById('sslAlertThresholds').value = config.ssl_alert_thresholds || '';
+                    document.getElementById('checkType').value = config.check_type || 'http';
+                    document.getElementById('target').value = config.target || '';
+                    document.getElementById('connectTimeout').value = config.connect_timeout || 10;
+                    document.getElementById('tcpSend').value = config.tcp_send || '';
+                    document.getElementById('tcpExpect').value = config.tcp_expect || '';
+                    document.getElementById('pingCount').value = config.ping_count || 4;
+                    document.getElementById('pingInterval').value = config.ping_interval || 1000;
+                    document.getElementById('maxPacketLoss').value = config.max_packet_loss || 0;
+                    document.getElementById('dnsRecordType').value = config.dns_record_type || 'A';
+                    document.getElementById('dnsResolver').value = config.dns_resolver || '';
+                    document.getElementById('dnsExpected').value = config.dns_expected || '';
+                    document.getElementById('tlsStartTLS').value = config.tls_starttls || '';
+                    document.getElementById('tlsServerName').value = config.tls_server_name || '';
+                    document.getElementById('tlsAllowUntrusted').checked = config.tls_allow_untrusted === true;
+                    document.getElementById('steps').value = (config.steps && config.steps.length) ? JSON.stringify(config.steps, null, 2) : '';
                     
                     document.getElementById('collectDNSTime').checked = config.collect_dns_time === true;
                     document.getElementById('collectConnectTime').checked = config.collect_connect_time === true;
                     document.getElementById('collectTLSTime').checked = config.collect_tls_time === true;
                     document.getElementById('collectTTFB').checked = config.collect_ttfb === true;
                     document.getElementById('collectContentHash').checked = config.collect_content_hash === true;
+                    document.getElementById('contentMasks').value = config.content_masks || '';
                     document.getElementById('collectRedirects').checked = config.collect_redirects === true;
                     document.getElementById('collectSSLDetails').checked = config.collect_ssl_details !== false;
                     document.getElementById('collectServerInfo').checked = config.collect_server_info === true;
@@ -1377,10 +1668,21 @@ const webTemplate = `<!DOCTYPE html>
             if (sslIndicator) {
                 detailsHtml += '<div class="detail-item">' + sslIndicator + '</div>';
             }
+
+            let pendingHtml = '';
+            if (site.pending_status) {
+                const counter = site.pending_status === 'up' ?
+                    site.consecutive_successes + '/' + (config.recover_threshold || 1) :
+                    site.consecutive_failures + '/' + (config.fail_threshold || 1);
+                pendingHtml = '<div class="site-status pending" title="Ожидает подтверждения">' +
+                    '<i class="fas fa-hourglass-half"></i>' + site.pending_status.toUpperCase() + '? ' + counter +
+                '</div>';
+            }
             
             return '<div class="site-card ' + site.status + '">' +
                 '<div class="site-header">' +
                     '<div class="site-url">' + site.url + '</div>' +
+                    pendingHtml +
                     '<div class="site-status ' + site.status + '">' +
                         '<i class="fas fa-' + (site.status === 'up' ? 'check' : 'times') + '"></i>' +
                         site.status.toUpperCase() +
@@ -1624,18 +1926,43 @@ const webTemplate = `<!DOCTYPE html>
         document.getElementById('configForm').addEventListener('submit', function(e) {
             e.preventDefault();
             const siteId = document.getElementById('configSiteId').value;
+
+            let steps = [];
+            const stepsText = document.getElementById('steps').value.trim();
+            if (stepsText) {
+                try {
+                    steps = JSON.parse(stepsText);
+                } catch (err) {
+                    showNotification('Шаги сценария: некорректный JSON', 'error');
+                    return;
+                }
+            }
             
-            const config = {
+            // Поля без элементов формы сохраняются из загруженной конфигурации
+            const config = Object.assign({}, currentSiteConfig, {
                 check_interval: parseInt(document.getElementById('checkInterval').value),
                 timeout: parseInt(document.getElementById('timeout').value),
                 expected_status: parseInt(document.getElementById('expectedStatus').value),
+                method: document.getElementById('method').value,
+                request_content_type: document.getElementById('requestContentType').value,
+                request_body: document.getElementById('requestBody').value,
+                expected_status_codes: document.getElementById('expectedStatusCodes').value.trim(),
+                header_assertions: document.getElementById('headerAssertions').value,
                 follow_redirects: document.getElementById('followRedirects').checked,
                 max_redirects: parseInt(document.getElementById('maxRedirects').value),
                 check_ssl: document.getElementById('checkSSL').checked,
                 ssl_alert_days: parseInt(document.getElementById('sslAlertDays').value),
+                ssl_alert_thresholds: document.getElementById('sslAlertThresholds').value.trim(),
                 check_keywords: document.getElementById('checkKeywords').value,
                 avoid_keywords: document.getElementById('avoidKeywords').value,
-                headers: {},
+                json_assertions: document.getElementById('jsonAssertions').value,
+                fail_threshold: parseInt(document.getElementById('failThreshold').value) || 1,
+                recover_threshold: parseInt(document.getElementById('recoverThreshold').value) || 1,
+                retry_on_failure: document.getElementById('retryOnFailure').checked,
+                retry_delay: parseInt(document.getElementById('retryDelay').value) || 5,
+                tags: document.getElementById('siteTags').value.split(',').map(function(tag) { return tag.trim(); }).filter(Boolean),
+                escalation_policy_id: parseInt(document.getElementById('escalationPolicyId').value) || 0,
+                headers: currentSiteConfig.headers || {},
                 user_agent: document.getElementById('userAgent').value,
                 enabled: document.getElementById('enabled').checked,
                 notify_on_down: document.getElementById('notifyOnDown').checked,
@@ -1645,6 +1972,7 @@ const webTemplate = `<!DOCTYPE html>
                 collect_tls_time: document.getElementById('collectTLSTime').checked,
                 collect_ttfb: document.getElementById('collectTTFB').checked,
                 collect_content_hash: document.getElementById('collectContentHash').checked,
+                content_masks: document.getElementById('contentMasks').value,
                 collect_redirects: document.getElementById('collectRedirects').checked,
                 collect_ssl_details: document.getElementById('collectSSLDetails').checked,
                 collect_server_info: document.getElementById('collectServerInfo').checked,
@@ -1656,8 +1984,23 @@ const webTemplate = `<!DOCTYPE html>
                 show_server_info: document.getElementById('showServerInfo').checked,
                 show_performance: document.getElementById('showPerformance').checked,
                 show_redirect_info: document.getElementById('showRedirectInfo').checked,
-                show_content_info: document.getElementById('showContentInfo').checked
-            };
+                show_content_info: document.getElementById('showContentInfo').checked,
+                check_type: document.getElementById('checkType').value,
+                target: document.getElementById('target').value,
+                connect_timeout: parseInt(document.getElementById('connectTimeout').value),
+                tcp_send: document.getElementById('tcpSend').value,
+                tcp_expect: document.getElementById('tcpExpect').value,
+                ping_count: parseInt(document.getElementById('pingCount').value),
+                ping_interval: parseInt(document.getElementById('pingInterval').value),
+                max_packet_loss: parseFloat(document.getElementById('maxPacketLoss').value) || 0,
+                dns_record_type: document.getElementById('dnsRecordType').value,
+                dns_resolver: document.getElementById('dnsResolver').value,
+                dns_expected: document.getElementById('dnsExpected').value,
+                tls_starttls: document.getElementById('tlsStartTLS').value,
+                tls_server_name: document.getElementById('tlsServerName').value,
+                tls_allow_untrusted: document.getElementById('tlsAllowUntrusted').checked,
+                steps: steps
+            });
             
             fetch('/api/sites/' + siteId + '/config', {
                 method: 'PUT',
@@ -1667,6 +2010,12 @@ const webTemplate = `<!DOCTYPE html>
                 body: JSON.stringify(config)
             })
             .then(response => response.json())
+            .then(data => {
+                if (data.status !== 'ok') {
+                    return data;
+                }
+                return saveSiteAlertConfigs(siteId).then(result => result.error ? result : data);
+            })
             .then(data => {
                 if (data.status === 'ok') {
                     showNotification('Настройки сохранены успешно', 'success');
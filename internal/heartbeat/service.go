@@ -0,0 +1,255 @@
+package heartbeat
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"ping-tower/internal/database"
+	"ping-tower/internal/models"
+	"ping-tower/internal/notifications"
+	"ping-tower/internal/scheduler"
+)
+
+// MaxLogSize limits the log excerpt stored with a ping. The tail of the log
+// is kept because that is where jobs usually report their errors.
+const MaxLogSize = 10 << 10
+
+// OnStateChange is called after a heartbeat changes its state, e.g. to push
+// the update to the web interface.
+var OnStateChange func(hb models.Heartbeat)
+
+type Service struct {
+	db           *database.DB
+	alertManager *notifications.AlertManager
+}
+
+func NewService(db *database.DB) *Service {
+	return &Service{
+		db:           db,
+		alertManager: nil, // Will be set externally
+	}
+}
+
+func (s *Service) SetAlertManager(alertManager *notifications.AlertManager) {
+	s.alertManager = alertManager
+}
+
+// GenerateToken returns a random token for the push URL.
+func GenerateToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Validate checks the expected cadence of the heartbeat.
+func Validate(hb *models.Heartbeat) error {
+	if strings.TrimSpace(hb.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if hb.GraceSeconds < 0 {
+		return fmt.Errorf("grace_seconds must not be negative")
+	}
+	if hb.CronSchedule != "" {
+		if _, err := scheduler.ParseCronExpression(hb.CronSchedule); err != nil {
+			return fmt.Errorf("invalid cron_schedule: %v", err)
+		}
+		return nil
+	}
+	if hb.PeriodSeconds < 60 {
+		return fmt.Errorf("period_seconds must be at least 60 or cron_schedule must be set")
+	}
+	return nil
+}
+
+// NextDue returns the deadline for the ping following one received at from:
+// the next cron run (or from + period) plus the grace time.
+func NextDue(hb *models.Heartbeat, from time.Time) *time.Time {
+	grace := time.Duration(hb.GraceSeconds) * time.Second
+
+	var due time.Time
+	if hb.CronSchedule != "" {
+		schedule, err := scheduler.ParseCronExpression(hb.CronSchedule)
+		if err != nil {
+			return nil
+		}
+		due = schedule.Next(from).Add(grace)
+	} else {
+		if hb.PeriodSeconds <= 0 {
+			return nil
+		}
+		due = from.Add(time.Duration(hb.PeriodSeconds)*time.Second + grace)
+	}
+
+	return &due
+}
+
+// RecordPing applies a start, success or fail ping to the heartbeat with the
+// given token and stores the attached log excerpt.
+func (s *Service) RecordPing(token, kind, logText, remoteAddr string) (*models.Heartbeat, error) {
+	switch kind {
+	case models.HeartbeatPingSuccess, models.HeartbeatPingStart, models.HeartbeatPingFail:
+	default:
+		return nil, fmt.Errorf("unsupported ping kind: %s", kind)
+	}
+
+	hb, err := s.db.GetHeartbeatByToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	ping := models.HeartbeatPing{
+		HeartbeatID: hb.ID,
+		Kind:        kind,
+		Log:         TrimLog(logText),
+		RemoteAddr:  remoteAddr,
+	}
+
+	previousStatus := hb.Status
+
+	switch kind {
+	case models.HeartbeatPingStart:
+		hb.LastStartAt = &now
+		if hb.Status != models.HeartbeatStatusDown {
+			hb.Status = models.HeartbeatStatusRunning
+		}
+		// The run has to finish within the grace time.
+		if hb.GraceSeconds > 0 {
+			due := now.Add(time.Duration(hb.GraceSeconds) * time.Second)
+			hb.NextDueAt = &due
+		}
+	case models.HeartbeatPingSuccess, models.HeartbeatPingFail:
+		if hb.LastStartAt != nil && (hb.LastPingAt == nil || hb.LastStartAt.After(*hb.LastPingAt)) {
+			ping.Duration = now.Sub(*hb.LastStartAt).Milliseconds()
+			hb.LastDuration = ping.Duration
+		}
+		hb.LastPingAt = &now
+		hb.LastLog = ping.Log
+		hb.NextDueAt = NextDue(hb, now)
+		if kind == models.HeartbeatPingSuccess {
+			hb.Status = models.HeartbeatStatusUp
+		} else {
+			hb.Status = models.HeartbeatStatusDown
+		}
+	}
+
+	if !hb.Enabled {
+		hb.Status = previousStatus
+	}
+
+	if err := s.db.AddHeartbeatPing(&ping); err != nil {
+		log.Printf("⚠️ Ошибка сохранения heartbeat пинга %s: %v", hb.Name, err)
+	}
+	if err := s.db.UpdateHeartbeatState(hb); err != nil {
+		return nil, err
+	}
+
+	log.Printf("💓 Heartbeat '%s': %s (статус: %s)", hb.Name, kind, hb.Status)
+
+	if hb.Enabled {
+		switch {
+		case kind == models.HeartbeatPingFail:
+			errText := "Job reported failure"
+			if ping.Log != "" {
+				errText += ":\n" + ping.Log
+			}
+			s.sendAlert(hb, "heartbeat_failed", errText)
+		case kind == models.HeartbeatPingSuccess && previousStatus == models.HeartbeatStatusDown:
+			s.sendAlert(hb, "heartbeat_recovered", "")
+		}
+	}
+
+	if hb.Status != previousStatus && OnStateChange != nil {
+		OnStateChange(*hb)
+	}
+
+	return hb, nil
+}
+
+// CheckOverdue marks heartbeats whose deadline has passed as down and alerts.
+func (s *Service) CheckOverdue() error {
+	now := time.Now()
+
+	heartbeats, err := s.db.GetOverdueHeartbeats(now)
+	if err != nil {
+		return fmt.Errorf("failed to load overdue heartbeats: %w", err)
+	}
+
+	for i := range heartbeats {
+		hb := &heartbeats[i]
+
+		errText := fmt.Sprintf("No ping received (expected by %s)", hb.NextDueAt.Format("2006-01-02 15:04:05"))
+		if hb.Status == models.HeartbeatStatusRunning && hb.LastStartAt != nil {
+			errText = fmt.Sprintf("Job started at %s did not finish in time", hb.LastStartAt.Format("2006-01-02 15:04:05"))
+		} else if hb.LastPingAt != nil {
+			errText += fmt.Sprintf(", last ping at %s", hb.LastPingAt.Format("2006-01-02 15:04:05"))
+		}
+
+		hb.Status = models.HeartbeatStatusDown
+		if err := s.db.UpdateHeartbeatState(hb); err != nil {
+			log.Printf("❌ Ошибка обновления heartbeat '%s': %v", hb.Name, err)
+			continue
+		}
+
+		log.Printf("💔 Heartbeat '%s' пропущен: %s", hb.Name, errText)
+		s.sendAlert(hb, "heartbeat_missed", errText)
+
+		if OnStateChange != nil {
+			OnStateChange(*hb)
+		}
+	}
+
+	return nil
+}
+
+// CreateJob returns a scheduler job that looks for missed heartbeats.
+func (s *Service) CreateJob() func() error {
+	return func() error {
+		return s.CheckOverdue()
+	}
+}
+
+func (s *Service) sendAlert(hb *models.Heartbeat, alertType, errText string) {
+	if s.alertManager == nil {
+		return
+	}
+
+	status := "down"
+	if alertType == "heartbeat_recovered" {
+		status = "up"
+	}
+
+	result := notifications.CheckResult{
+		Status:       status,
+		ResponseTime: hb.LastDuration,
+		Error:        errText,
+		Headers:      map[string]string{},
+		Keywords:     []string{},
+		Cookies:      []string{},
+	}
+
+	if err := s.alertManager.SendAlert(0, "heartbeat://"+hb.Name, result, alertType); err != nil {
+		log.Printf("⚠️ Ошибка отправки оповещения для heartbeat '%s': %v", hb.Name, err)
+	}
+}
+
+// TrimLog keeps the last MaxLogSize bytes of the log on a UTF-8 boundary.
+func TrimLog(text string) string {
+	text = strings.TrimSpace(text)
+	if len(text) <= MaxLogSize {
+		return text
+	}
+
+	text = text[len(text)-MaxLogSize:]
+	for len(text) > 0 && !utf8.RuneStart(text[0]) {
+		text = text[1:]
+	}
+	return "..." + text
+}
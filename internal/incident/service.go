@@ -0,0 +1,267 @@
+package incident
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"ping-tower/internal/database"
+	"ping-tower/internal/models"
+	"ping-tower/internal/notifications"
+)
+
+// alertWindow is how long after the resolution alert deliveries are still
+// attached to the incident, so the recovery alert shows up on its timeline.
+const alertWindow = 15 * time.Minute
+
+// OnChange is called after an incident was opened, updated or resolved,
+// e.g. to push the update to the web interface.
+var OnChange func(inc models.Incident)
+
+// Check is the part of a check result the incident timeline keeps.
+type Check struct {
+	Status       string
+	RawStatus    string
+	StatusCode   int
+	ResponseTime int64
+	Error        string
+	Maintenance  bool
+}
+
+type Service struct {
+	db *database.DB
+}
+
+func NewService(db *database.DB) *Service {
+	return &Service{db: db}
+}
+
+// RecordCheck opens an incident on a confirmed outage, adds every following
+// check to its timeline and resolves it once the site is confirmed up.
+func (s *Service) RecordCheck(siteID int, siteURL string, check Check) {
+	active, err := s.db.GetActiveIncident(siteID)
+	if err != nil {
+		log.Printf("❌ Ошибка получения инцидента для %s: %v", siteURL, err)
+		return
+	}
+
+	now := time.Now()
+	rawStatus := check.RawStatus
+	if rawStatus == "" {
+		rawStatus = check.Status
+	}
+	failed := rawStatus != "up"
+
+	if active == nil {
+		// Падение во время обслуживания ожидаемо и инцидент не открывает
+		if check.Status != "down" || check.Maintenance {
+			return
+		}
+		s.open(siteID, siteURL, check, now)
+		return
+	}
+
+	if err := s.db.RecordIncidentCheck(active.ID, failed, check.Error, now); err != nil {
+		log.Printf("❌ Ошибка обновления инцидента #%d: %v", active.ID, err)
+		return
+	}
+	active.CheckCount++
+	active.LastCheckAt = &now
+	if failed {
+		active.FailedChecks++
+		active.LastError = check.Error
+	}
+
+	message := "Check passed"
+	if failed {
+		message = check.Error
+	}
+	s.addEvent(&models.IncidentEvent{
+		IncidentID:   active.ID,
+		Type:         models.IncidentEventCheck,
+		Message:      message,
+		Status:       rawStatus,
+		StatusCode:   check.StatusCode,
+		ResponseTime: check.ResponseTime,
+	})
+
+	if check.Status == "up" {
+		s.resolve(active, "", fmt.Sprintf("Site recovered after %s", now.Sub(active.StartedAt).Round(time.Second)), now)
+		return
+	}
+
+	notifyChange(*active)
+}
+
+func (s *Service) open(siteID int, siteURL string, check Check, now time.Time) {
+	inc := &models.Incident{
+		SiteID:       siteID,
+		SiteURL:      siteURL,
+		Status:       models.IncidentStatusOpen,
+		Title:        fmt.Sprintf("%s is down", siteURL),
+		Cause:        check.Error,
+		LastError:    check.Error,
+		StartedAt:    now,
+		LastCheckAt:  &now,
+		CheckCount:   1,
+		FailedChecks: 1,
+	}
+	if err := s.db.CreateIncident(inc); err != nil {
+		log.Printf("❌ Ошибка создания инцидента для %s: %v", siteURL, err)
+		return
+	}
+
+	s.addEvent(&models.IncidentEvent{
+		IncidentID:   inc.ID,
+		Type:         models.IncidentEventOpened,
+		Message:      check.Error,
+		Status:       check.Status,
+		StatusCode:   check.StatusCode,
+		ResponseTime: check.ResponseTime,
+	})
+
+	log.Printf("🚨 Открыт инцидент #%d для %s", inc.ID, siteURL)
+	notifyChange(*inc)
+}
+
+func (s *Service) resolve(inc *models.Incident, by, message string, now time.Time) {
+	if err := s.db.ResolveIncident(inc.ID, by, now); err != nil {
+		log.Printf("❌ Ошибка закрытия инцидента #%d: %v", inc.ID, err)
+		return
+	}
+	inc.Status = models.IncidentStatusResolved
+	inc.ResolvedAt = &now
+	inc.ResolvedBy = by
+
+	s.addEvent(&models.IncidentEvent{
+		IncidentID: inc.ID,
+		Type:       models.IncidentEventResolved,
+		Message:    message,
+		Author:     by,
+	})
+
+	log.Printf("✅ Инцидент #%d для %s закрыт", inc.ID, inc.SiteURL)
+	notifyChange(*inc)
+}
+
+// Acknowledge marks the incident as taken care of. Alerts for the site are
+// suppressed until the incident is resolved.
+func (s *Service) Acknowledge(id int, by string) (*models.Incident, error) {
+	if err := s.db.AcknowledgeIncident(id, by); err != nil {
+		return nil, err
+	}
+
+	message := "Incident acknowledged"
+	if by != "" {
+		message += " by " + by
+	}
+	return s.afterAction(id, models.IncidentEventAcknowledged, message, by)
+}
+
+// Assign sets the person responsible for the incident.
+func (s *Service) Assign(id int, assignee, by string) (*models.Incident, error) {
+	assignee = strings.TrimSpace(assignee)
+	if assignee == "" {
+		return nil, fmt.Errorf("assignee is required")
+	}
+	if err := s.db.AssignIncident(id, assignee); err != nil {
+		return nil, err
+	}
+	return s.afterAction(id, models.IncidentEventAssigned, "Assigned to "+assignee, by)
+}
+
+// Comment adds a note of a user to the timeline.
+func (s *Service) Comment(id int, author, message string) (*models.IncidentEvent, error) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return nil, fmt.Errorf("message is required")
+	}
+	if _, err := s.db.GetIncident(id); err != nil {
+		return nil, err
+	}
+
+	event := &models.IncidentEvent{
+		IncidentID: id,
+		Type:       models.IncidentEventComment,
+		Message:    message,
+		Author:     author,
+	}
+	if err := s.db.AddIncidentEvent(event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (s *Service) afterAction(id int, eventType, message, by string) (*models.Incident, error) {
+	s.addEvent(&models.IncidentEvent{
+		IncidentID: id,
+		Type:       eventType,
+		Message:    message,
+		Author:     by,
+	})
+
+	inc, err := s.db.GetIncident(id)
+	if err != nil {
+		return nil, err
+	}
+	notifyChange(*inc)
+	return inc, nil
+}
+
+// Suppress is registered with notifications.AddSuppressor: alerts of a site
+// whose incident is acknowledged are not delivered.
+func (s *Service) Suppress(siteID int, alertType string) (bool, string) {
+	if siteID == 0 {
+		return false, ""
+	}
+
+	inc, err := s.db.GetActiveIncident(siteID)
+	if err != nil || inc == nil || inc.Status != models.IncidentStatusAcknowledged {
+		return false, ""
+	}
+
+	reason := fmt.Sprintf("инцидент #%d подтвержден", inc.ID)
+	if inc.AcknowledgedBy != "" {
+		reason += " (" + inc.AcknowledgedBy + ")"
+	}
+	return true, reason
+}
+
+// RecordAlert is set as notifications.AlertDelivered and adds the delivery
+// to the timeline of the incident of the site.
+func (s *Service) RecordAlert(alertData notifications.AlertData, channel string, err error) {
+	if alertData.SiteID == 0 {
+		return
+	}
+
+	inc, dbErr := s.db.GetRecentIncident(alertData.SiteID, time.Now().Add(-alertWindow))
+	if dbErr != nil || inc == nil {
+		return
+	}
+
+	event := &models.IncidentEvent{
+		IncidentID: inc.ID,
+		Type:       models.IncidentEventAlert,
+		Message:    fmt.Sprintf("%s alert sent", alertData.AlertType),
+		Status:     "sent",
+		Channel:    channel,
+	}
+	if err != nil {
+		event.Message = fmt.Sprintf("%s alert failed: %v", alertData.AlertType, err)
+		event.Status = "failed"
+	}
+	s.addEvent(event)
+}
+
+func (s *Service) addEvent(event *models.IncidentEvent) {
+	if err := s.db.AddIncidentEvent(event); err != nil {
+		log.Printf("⚠️ Ошибка записи события инцидента #%d: %v", event.IncidentID, err)
+	}
+}
+
+func notifyChange(inc models.Incident) {
+	if OnChange != nil {
+		OnChange(inc)
+	}
+}
@@ -0,0 +1,173 @@
+package maintenance
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"ping-tower/internal/database"
+	"ping-tower/internal/models"
+	"ping-tower/internal/scheduler"
+)
+
+// MaxDuration limits recurring windows, an active window is found by
+// looking back over its duration minute by minute.
+const MaxDuration = 7 * 24 * time.Hour
+
+type Service struct {
+	db *database.DB
+}
+
+func NewService(db *database.DB) *Service {
+	return &Service{db: db}
+}
+
+// Validate checks the window and normalizes its scope and tags.
+func Validate(w *models.MaintenanceWindow) error {
+	if strings.TrimSpace(w.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	if w.Scope == "" {
+		w.Scope = models.MaintenanceScopeAll
+	}
+	switch w.Scope {
+	case models.MaintenanceScopeAll:
+	case models.MaintenanceScopeSites:
+		if len(w.SiteIDs) == 0 {
+			return fmt.Errorf("site_ids are required for scope 'sites'")
+		}
+	case models.MaintenanceScopeTags:
+		w.Tags = NormalizeTags(w.Tags)
+		if len(w.Tags) == 0 {
+			return fmt.Errorf("tags are required for scope 'tags'")
+		}
+	default:
+		return fmt.Errorf("scope must be all, sites or tags")
+	}
+	if w.SiteIDs == nil {
+		w.SiteIDs = []int{}
+	}
+	if w.Tags == nil {
+		w.Tags = []string{}
+	}
+
+	if w.StartsAt != nil && w.EndsAt != nil && !w.EndsAt.After(*w.StartsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+
+	if w.IsRecurring() {
+		if _, err := scheduler.ParseCronExpression(w.CronSchedule); err != nil {
+			return fmt.Errorf("invalid cron_schedule: %v", err)
+		}
+		duration := time.Duration(w.DurationMinutes) * time.Minute
+		if duration <= 0 || duration > MaxDuration {
+			return fmt.Errorf("duration_minutes must be between 1 and %d", int(MaxDuration.Minutes()))
+		}
+		return nil
+	}
+
+	if w.StartsAt == nil || w.EndsAt == nil {
+		return fmt.Errorf("starts_at and ends_at are required for a one-off window, or set cron_schedule and duration_minutes")
+	}
+	return nil
+}
+
+// NormalizeTags trims tags and drops empty and duplicate ones.
+func NormalizeTags(tags []string) []string {
+	result := []string{}
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// IsActive reports whether the window is in effect at t. A recurring window
+// is active if one of its cron runs started less than its duration ago.
+func IsActive(w *models.MaintenanceWindow, t time.Time) bool {
+	if !w.Enabled {
+		return false
+	}
+	if w.StartsAt != nil && t.Before(*w.StartsAt) {
+		return false
+	}
+	if w.EndsAt != nil && !t.Before(*w.EndsAt) {
+		return false
+	}
+	if !w.IsRecurring() {
+		return w.StartsAt != nil && w.EndsAt != nil
+	}
+
+	schedule, err := scheduler.ParseCronExpression(w.CronSchedule)
+	if err != nil {
+		return false
+	}
+
+	duration := time.Duration(w.DurationMinutes) * time.Minute
+	if duration > MaxDuration {
+		duration = MaxDuration
+	}
+
+	minute := t.Truncate(time.Minute)
+	for start := minute; t.Sub(start) < duration; start = start.Add(-time.Minute) {
+		if schedule.Matches(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveWindow returns the window covering the site at t, or nil.
+func (s *Service) ActiveWindow(siteID int, tags []string, t time.Time) *models.MaintenanceWindow {
+	windows, err := s.db.GetEnabledMaintenanceWindows()
+	if err != nil {
+		log.Printf("⚠️ Ошибка загрузки окон обслуживания: %v", err)
+		return nil
+	}
+
+	for i := range windows {
+		if windows[i].AppliesTo(siteID, tags) && IsActive(&windows[i], t) {
+			return &windows[i]
+		}
+	}
+	return nil
+}
+
+// SiteWindow returns the window covering the site now. config may be nil,
+// then the site tags are loaded from the database.
+func (s *Service) SiteWindow(siteID int, config *models.SiteConfig) *models.MaintenanceWindow {
+	if config == nil && siteID > 0 {
+		config, _ = s.db.GetSiteConfig(siteID)
+	}
+
+	var tags []string
+	if config != nil {
+		tags = config.Tags
+	}
+	return s.ActiveWindow(siteID, tags, time.Now())
+}
+
+// Suppress is registered with notifications.AddSuppressor: no alerts are
+// sent for a site under maintenance. Alerts that do not belong to a site
+// (siteID 0, e.g. heartbeats) are only covered by windows for everything.
+func (s *Service) Suppress(siteID int, alertType string) (bool, string) {
+	w := s.SiteWindow(siteID, nil)
+	if w == nil {
+		return false, ""
+	}
+	return true, fmt.Sprintf("окно обслуживания '%s'", w.Name)
+}
+
+// MarkActive fills the Active flag of the windows for the API.
+func MarkActive(windows []models.MaintenanceWindow, t time.Time) {
+	for i := range windows {
+		windows[i].Active = IsActive(&windows[i], t)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"log"
 	"ping-tower/internal/database"
 	"ping-tower/internal/monitor"
-	"ping-tower/internal/notifications"
 	"sync"
 	"time"
 )
@@ -13,7 +12,6 @@ import (
 type Service struct {
 	clickhouse    *database.ClickHouseDB
 	postgres      *database.DB
-	alertManager  *notifications.AlertManager
 	batchSize     int
 	flushInterval time.Duration
 	buffer        []database.SiteMetric
@@ -35,6 +33,7 @@ type SiteState struct {
 	DownSince        *time.Time
 	LastResponseTime int64
 	LastSSLExpiry    *time.Time
+	LastSSLFingerprint string
 	LastMetricSent   time.Time 
 }
 
@@ -55,7 +54,6 @@ func NewService(config Config, postgresDB *database.DB) (*Service, error) {
 	service := &Service{
 		clickhouse:    clickhouseDB,
 		postgres:      postgresDB,
-		alertManager:  nil, // Will be set externally
 		batchSize:     config.BatchSize,
 		flushInterval: config.FlushInterval,
 		buffer:        make([]database.SiteMetric, 0, config.BatchSize),
@@ -83,10 +81,6 @@ func NewService(config Config, postgresDB *database.DB) (*Service, error) {
 	return service, nil
 }
 
-func (s *Service) SetAlertManager(alertManager *notifications.AlertManager) {
-	s.alertManager = alertManager
-}
-
 func (s *Service) startBatchProcessor() {
 	s.wg.Add(1)
 	go func() {
@@ -117,6 +111,9 @@ func (s *Service) RecordCheckResult(siteID int, siteURL string, result monitor.C
 		return nil
 	}
 
+	// SSL changes are detected against the state before this check updates it.
+	s.recordSSLCertificateChange(siteID, siteURL, result)
+
 	s.handleSiteStateChange(siteID, siteURL, result)
 
 	metric := s.convertCheckResultToMetric(siteID, siteURL, result, checkType)
@@ -135,7 +132,8 @@ func (s *Service) RecordCheckResult(siteID int, siteURL string, result monitor.C
 		s.flushBuffer()
 	}
 
-	if result.Status == "down" {
+	// Проверки во время обслуживания записываются с флагом и не считаются простоем
+	if result.Status == "down" && !result.Maintenance {
 		if err := s.recordDowntimeEvent(uint32(siteID), siteURL, result.Error, uint16(result.StatusCode)); err != nil {
 			log.Printf("⚠️ Failed to record downtime event: %v", err)
 		}
@@ -152,20 +150,54 @@ func (s *Service) RecordCheckResult(siteID int, siteURL string, result monitor.C
 		}
 	}
 
-	if result.SSLExpiry != nil && result.SSLAlgorithm != "" {
-		s.statesMutex.RLock()
-		state := s.siteStates[siteID]
-		sslChanged := state.LastSSLExpiry == nil || !state.LastSSLExpiry.Equal(*result.SSLExpiry)
-		s.statesMutex.RUnlock()
-		
-		if sslChanged {
-			if err := s.updateSSLCertificate(uint32(siteID), siteURL, result); err != nil {
-				log.Printf("⚠️ Failed to update SSL certificate info: %v", err)
-			}
+	return nil
+}
+
+func (s *Service) recordSSLCertificateChange(siteID int, siteURL string, result monitor.CheckResult) {
+	if result.SSLExpiry == nil || result.SSLAlgorithm == "" {
+		return
+	}
+
+	fingerprint := ""
+	if result.TLS != nil {
+		fingerprint = result.TLS.Fingerprint
+	}
+
+	s.statesMutex.RLock()
+	state := s.siteStates[siteID]
+	s.statesMutex.RUnlock()
+
+	previousFingerprint := state.LastSSLFingerprint
+	if previousFingerprint == "" && fingerprint != "" {
+		if fp, err := s.clickhouse.GetLatestSSLFingerprint(uint32(siteID)); err == nil {
+			previousFingerprint = fp
+		} else {
+			log.Printf("⚠️ Failed to load last SSL fingerprint: %v", err)
 		}
 	}
 
-	return nil
+	expiryChanged := state.LastSSLExpiry == nil || !state.LastSSLExpiry.Equal(*result.SSLExpiry)
+	fingerprintChanged := fingerprint != "" && fingerprint != previousFingerprint
+
+	if fingerprint != "" {
+		s.statesMutex.Lock()
+		state := s.siteStates[siteID]
+		state.LastSSLFingerprint = fingerprint
+		s.siteStates[siteID] = state
+		s.statesMutex.Unlock()
+	}
+
+	if !expiryChanged && !fingerprintChanged {
+		return
+	}
+
+	if fingerprintChanged && previousFingerprint != "" {
+		log.Printf("🔁 SSL certificate fingerprint changed for %s: %s -> %s", siteURL, previousFingerprint, fingerprint)
+	}
+
+	if err := s.updateSSLCertificate(uint32(siteID), siteURL, result, previousFingerprint); err != nil {
+		log.Printf("⚠️ Failed to update SSL certificate info: %v", err)
+	}
 }
 
 func (s *Service) checkDailyLimit() bool {
@@ -245,12 +277,10 @@ func (s *Service) handleSiteStateChange(siteID int, siteURL string, result monit
 			newState.LastStatus = "down"
 			newState.DownSince = &now
 			log.Printf("🔴 Site %s went DOWN", siteURL)
-			s.sendAlert(siteID, siteURL, result, "site_down")
 		} else if result.Status == "up" && currentState.LastStatus == "down" {
 			newState.LastStatus = "up"
 			newState.DownSince = nil
 			log.Printf("🟢 Site %s is back UP", siteURL)
-			s.sendAlert(siteID, siteURL, result, "site_up")
 		} else if result.Status == "up" {
 			newState.LastStatus = "up"
 			newState.DownSince = nil
@@ -282,6 +312,17 @@ func (s *Service) convertCheckResultToMetric(siteID int, siteURL string, result
 		ResponseTimeMs: uint64(result.ResponseTime),
 		CheckType:     checkType,
 		ConfigVersion: 1,
+
+		PacketsSent:       uint16(result.PacketsSent),
+		PacketsReceived:   uint16(result.PacketsReceived),
+		PacketLossPercent: float32(result.PacketLoss),
+		RTTMinMs:          result.RTTMin,
+		RTTAvgMs:          result.RTTAvg,
+		RTTMaxMs:          result.RTTMax,
+		JitterMs:          result.Jitter,
+	}
+	if result.Maintenance {
+		metric.Maintenance = 1
 	}
 
 	s.metricsMutex.RLock()
@@ -376,21 +417,46 @@ func (s *Service) resolveDowntimeEvent(siteID uint32) error {
 	return s.clickhouse.ResolveDowntimeEvent(siteID)
 }
 
-func (s *Service) updateSSLCertificate(siteID uint32, siteURL string, result monitor.CheckResult) error {
+func (s *Service) updateSSLCertificate(siteID uint32, siteURL string, result monitor.CheckResult, previousFingerprint string) error {
 	if result.SSLExpiry == nil {
 		return nil
 	}
 
-	return s.clickhouse.UpdateSSLCertificate(
-		siteID, siteURL, result.SSLIssuer, result.SSLAlgorithm,
-		uint16(result.SSLKeyLength), *result.SSLExpiry,
-	)
+	cert := database.SSLCertificate{
+		SiteID:              siteID,
+		SiteURL:             siteURL,
+		CheckTarget:         result.FinalURL,
+		Issuer:              result.SSLIssuer,
+		Algorithm:           result.SSLAlgorithm,
+		KeyLength:           uint16(result.SSLKeyLength),
+		Expiry:              *result.SSLExpiry,
+		PreviousFingerprint: previousFingerprint,
+	}
+
+	if tlsInfo := result.TLS; tlsInfo != nil {
+		cert.Subject = tlsInfo.Subject
+		cert.SANs = tlsInfo.SANs
+		cert.Chain = tlsInfo.Chain
+		cert.HostnameMatch = tlsInfo.HostnameMatch
+		cert.ChainValid = tlsInfo.ChainValid
+		cert.OCSPStapled = tlsInfo.OCSPStapled
+		cert.OCSPStatus = tlsInfo.OCSPStatus
+		cert.TLSVersion = tlsInfo.Version
+		cert.TLSCipher = tlsInfo.Cipher
+		cert.Fingerprint = tlsInfo.Fingerprint
+	}
+
+	return s.clickhouse.UpdateSSLCertificate(cert)
 }
 
 func (s *Service) GetHourlyMetrics(siteID int, hours int) ([]database.HourlyMetrics, error) {
 	return s.clickhouse.GetHourlyMetrics(uint32(siteID), hours)
 }
 
+func (s *Service) GetHourlyResponseTimes(days int) ([]database.HourlyResponseTime, error) {
+	return s.clickhouse.GetHourlyResponseTimes(days)
+}
+
 func (s *Service) GetExpiringSSLCertificates(days int) ([]map[string]interface{}, error) {
 	return s.clickhouse.GetExpiringSSLCertificates(days)
 }
@@ -517,52 +583,3 @@ func (s *Service) Close() error {
 
 	return nil
 }
-
-func (s *Service) sendAlert(siteID int, siteURL string, result monitor.CheckResult, alertType string) {
-	if s.alertManager == nil {
-		return
-	}
-
-	// Конвертируем monitor.CheckResult в notifications.CheckResult
-	notificationResult := notifications.CheckResult{
-		Status:        result.Status,
-		StatusCode:    result.StatusCode,
-		ResponseTime:  result.ResponseTime,
-		ContentLength: result.ContentLength,
-		SSLValid:      result.SSLValid,
-		SSLExpiry:     result.SSLExpiry,
-		Error:         result.Error,
-		DNSTime:       result.DNSTime,
-		ConnectTime:   result.ConnectTime,
-		TLSTime:       result.TLSTime,
-		TTFB:          result.TTFB,
-		ContentHash:   result.ContentHash,
-		RedirectCount: result.RedirectCount,
-		FinalURL:      result.FinalURL,
-		Headers:       result.Headers,
-		Keywords:      result.Keywords,
-		SSLKeyLength:  result.SSLKeyLength,
-		SSLAlgorithm:  result.SSLAlgorithm,
-		SSLIssuer:     result.SSLIssuer,
-		ServerType:    result.ServerType,
-		PoweredBy:     result.PoweredBy,
-		ContentType:   result.ContentType,
-		CacheControl:  result.CacheControl,
-		Cookies:       result.Cookies,
-	}
-
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				log.Printf("❌ Паника при отправке алерта из metrics для %s: %v", siteURL, r)
-			}
-		}()
-
-		err := s.alertManager.SendAlert(siteID, siteURL, notificationResult, alertType)
-		if err != nil {
-			log.Printf("❌ Ошибка отправки алерта из metrics для %s: %v", siteURL, err)
-		} else {
-			log.Printf("📧 Алерт отправлен из metrics для %s (тип: %s)", siteURL, alertType)
-		}
-	}()
-}
\ No newline at end of file
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EscalationPolicy notifies a growing circle of people while an alert stays
+// unacknowledged: every step waits DelayMinutes after the previous one.
+type EscalationPolicy struct {
+	ID          int              `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Steps       []EscalationStep `json:"steps"`
+	Enabled     bool             `json:"enabled"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// EscalationStep sends the alert to the listed channels. OnCallScheduleID
+// adds the person on call to the recipients; recipients fall back to the
+// alert configuration when not set.
+type EscalationStep struct {
+	DelayMinutes     int      `json:"delay_minutes"`
+	Channels         []string `json:"channels"`
+	EmailTo          []string `json:"email_to,omitempty"`
+	WebhookURL       string   `json:"webhook_url,omitempty"`
+	TelegramChatID   string   `json:"telegram_chat_id,omitempty"`
+	OnCallScheduleID int      `json:"oncall_schedule_id,omitempty"`
+}
+
+// Escalation is a running policy for one alert. Its state lives in the
+// database so that a restart continues where it stopped.
+type Escalation struct {
+	ID               int             `json:"id"`
+	PolicyID         int             `json:"policy_id"`
+	ConfigName       string          `json:"config_name"`
+	SiteID           int             `json:"site_id"`
+	SiteURL          string          `json:"site_url"`
+	AlertType        string          `json:"alert_type"`
+	Alert            json.RawMessage `json:"alert"`
+	Status           string          `json:"status"`
+	CurrentStep      int             `json:"current_step"`
+	NotifiedChannels []string        `json:"notified_channels"`
+	NextStepAt       *time.Time      `json:"next_step_at,omitempty"`
+	AcknowledgedAt   *time.Time      `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy   string          `json:"acknowledged_by"`
+	StartedAt        time.Time       `json:"started_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+const (
+	EscalationStatusActive       = "active"
+	EscalationStatusAcknowledged = "acknowledged"
+	EscalationStatusResolved     = "resolved"
+	EscalationStatusCompleted    = "completed"
+)
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Heartbeat is a push monitor: a job calls its URL on every run and the
+// monitor goes down when a ping does not arrive in time.
+type Heartbeat struct {
+	ID            int        `json:"id"`
+	Name          string     `json:"name"`
+	Token         string     `json:"token"`
+	PeriodSeconds int        `json:"period_seconds"`
+	GraceSeconds  int        `json:"grace_seconds"`
+	CronSchedule  string     `json:"cron_schedule"`
+	Status        string     `json:"status"`
+	Enabled       bool       `json:"enabled"`
+	LastPingAt    *time.Time `json:"last_ping_at,omitempty"`
+	LastStartAt   *time.Time `json:"last_start_at,omitempty"`
+	LastDuration  int64      `json:"last_duration_ms"`
+	LastLog       string     `json:"last_log"`
+	NextDueAt     *time.Time `json:"next_due_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// HeartbeatPing is one received ping with an optional log excerpt.
+type HeartbeatPing struct {
+	ID          int       `json:"id"`
+	HeartbeatID int       `json:"heartbeat_id"`
+	Kind        string    `json:"kind"`
+	Log         string    `json:"log"`
+	RemoteAddr  string    `json:"remote_addr"`
+	Duration    int64     `json:"duration_ms"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+const (
+	HeartbeatStatusNew     = "new"
+	HeartbeatStatusUp      = "up"
+	HeartbeatStatusRunning = "running"
+	HeartbeatStatusDown    = "down"
+
+	HeartbeatPingSuccess = "success"
+	HeartbeatPingStart   = "start"
+	HeartbeatPingFail    = "fail"
+)
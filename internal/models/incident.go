@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Incident groups the checks of one outage of a site, from the first
+// confirmed failure to the recovery.
+type Incident struct {
+	ID             int             `json:"id"`
+	SiteID         int             `json:"site_id"`
+	SiteURL        string          `json:"site_url"`
+	Status         string          `json:"status"`
+	Title          string          `json:"title"`
+	Cause          string          `json:"cause"`
+	LastError      string          `json:"last_error"`
+	StartedAt      time.Time       `json:"started_at"`
+	AcknowledgedAt *time.Time      `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy string          `json:"acknowledged_by"`
+	AssignedTo     string          `json:"assigned_to"`
+	ResolvedAt     *time.Time      `json:"resolved_at,omitempty"`
+	ResolvedBy     string          `json:"resolved_by"`
+	LastCheckAt    *time.Time      `json:"last_check_at,omitempty"`
+	CheckCount     int             `json:"check_count"`
+	FailedChecks   int             `json:"failed_checks"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	Events         []IncidentEvent `json:"events,omitempty"`
+}
+
+// IncidentEvent is one entry of the incident timeline: a check result, an
+// alert delivery or an action of a user.
+type IncidentEvent struct {
+	ID           int       `json:"id"`
+	IncidentID   int       `json:"incident_id"`
+	Type         string    `json:"type"`
+	Message      string    `json:"message"`
+	Author       string    `json:"author,omitempty"`
+	Status       string    `json:"status,omitempty"`
+	StatusCode   int       `json:"status_code,omitempty"`
+	ResponseTime int64     `json:"response_time,omitempty"`
+	Channel      string    `json:"channel,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+const (
+	IncidentStatusOpen         = "open"
+	IncidentStatusAcknowledged = "acknowledged"
+	IncidentStatusResolved     = "resolved"
+
+	IncidentEventOpened       = "opened"
+	IncidentEventCheck        = "check"
+	IncidentEventAlert        = "alert"
+	IncidentEventAcknowledged = "acknowledged"
+	IncidentEventAssigned     = "assigned"
+	IncidentEventComment      = "comment"
+	IncidentEventResolved     = "resolved"
+)
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// MaintenanceWindow is a period in which a site is expected to be down:
+// checks keep running but alerts are not sent and the checks do not count
+// towards uptime.
+type MaintenanceWindow struct {
+	ID              int        `json:"id"`
+	Name            string     `json:"name"`
+	Description     string     `json:"description"`
+	Scope           string     `json:"scope"`
+	SiteIDs         []int      `json:"site_ids"`
+	Tags            []string   `json:"tags"`
+	StartsAt        *time.Time `json:"starts_at,omitempty"`
+	EndsAt          *time.Time `json:"ends_at,omitempty"`
+	CronSchedule    string     `json:"cron_schedule"`
+	DurationMinutes int        `json:"duration_minutes"`
+	Enabled         bool       `json:"enabled"`
+	Active          bool       `json:"active"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+const (
+	MaintenanceScopeAll   = "all"
+	MaintenanceScopeSites = "sites"
+	MaintenanceScopeTags  = "tags"
+)
+
+// IsRecurring reports whether the window repeats on a cron schedule.
+func (w *MaintenanceWindow) IsRecurring() bool {
+	return w.CronSchedule != ""
+}
+
+// AppliesTo reports whether the window covers the site with the given tags.
+func (w *MaintenanceWindow) AppliesTo(siteID int, tags []string) bool {
+	switch w.Scope {
+	case MaintenanceScopeAll:
+		return true
+	case MaintenanceScopeSites:
+		for _, id := range w.SiteIDs {
+			if id == siteID {
+				return true
+			}
+		}
+	case MaintenanceScopeTags:
+		for _, tag := range w.Tags {
+			for _, siteTag := range tags {
+				if tag == siteTag {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// QueuedNotification is a failed delivery of an alert on one channel. It is
+// retried until it is sent or runs out of attempts and becomes a dead letter.
+// It is superseded when a newer alert of its site and family, e.g. the
+// recovery of an outage, is delivered on the same channel, so a stale alert
+// never reopens or resolves an incident.
+type QueuedNotification struct {
+	ID            int             `json:"id"`
+	SiteID        int             `json:"site_id"`
+	SiteURL       string          `json:"site_url"`
+	AlertType     string          `json:"alert_type"`
+	Channel       string          `json:"channel"`
+	ConfigName    string          `json:"config_name"`
+	Recipients    json.RawMessage `json:"recipients,omitempty"`
+	Alert         json.RawMessage `json:"alert"`
+	AlertedAt     time.Time       `json:"alerted_at"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	MaxAttempts   int             `json:"max_attempts"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"`
+	LastError     string          `json:"last_error"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	SentAt        *time.Time      `json:"sent_at,omitempty"`
+}
+
+const (
+	NotificationStatusPending    = "pending"
+	NotificationStatusSent       = "sent"
+	NotificationStatusDead       = "dead"
+	NotificationStatusSuperseded = "superseded"
+)
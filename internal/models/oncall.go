@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// OnCallUser is a person who can be on call, with the contacts alerts are
+// routed to while they are.
+type OnCallUser struct {
+	ID             int       `json:"id"`
+	Name           string    `json:"name"`
+	Email          string    `json:"email"`
+	TelegramChatID string    `json:"telegram_chat_id"`
+	WebhookURL     string    `json:"webhook_url"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// OnCallSchedule combines rotation layers. At any moment the last layer that
+// has somebody on call wins; overrides win over every layer.
+type OnCallSchedule struct {
+	ID          int           `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Timezone    string        `json:"timezone"`
+	Layers      []OnCallLayer `json:"layers"`
+	Enabled     bool          `json:"enabled"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// OnCallLayer rotates its users every day or week, handing off at the time of
+// StartAt. RestrictFrom/RestrictTo ("HH:MM") limit the layer to part of the
+// day, e.g. business hours; the range may wrap over midnight.
+type OnCallLayer struct {
+	Name         string    `json:"name"`
+	Rotation     string    `json:"rotation"`
+	StartAt      time.Time `json:"start_at"`
+	UserIDs      []int     `json:"user_ids"`
+	RestrictFrom string    `json:"restrict_from,omitempty"`
+	RestrictTo   string    `json:"restrict_to,omitempty"`
+}
+
+// OnCallOverride puts a user on call for a period, e.g. to cover a vacation.
+type OnCallOverride struct {
+	ID         int       `json:"id"`
+	ScheduleID int       `json:"schedule_id"`
+	UserID     int       `json:"user_id"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// OnCallShift is a computed period of the final schedule.
+type OnCallShift struct {
+	UserID   int         `json:"user_id"`
+	User     *OnCallUser `json:"user,omitempty"`
+	Layer    string      `json:"layer"`
+	Override bool        `json:"override"`
+	Start    time.Time   `json:"start"`
+	End      time.Time   `json:"end"`
+}
+
+const (
+	OnCallRotationDaily  = "daily"
+	OnCallRotationWeekly = "weekly"
+)
@@ -2,6 +2,9 @@ package models
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -38,6 +41,10 @@ type Site struct {
 	PoweredBy         string      `json:"powered_by"`
 	ContentType       string      `json:"content_type"`
 	CacheControl      string      `json:"cache_control"`
+
+	PendingStatus        string `json:"pending_status"`
+	ConsecutiveFailures  int    `json:"consecutive_failures"`
+	ConsecutiveSuccesses int    `json:"consecutive_successes"`
 }
 
 type SiteConfig struct {
@@ -51,6 +58,7 @@ type SiteConfig struct {
 	MaxRedirects     int                    `json:"max_redirects"`
 	CheckSSL         bool                   `json:"check_ssl"`
 	SSLAlertDays     int                    `json:"ssl_alert_days"`
+	SSLAlertThresholds string               `json:"ssl_alert_thresholds"`
 	CheckKeywords    string                 `json:"check_keywords"`
 	AvoidKeywords    string                 `json:"avoid_keywords"`
 	Headers          map[string]interface{} `json:"headers"`
@@ -58,12 +66,43 @@ type SiteConfig struct {
 	Enabled          bool                   `json:"enabled"`
 	NotifyOnDown     bool                   `json:"notify_on_down"`
 	NotifyOnUp       bool                   `json:"notify_on_up"`
-	
-	CollectDNSTime       bool `json:"collect_dns_time"`
+
+	CheckType        string                 `json:"check_type"`
+	Target           string                 `json:"target"`
+	ConnectTimeout   int                    `json:"connect_timeout"`
+	TCPSend          string                 `json:"tcp_send"`
+	TCPExpect        string                 `json:"tcp_expect"`
+	PingCount        int                    `json:"ping_count"`
+	PingInterval     int                    `json:"ping_interval"`
+	MaxPacketLoss    float64                `json:"max_packet_loss"`
+	DNSRecordType    string                 `json:"dns_record_type"`
+	DNSResolver      string                 `json:"dns_resolver"`
+	DNSExpected      string                 `json:"dns_expected"`
+	TLSStartTLS      string                 `json:"tls_starttls"`
+	TLSServerName    string                 `json:"tls_server_name"`
+	TLSAllowUntrusted bool                  `json:"tls_allow_untrusted"`
+	Steps            []HTTPStep             `json:"steps"`
+	JSONAssertions   string                 `json:"json_assertions"`
+	Method           string                 `json:"method"`
+	RequestBody      string                 `json:"request_body"`
+	RequestContentType string               `json:"request_content_type"`
+	ExpectedStatusCodes string              `json:"expected_status_codes"`
+	HeaderAssertions string                 `json:"header_assertions"`
+	FailThreshold    int                    `json:"fail_threshold"`
+	RecoverThreshold int                    `json:"recover_threshold"`
+	RetryOnFailure   bool                   `json:"retry_on_failure"`
+	RetryDelay       int                    `json:"retry_delay"`
+	Tags             []string               `json:"tags"`
+	EscalationPolicyID int                  `json:"escalation_policy_id"`
+
+	CollectDNSTime      bool `json:"collect_dns_time"`
 	CollectConnectTime   bool `json:"collect_connect_time"`
 	CollectTLSTime       bool `json:"collect_tls_time"`
 	CollectTTFB          bool `json:"collect_ttfb"`
 	CollectContentHash   bool `json:"collect_content_hash"`
+	// ContentMasks hides dynamic parts of the page from the content hash and
+	// snapshots: one CSS selector or "regex:<pattern>" per line
+	ContentMasks         string `json:"content_masks"`
 	CollectRedirects     bool `json:"collect_redirects"`
 	CollectSSLDetails    bool `json:"collect_ssl_details"`
 	CollectServerInfo    bool `json:"collect_server_info"`
@@ -134,6 +173,134 @@ func (sc *SiteConfig) GetScheduleDescription() string {
 	return fmt.Sprintf("Каждые %d дней", days)
 }
 
+const (
+	CheckTypeHTTP = "http"
+	CheckTypeTCP  = "tcp"
+	CheckTypeICMP = "icmp"
+	CheckTypeDNS  = "dns"
+	CheckTypeTLS  = "tls"
+
+	CheckTypeMultiStep = "multistep"
+)
+
+// IsValidCheckType reports whether the check type is supported by the checker.
+// An empty value is treated as "http".
+func IsValidCheckType(checkType string) bool {
+	switch checkType {
+	case "", CheckTypeHTTP, CheckTypeTCP, CheckTypeICMP, CheckTypeDNS, CheckTypeTLS, CheckTypeMultiStep:
+		return true
+	}
+	return false
+}
+
+// HTTPMethods lists the request methods supported by the HTTP check.
+var HTTPMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// IsValidHTTPMethod reports whether the HTTP check can send the method.
+// An empty value is treated as "GET".
+func IsValidHTTPMethod(method string) bool {
+	if method == "" {
+		return true
+	}
+	for _, m := range HTTPMethods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidStartTLS reports whether the TLS check can upgrade the protocol with
+// STARTTLS. An empty value means implicit TLS.
+func IsValidStartTLS(protocol string) bool {
+	switch strings.ToLower(protocol) {
+	case "", "smtp", "imap", "postgres":
+		return true
+	}
+	return false
+}
+
+// DNSRecordTypes lists the record types supported by the DNS check.
+var DNSRecordTypes = []string{"A", "AAAA", "CNAME", "MX", "TXT", "NS"}
+
+// IsValidDNSRecordType reports whether the DNS check can query the record type.
+// An empty value is treated as "A".
+func IsValidDNSRecordType(recordType string) bool {
+	if recordType == "" {
+		return true
+	}
+	for _, t := range DNSRecordTypes {
+		if strings.EqualFold(t, recordType) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetEffectiveCheckType returns the configured check type, falling back to the
+// scheme of the site URL (tcp://host:port, icmp://host, dns://name,
+// tls://host:port) for sites without an explicit type.
+func (sc *SiteConfig) GetEffectiveCheckType(siteURL string) string {
+	if sc.CheckType != "" && sc.CheckType != CheckTypeHTTP {
+		return sc.CheckType
+	}
+
+	if u, err := url.Parse(siteURL); err == nil {
+		switch u.Scheme {
+		case CheckTypeTCP, CheckTypeICMP, CheckTypeDNS, CheckTypeTLS:
+			return u.Scheme
+		}
+	}
+
+	return CheckTypeHTTP
+}
+
+// GetTargetHost returns the host the check should talk to without a port.
+func (sc *SiteConfig) GetTargetHost(siteURL string) (string, error) {
+	target := strings.TrimSpace(sc.Target)
+	if target == "" {
+		u, err := url.Parse(siteURL)
+		if err != nil {
+			return "", fmt.Errorf("invalid URL: %w", err)
+		}
+		target = u.Hostname()
+		if target == "" {
+			target = siteURL
+		}
+	}
+
+	if host, _, err := net.SplitHostPort(target); err == nil {
+		return host, nil
+	}
+
+	return target, nil
+}
+
+// GetTargetAddress returns the host:port the check should connect to. The
+// explicit Target wins, otherwise the host of the site URL is used.
+func (sc *SiteConfig) GetTargetAddress(siteURL string, defaultPort string) (string, error) {
+	address := strings.TrimSpace(sc.Target)
+	if address == "" {
+		u, err := url.Parse(siteURL)
+		if err != nil {
+			return "", fmt.Errorf("invalid URL: %w", err)
+		}
+		address = u.Host
+		if address == "" {
+			address = siteURL
+		}
+	}
+
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		if defaultPort == "" {
+			return "", fmt.Errorf("target %q must be in host:port form", address)
+		}
+		address = net.JoinHostPort(address, defaultPort)
+	}
+
+	return address, nil
+}
+
 type SiteHistory struct {
 	ID           int       `json:"id"`
 	SiteID       int       `json:"site_id"`
@@ -141,6 +308,9 @@ type SiteHistory struct {
 	StatusCode   int       `json:"status_code"`
 	ResponseTime int64     `json:"response_time_ms"`
 	Error        string    `json:"error"`
+	FailedStep   string       `json:"failed_step,omitempty"`
+	Steps        []StepResult `json:"steps,omitempty"`
+	Maintenance  bool      `json:"maintenance,omitempty"`
 	CheckedAt    time.Time `json:"checked_at"`
 }
 
@@ -151,6 +321,11 @@ type AlertConfig struct {
 	EmailEnabled               bool              `json:"email_enabled"`
 	WebhookEnabled             bool              `json:"webhook_enabled"`
 	TelegramEnabled            bool              `json:"telegram_enabled"`
+	SlackEnabled               bool              `json:"slack_enabled"`
+	DiscordEnabled             bool              `json:"discord_enabled"`
+	TeamsEnabled               bool              `json:"teams_enabled"`
+	PagerDutyEnabled           bool              `json:"pagerduty_enabled"`
+	OpsgenieEnabled            bool              `json:"opsgenie_enabled"`
 
 	// Email settings
 	SMTPServer                 string            `json:"smtp_server"`
@@ -164,11 +339,27 @@ type AlertConfig struct {
 	WebhookURL                 string            `json:"webhook_url"`
 	WebhookHeaders            map[string]string `json:"webhook_headers"`
 	WebhookTimeout            int               `json:"webhook_timeout"`
+	// WebhookSecret signs webhook bodies with HMAC-SHA256, empty disables signing
+	WebhookSecret             string            `json:"webhook_secret"`
 
 	// Telegram settings
 	TelegramBotToken          string            `json:"telegram_bot_token"`
 	TelegramChatID            string            `json:"telegram_chat_id"`
 
+	// Slack settings: bot token and channel, or an incoming webhook URL
+	SlackWebhookURL           string            `json:"slack_webhook_url"`
+	SlackBotToken             string            `json:"slack_bot_token"`
+	SlackChannel              string            `json:"slack_channel"`
+
+	// Discord and Microsoft Teams webhooks
+	DiscordWebhookURL         string            `json:"discord_webhook_url"`
+	TeamsWebhookURL           string            `json:"teams_webhook_url"`
+
+	// PagerDuty Events API v2 routing key and Opsgenie API integration
+	PagerDutyRoutingKey       string            `json:"pagerduty_routing_key"`
+	OpsgenieAPIKey            string            `json:"opsgenie_api_key"`
+	OpsgenieRegion            string            `json:"opsgenie_region"`
+
 	// Alert conditions
 	AlertOnDown               bool              `json:"alert_on_down"`
 	AlertOnUp                 bool              `json:"alert_on_up"`
@@ -177,6 +368,24 @@ type AlertConfig struct {
 	AlertOnStatusCodeChange   bool              `json:"alert_on_status_code_change"`
 	AlertOnResponseTimeThreshold bool           `json:"alert_on_response_time_threshold"`
 	ResponseTimeThreshold     int               `json:"response_time_threshold"`
+	// ResponseTimeChecks is the number of consecutive slow or anomalous checks that raise an alert
+	ResponseTimeChecks        int               `json:"response_time_checks"`
+	// AlertOnResponseTimeAnomaly alerts on response times ResponseTimeAnomalySigmas
+	// robust standard deviations above the baseline of the site for the hour
+	AlertOnResponseTimeAnomaly bool             `json:"alert_on_response_time_anomaly"`
+	ResponseTimeAnomalySigmas float64           `json:"response_time_anomaly_sigmas"`
+	AlertOnContentChange      bool              `json:"alert_on_content_change"`
+	AlertOnRedirectChange     bool              `json:"alert_on_redirect_change"`
+	AlertOnKeywordLost        bool              `json:"alert_on_keyword_lost"`
+	AlertOnPacketLoss         bool              `json:"alert_on_packet_loss"`
+	PacketLossThreshold       float64           `json:"packet_loss_threshold"`
+
+	// Escalation policy for alerts of sites without their own policy
+	EscalationPolicyID        int               `json:"escalation_policy_id"`
+
+	// On-call schedule whose current person receives email/Telegram/webhook
+	// alerts instead of EmailTo/TelegramChatID/WebhookURL
+	OnCallScheduleID          int               `json:"oncall_schedule_id"`
 
 	CreatedAt                 time.Time         `json:"created_at"`
 	UpdatedAt                 time.Time         `json:"updated_at"`
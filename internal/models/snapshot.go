@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// ContentSnapshot is the content of a site stored when its hash changed.
+// Content holds the decompressed page and is only set for a single snapshot.
+type ContentSnapshot struct {
+	ID           int       `json:"id"`
+	SiteID       int       `json:"site_id"`
+	ContentHash  string    `json:"content_hash"`
+	StatusCode   int       `json:"status_code"`
+	Size         int       `json:"size"`
+	OriginalSize int       `json:"original_size"`
+	Truncated    bool      `json:"truncated"`
+	CreatedAt    time.Time `json:"created_at"`
+	Content      string    `json:"content,omitempty"`
+	// Data is the gzip compressed content as stored
+	Data []byte `json:"-"`
+}
+
+// ContentSnapshotDiff is a unified diff between two snapshots of a site.
+type ContentSnapshotDiff struct {
+	From ContentSnapshot `json:"from"`
+	To   ContentSnapshot `json:"to"`
+	Diff string          `json:"diff"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// SSLCertificate is the certificate of a site as seen by its last check,
+// together with the expiry alert settings of the site.
+type SSLCertificate struct {
+	SiteID     int             `json:"site_id"`
+	SiteURL    string          `json:"site_url"`
+	SiteStatus string          `json:"site_status"`
+	Expiry     time.Time       `json:"ssl_expiry"`
+	Issuer     string          `json:"ssl_issuer"`
+	Enabled    bool            `json:"enabled"`
+	CheckSSL   bool            `json:"check_ssl"`
+	AlertDays  int             `json:"ssl_alert_days"`
+	Thresholds string          `json:"ssl_alert_thresholds"`
+	Alert      *SSLExpiryAlert `json:"alert,omitempty"`
+}
+
+// SSLExpiryAlert records the smallest expiry threshold alerted for a
+// certificate; Threshold is 0 once the certificate has expired.
+type SSLExpiryAlert struct {
+	SiteID    int       `json:"site_id"`
+	SSLExpiry time.Time `json:"ssl_expiry"`
+	Threshold int       `json:"threshold"`
+	AlertedAt time.Time `json:"alerted_at"`
+}
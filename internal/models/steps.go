@@ -0,0 +1,70 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HTTPStep is one request of a multi-step (synthetic transaction) check.
+// URL, headers and body may reference captured variables as {{name}}.
+type HTTPStep struct {
+	Name       string            `json:"name"`
+	Method     string            `json:"method"`
+	URL        string            `json:"url"`
+	Headers    map[string]string `json:"headers"`
+	Body       string            `json:"body"`
+	Captures   []StepCapture     `json:"captures"`
+	Assertions []StepAssertion   `json:"assertions"`
+}
+
+// StepCapture stores a value of the step response in a variable.
+// Source is one of json (JSONPath), regex (first group), header or cookie.
+type StepCapture struct {
+	Name       string `json:"name"`
+	Source     string `json:"source"`
+	Expression string `json:"expression"`
+}
+
+// StepAssertion checks a value of the step response. Source is one of
+// status, body, json, regex, header, cookie or response_time.
+type StepAssertion struct {
+	Source     string `json:"source"`
+	Expression string `json:"expression"`
+	Operator   string `json:"operator"`
+	Value      string `json:"value"`
+}
+
+// StepResult is the outcome of one executed step.
+type StepResult struct {
+	Name         string `json:"name"`
+	Method       string `json:"method"`
+	URL          string `json:"url"`
+	StatusCode   int    `json:"status_code"`
+	ResponseTime int64  `json:"response_time_ms"`
+	Passed       bool   `json:"passed"`
+	Error        string `json:"error,omitempty"`
+}
+
+// ValidateSteps checks the capture and assertion sources of the steps.
+func ValidateSteps(steps []HTTPStep) error {
+	for i, step := range steps {
+		for _, capture := range step.Captures {
+			switch strings.ToLower(capture.Source) {
+			case "json", "regex", "header", "cookie":
+			default:
+				return fmt.Errorf("step %d: unsupported capture source %q", i+1, capture.Source)
+			}
+			if capture.Name == "" || capture.Expression == "" {
+				return fmt.Errorf("step %d: capture requires name and expression", i+1)
+			}
+		}
+		for _, assertion := range step.Assertions {
+			switch strings.ToLower(assertion.Source) {
+			case "status", "body", "json", "header", "cookie", "response_time", "regex":
+			default:
+				return fmt.Errorf("step %d: unsupported assertion source %q", i+1, assertion.Source)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+const (
+	TemplateFormatText = "text"
+	TemplateFormatHTML = "html"
+)
+
+// AlertTemplate replaces the built-in message of a channel. Templates with an
+// empty AlertType apply to every alert type without its own template.
+type AlertTemplate struct {
+	ID        int       `json:"id"`
+	Channel   string    `json:"channel"`
+	AlertType string    `json:"alert_type"`
+	Format    string    `json:"format"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// compareValues applies an assertion operator to an actual and an expected
+// value. Values that both parse as numbers are compared numerically.
+func compareValues(actual, operator, expected string) (bool, error) {
+	actualNum, actualErr := strconv.ParseFloat(strings.TrimSpace(actual), 64)
+	expectedNum, expectedErr := strconv.ParseFloat(strings.TrimSpace(expected), 64)
+	numeric := actualErr == nil && expectedErr == nil
+
+	switch operator {
+	case "==", "=", "equals":
+		if numeric {
+			return actualNum == expectedNum, nil
+		}
+		return actual == expected, nil
+	case "!=", "not_equals":
+		if numeric {
+			return actualNum != expectedNum, nil
+		}
+		return actual != expected, nil
+	case "<", "<=", ">", ">=":
+		if !numeric {
+			return false, fmt.Errorf("cannot compare %q %s %q: not numbers", actual, operator, expected)
+		}
+		switch operator {
+		case "<":
+			return actualNum < expectedNum, nil
+		case "<=":
+			return actualNum <= expectedNum, nil
+		case ">":
+			return actualNum > expectedNum, nil
+		default:
+			return actualNum >= expectedNum, nil
+		}
+	case "contains":
+		return strings.Contains(actual, expected), nil
+	case "not_contains":
+		return !strings.Contains(actual, expected), nil
+	case "matches":
+		re, err := regexp.Compile(expected)
+		if err != nil {
+			return false, fmt.Errorf("invalid regex %q: %v", expected, err)
+		}
+		return re.MatchString(actual), nil
+	}
+
+	return false, fmt.Errorf("unsupported operator %q", operator)
+}
@@ -2,10 +2,9 @@ package monitor
 
 import (
 	"context"
-	"crypto/ecdsa"
-	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -17,14 +16,12 @@ import (
 
 	"ping-tower/internal/models"
 	"ping-tower/internal/database"
-	"ping-tower/internal/notifications"
 	_ "github.com/lib/pq"
 )
 
 type Checker struct {
-	db           *database.DB
-	client       *http.Client
-	alertManager *notifications.AlertManager
+	db     *database.DB
+	client *http.Client
 }
 
 type CheckResult struct {
@@ -41,6 +38,8 @@ type CheckResult struct {
 	TLSTime       int64     `json:"tls_time"`
 	TTFB          int64     `json:"ttfb"`
 	ContentHash   string    `json:"content_hash"`
+	// Content is the body the hash was computed from, with the content masks applied
+	Content       []byte    `json:"-"`
 	RedirectCount int       `json:"redirect_count"`
 	FinalURL      string    `json:"final_url"`
 	Headers       map[string]string `json:"headers"`
@@ -55,6 +54,30 @@ type CheckResult struct {
 	ContentType   string    `json:"content_type"`
 	CacheControl  string    `json:"cache_control"`
 	Cookies       []string  `json:"cookies"`
+
+	PacketsSent     int     `json:"packets_sent"`
+	PacketsReceived int     `json:"packets_received"`
+	PacketLoss      float64 `json:"packet_loss_percent"`
+	RTTMin          float64 `json:"rtt_min_ms"`
+	RTTAvg          float64 `json:"rtt_avg_ms"`
+	RTTMax          float64 `json:"rtt_max_ms"`
+	Jitter          float64 `json:"jitter_ms"`
+
+	DNSAnswers []string `json:"dns_answers"`
+
+	TLS *TLSInfo `json:"tls,omitempty"`
+
+	Steps      []models.StepResult `json:"steps,omitempty"`
+	FailedStep string              `json:"failed_step,omitempty"`
+
+	RawStatus            string `json:"raw_status"`
+	Pending              bool   `json:"pending"`
+	Retried              bool   `json:"retried"`
+	ConsecutiveFailures  int    `json:"consecutive_failures"`
+	ConsecutiveSuccesses int    `json:"consecutive_successes"`
+
+	Maintenance       bool   `json:"maintenance"`
+	MaintenanceWindow string `json:"maintenance_window,omitempty"`
 }
 
 var DefaultSiteConfig = models.SiteConfig{
@@ -97,36 +120,10 @@ func NewChecker(db *database.DB, interval time.Duration) *Checker {
 
 	return &Checker{
 		db:           db,
-		client:       client,
-		alertManager: nil, // Will be set externally
+		client: client,
 	}
 }
 
-func NewCheckerWithAlerts(db *database.DB, interval time.Duration, alertManager *notifications.AlertManager) *Checker {
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-		Transport: &http.Transport{
-			DialContext: (&net.Dialer{
-				Timeout: 10 * time.Second,
-			}).DialContext,
-			TLSHandshakeTimeout: 10 * time.Second,
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: false,
-			},
-		},
-	}
-
-	return &Checker{
-		db:           db,
-		client:       client,
-		alertManager: alertManager,
-	}
-}
-
-func (c *Checker) SetAlertManager(alertManager *notifications.AlertManager) {
-	c.alertManager = alertManager
-}
-
 func (c *Checker) CheckAllSitesScheduled() error {
 	log.Println("📅 Запуск запланированной проверки всех сайтов...")
 	
@@ -225,7 +222,7 @@ func (c *Checker) checkAllSites() {
 		}
 		
 		result := c.checkSiteWithConfig(site.URL, config)
-		c.updateSiteStatus(&site, result)
+		c.updateSiteStatus(&site, config, &result)
 		c.saveCheckHistory(site.ID, result)
 
 		if MetricsRecorder != nil {
@@ -242,9 +239,50 @@ func (c *Checker) CheckSiteWithConfig(siteURL string, config *models.SiteConfig)
 	return c.checkSiteWithConfig(siteURL, config)
 }
 
+// Retry delays of failed checks in seconds. Sites are checked one after
+// another, so a retry holds up the checks of every other site and must stay
+// short.
+const (
+	DefaultRetryDelay = 5
+	MaxRetryDelay     = 30
+)
+
+// checkSiteWithConfig runs the check and, if enabled, retries a failed one
+// once after a short delay before the failure counts.
 func (c *Checker) checkSiteWithConfig(siteURL string, config *models.SiteConfig) CheckResult {
-	log.Printf("🌐 Проверка с конфигурацией: %s (таймаут: %ds, ожидаемый статус: %d)", 
-		siteURL, config.Timeout, config.ExpectedStatus)
+	result := c.performCheck(siteURL, config)
+
+	if result.Status == "down" && config.RetryOnFailure {
+		delay := time.Duration(min(config.RetryDelay, MaxRetryDelay)) * time.Second
+		if delay <= 0 {
+			delay = DefaultRetryDelay * time.Second
+		}
+		log.Printf("🔁 Повторная проверка %s через %v: %s", siteURL, delay, result.Error)
+		time.Sleep(delay)
+
+		result = c.performCheck(siteURL, config)
+		result.Retried = true
+	}
+
+	return result
+}
+
+func (c *Checker) performCheck(siteURL string, config *models.SiteConfig) CheckResult {
+	switch config.GetEffectiveCheckType(siteURL) {
+	case models.CheckTypeTCP:
+		return c.checkTCP(siteURL, config)
+	case models.CheckTypeICMP:
+		return c.checkICMP(siteURL, config)
+	case models.CheckTypeDNS:
+		return c.checkDNS(siteURL, config)
+	case models.CheckTypeTLS:
+		return c.checkTLS(siteURL, config)
+	case models.CheckTypeMultiStep:
+		return c.checkMultiStep(siteURL, config)
+	}
+
+	log.Printf("🌐 Проверка с конфигурацией: %s (таймаут: %ds, ожидаемый статус: %s)", 
+		siteURL, config.Timeout, describeExpectedStatus(config.ExpectedStatus, config.ExpectedStatusCodes))
 	
 	start := time.Now()
 	result := CheckResult{
@@ -373,7 +411,17 @@ func (c *Checker) checkSiteWithConfig(siteURL string, config *models.SiteConfig)
 		}
 	}
 
-	req, err := http.NewRequest("GET", siteURL, nil)
+	method := strings.ToUpper(strings.TrimSpace(config.Method))
+	if method == "" {
+		method = "GET"
+	}
+
+	var requestBody io.Reader
+	if config.RequestBody != "" {
+		requestBody = strings.NewReader(config.RequestBody)
+	}
+
+	req, err := http.NewRequest(method, siteURL, requestBody)
 	if err != nil {
 		result.Error = fmt.Sprintf("Invalid request: %v", err)
 		return result
@@ -383,6 +431,10 @@ func (c *Checker) checkSiteWithConfig(siteURL string, config *models.SiteConfig)
 		req.Header.Set("User-Agent", config.UserAgent)
 	}
 
+	if config.RequestContentType != "" {
+		req.Header.Set("Content-Type", config.RequestContentType)
+	}
+
 	for key, value := range config.Headers {
 		if strValue, ok := value.(string); ok {
 			req.Header.Set(key, strValue)
@@ -398,6 +450,7 @@ func (c *Checker) checkSiteWithConfig(siteURL string, config *models.SiteConfig)
 			result.SSLKeyLength = sslDetails.KeyLength
 			result.SSLAlgorithm = sslDetails.Algorithm
 			result.SSLIssuer = sslDetails.Issuer
+			result.TLS = sslDetails.TLS
 		}
 	}
 
@@ -437,11 +490,13 @@ func (c *Checker) checkSiteWithConfig(siteURL string, config *models.SiteConfig)
 		result.FinalURL = resp.Request.URL.String()
 	}
 
-	statusValid := false
-	if config.ExpectedStatus == 0 {
-		statusValid = resp.StatusCode >= 200 && resp.StatusCode < 400
-	} else {
-		statusValid = resp.StatusCode == config.ExpectedStatus
+	statusValid := statusAccepted(resp.StatusCode, config.ExpectedStatus, config.ExpectedStatusCodes)
+
+	if statusValid && strings.TrimSpace(config.HeaderAssertions) != "" {
+		if failures := evaluateHeaderAssertions(resp.Header, config.HeaderAssertions); len(failures) > 0 {
+			statusValid = false
+			result.Error = "Header assertions failed: " + strings.Join(failures, "; ")
+		}
 	}
 
 	bodyBytes, err := io.ReadAll(resp.Body)
@@ -449,8 +504,18 @@ func (c *Checker) checkSiteWithConfig(siteURL string, config *models.SiteConfig)
 		result.ContentLength = int64(len(bodyBytes))
 		
 		if config.CollectContentHash {
-			hash := sha256.Sum256(bodyBytes)
+			content := bodyBytes
+			if strings.TrimSpace(config.ContentMasks) != "" {
+				masks, err := parseContentMasks(config.ContentMasks)
+				if err != nil {
+					log.Printf("⚠️ Неверные маски контента для %s: %v", siteURL, err)
+				} else {
+					content = maskContent(bodyBytes, masks)
+				}
+			}
+			hash := sha256.Sum256(content)
 			result.ContentHash = fmt.Sprintf("%x", hash[:8])
+			result.Content = content
 		}
 		
 		if config.CheckKeywords != "" || config.AvoidKeywords != "" {
@@ -485,6 +550,13 @@ func (c *Checker) checkSiteWithConfig(siteURL string, config *models.SiteConfig)
 				}
 			}
 		}
+
+		if strings.TrimSpace(config.JSONAssertions) != "" {
+			if failures := evaluateJSONAssertions(bodyBytes, config.JSONAssertions); len(failures) > 0 {
+				statusValid = false
+				result.Error = "JSON assertions failed: " + strings.Join(failures, "; ")
+			}
+		}
 	}
 
 	if statusValid {
@@ -493,7 +565,7 @@ func (c *Checker) checkSiteWithConfig(siteURL string, config *models.SiteConfig)
 	} else {
 		result.Status = "down"
 		if result.Error == "" {
-			result.Error = fmt.Sprintf("Unexpected status: %d (expected: %d)", resp.StatusCode, config.ExpectedStatus)
+			result.Error = fmt.Sprintf("Unexpected status: %d (expected: %s)", resp.StatusCode, describeExpectedStatus(config.ExpectedStatus, config.ExpectedStatusCodes))
 		}
 		log.Printf("❌ Сайт %s недоступен: %s", siteURL, result.Error)
 	}
@@ -505,6 +577,7 @@ type SSLDetails struct {
 	KeyLength int
 	Algorithm string
 	Issuer    string
+	TLS       *TLSInfo
 }
 
 func (c *Checker) checkSSLDetailed(siteURL string) (bool, *time.Time, SSLDetails) {
@@ -543,18 +616,11 @@ func (c *Checker) checkSSLDetailed(siteURL string) (bool, *time.Time, SSLDetails
 	cert := certs[0]
 	now := time.Now()
 	
-	if cert.PublicKey != nil {
-		switch pub := cert.PublicKey.(type) {
-		case *rsa.PublicKey:
-			details.KeyLength = pub.N.BitLen()
-			details.Algorithm = "RSA"
-		case *ecdsa.PublicKey:
-			details.KeyLength = pub.Params().BitSize
-			details.Algorithm = "ECDSA"
-		}
-	}
-	
+	details.Algorithm, details.KeyLength = certKeyInfo(cert)
 	details.Issuer = cert.Issuer.CommonName
+
+	tlsInfo := inspectTLS(conn.ConnectionState(), u.Hostname())
+	details.TLS = &tlsInfo
 	
 	log.Printf("🔍 SSL детали для %s: алгоритм %s, длина ключа %d бит, издатель %s", 
 		siteURL, details.Algorithm, details.KeyLength, details.Issuer)
@@ -584,22 +650,79 @@ func (c *Checker) findKeywords(content string) []string {
 	return keywords
 }
 
-func (c *Checker) UpdateSiteStatus(site *Site, result CheckResult) {
+func (c *Checker) UpdateSiteStatus(site *Site, config *models.SiteConfig, result *CheckResult) {
 	modelSite := &models.Site{ID: site.ID, URL: site.URL}
-	c.updateSiteStatus(modelSite, result)
+	c.updateSiteStatus(modelSite, config, result)
 }
 
-func (c *Checker) updateSiteStatus(site *models.Site, result CheckResult) {
+// updateSiteStatus stores the check result. The raw status only becomes the
+// site status after FailThreshold consecutive failures (or RecoverThreshold
+// consecutive successes); until then the site keeps its status and the
+// transition is reported as pending. result.Status is replaced with the
+// confirmed status, the raw one is kept in result.RawStatus.
+func (c *Checker) updateSiteStatus(site *models.Site, config *models.SiteConfig, result *CheckResult) {
 	log.Printf("💾 Обновляем детальный статус сайта %s: %s", site.URL, result.Status)
 
 	// Получаем предыдущий статус сайта для сравнения
-	var previousStatus string
-	statusQuery := `SELECT COALESCE(status, '') FROM sites WHERE id = $1`
-	err := c.db.QueryRow(statusQuery, site.ID).Scan(&previousStatus)
+	var previousStatus, previousPending string
+	var failures, successes int
+	statusQuery := `SELECT COALESCE(status, ''), COALESCE(pending_status, ''),
+                    COALESCE(consecutive_failures, 0), COALESCE(consecutive_successes, 0)
+                    FROM sites WHERE id = $1`
+	err := c.db.QueryRow(statusQuery, site.ID).Scan(&previousStatus, &previousPending, &failures, &successes)
 	if err != nil && err.Error() != "sql: no rows in result set" {
 		log.Printf("❌ Ошибка получения предыдущего статуса сайта %s: %v", site.URL, err)
 	}
 
+	failThreshold, recoverThreshold := 1, 1
+	if config != nil {
+		if config.FailThreshold > 1 {
+			failThreshold = config.FailThreshold
+		}
+		if config.RecoverThreshold > 1 {
+			recoverThreshold = config.RecoverThreshold
+		}
+	}
+
+	rawStatus := result.Status
+	if rawStatus == "up" {
+		successes++
+		failures = 0
+	} else {
+		failures++
+		successes = 0
+	}
+
+	confirmedStatus := rawStatus
+	switch {
+	case previousStatus == "up" && rawStatus != "up" && failures < failThreshold:
+		confirmedStatus = previousStatus
+	case previousStatus == "down" && rawStatus == "up" && successes < recoverThreshold:
+		confirmedStatus = previousStatus
+	}
+
+	pendingStatus := ""
+	if confirmedStatus != rawStatus {
+		pendingStatus = rawStatus
+		log.Printf("⏳ Сайт %s: %s ожидает подтверждения (неудач подряд: %d/%d, успехов подряд: %d/%d)",
+			site.URL, rawStatus, failures, failThreshold, successes, recoverThreshold)
+	}
+
+	result.RawStatus = rawStatus
+	result.Status = confirmedStatus
+	result.Pending = pendingStatus != ""
+	result.ConsecutiveFailures = failures
+	result.ConsecutiveSuccesses = successes
+
+	// Проверки во время обслуживания сохраняются, но не учитываются в аптайме
+	if MaintenanceLookup != nil {
+		if window := MaintenanceLookup(site.ID, config); window != "" {
+			result.Maintenance = true
+			result.MaintenanceWindow = window
+			log.Printf("🛠️ Сайт %s на обслуживании (%s)", site.URL, window)
+		}
+	}
+
 	query := `UPDATE sites SET
                 status = $1::varchar,
                 status_code = $2,
@@ -623,8 +746,11 @@ func (c *Checker) updateSiteStatus(site *models.Site, result CheckResult) {
                 ssl_algorithm = $20,
                 ssl_issuer = $21,
                 last_checked = CURRENT_TIMESTAMP,
-                total_checks = COALESCE(total_checks, 0) + 1,
-                successful_checks = COALESCE(successful_checks, 0) + CASE WHEN $1::varchar = 'up' THEN 1 ELSE 0 END
+                total_checks = COALESCE(total_checks, 0) + CASE WHEN $27::boolean THEN 0 ELSE 1 END,
+                successful_checks = COALESCE(successful_checks, 0) + CASE WHEN $27::boolean THEN 0 WHEN $23::varchar = 'up' THEN 1 ELSE 0 END,
+                pending_status = $24,
+                consecutive_failures = $25,
+                consecutive_successes = $26
               WHERE id = $22`
 
 	_, err = c.db.Exec(query,
@@ -634,7 +760,7 @@ func (c *Checker) updateSiteStatus(site *models.Site, result CheckResult) {
 		result.ContentHash, result.RedirectCount, result.FinalURL,
 		result.ServerType, result.PoweredBy, result.ContentType, result.CacheControl,
 		result.SSLKeyLength, result.SSLAlgorithm, result.SSLIssuer,
-		site.ID)
+		site.ID, rawStatus, pendingStatus, failures, successes, result.Maintenance)
 
 	if err != nil {
 		log.Printf("❌ Ошибка обновления детального статуса сайта %s: %v", site.URL, err)
@@ -643,75 +769,12 @@ func (c *Checker) updateSiteStatus(site *models.Site, result CheckResult) {
 
 	log.Printf("✅ Детальный статус сайта %s успешно обновлен", site.URL)
 
-	// Отправляем алерт если статус изменился и настроен AlertManager
-	log.Printf("🔍 DEBUG: Проверка алертов для %s: alertManager=%v, previousStatus='%s', currentStatus='%s'",
-		site.URL, c.alertManager != nil, previousStatus, result.Status)
-
-	if c.alertManager != nil && previousStatus != "" && previousStatus != result.Status {
-		log.Printf("🚨 ОТПРАВЛЯЕМ АЛЕРТ для %s: %s -> %s", site.URL, previousStatus, result.Status)
-		go c.sendStatusChangeAlert(site, previousStatus, result)
-	} else if c.alertManager == nil {
-		log.Printf("❌ AlertManager не настроен для %s", site.URL)
-	} else if previousStatus == "" {
-		log.Printf("ℹ️ Первая проверка сайта %s, предыдущий статус неизвестен", site.URL)
-	} else if previousStatus == result.Status {
-		log.Printf("ℹ️ Статус сайта %s не изменился: %s", site.URL, result.Status)
+	if SiteCheckRecorded != nil {
+		SiteCheckRecorded(site.ID, site.URL, *result)
 	}
-}
-
-func (c *Checker) sendStatusChangeAlert(site *models.Site, previousStatus string, result CheckResult) {
-	defer func() {
-		if r := recover(); r != nil {
-			log.Printf("❌ Паника при отправке алерта для %s: %v", site.URL, r)
-		}
-	}()
 
-	// Определяем тип алерта
-	alertType := "status_change"
-	if result.Status == "down" && previousStatus == "up" {
-		alertType = "site_down"
-		log.Printf("🚨 Сайт %s перешел в состояние DOWN (с %s на %s)", site.URL, previousStatus, result.Status)
-	} else if result.Status == "up" && previousStatus == "down" {
-		alertType = "site_up"
-		log.Printf("✅ Сайт %s восстановлен (с %s на %s)", site.URL, previousStatus, result.Status)
-	} else {
-		log.Printf("🔄 Статус сайта %s изменился: %s -> %s", site.URL, previousStatus, result.Status)
-	}
-
-	// Конвертируем CheckResult в формат для notifications
-	notificationResult := notifications.CheckResult{
-		Status:        result.Status,
-		StatusCode:    result.StatusCode,
-		ResponseTime:  result.ResponseTime,
-		ContentLength: result.ContentLength,
-		SSLValid:      result.SSLValid,
-		SSLExpiry:     result.SSLExpiry,
-		Error:         result.Error,
-		DNSTime:       result.DNSTime,
-		ConnectTime:   result.ConnectTime,
-		TLSTime:       result.TLSTime,
-		TTFB:          result.TTFB,
-		ContentHash:   result.ContentHash,
-		RedirectCount: result.RedirectCount,
-		FinalURL:      result.FinalURL,
-		Headers:       result.Headers,
-		Keywords:      result.Keywords,
-		SSLKeyLength:  result.SSLKeyLength,
-		SSLAlgorithm:  result.SSLAlgorithm,
-		SSLIssuer:     result.SSLIssuer,
-		ServerType:    result.ServerType,
-		PoweredBy:     result.PoweredBy,
-		ContentType:   result.ContentType,
-		CacheControl:  result.CacheControl,
-		Cookies:       result.Cookies,
-	}
-
-	// Отправляем алерт
-	err := c.alertManager.SendAlert(site.ID, site.URL, notificationResult, alertType)
-	if err != nil {
-		log.Printf("❌ Ошибка отправки алерта для %s: %v", site.URL, err)
-	} else {
-		log.Printf("📧 Алерт отправлен для %s (тип: %s)", site.URL, alertType)
+	if SiteStatusUpdated != nil && (previousStatus != confirmedStatus || previousPending != pendingStatus) {
+		SiteStatusUpdated(site.ID, site.URL, *result)
 	}
 }
 
@@ -720,10 +783,22 @@ func (c *Checker) SaveCheckHistory(siteID int, result CheckResult) {
 }
 
 func (c *Checker) saveCheckHistory(siteID int, result CheckResult) {
-	query := `INSERT INTO site_history (site_id, status, status_code, response_time, error, checked_at) 
-              VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)`
+	var stepsJSON []byte
+	if len(result.Steps) > 0 {
+		stepsJSON, _ = json.Marshal(result.Steps)
+	}
+
+	query := `INSERT INTO site_history (site_id, status, status_code, response_time, error, failed_step, step_results, maintenance, checked_at) 
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CURRENT_TIMESTAMP)`
 
-	_, err := c.db.Exec(query, siteID, result.Status, result.StatusCode, result.ResponseTime, result.Error)
+	// История хранит фактический результат проверки, а не подтвержденный статус
+	status := result.Status
+	if result.RawStatus != "" {
+		status = result.RawStatus
+	}
+
+	_, err := c.db.Exec(query, siteID, status, result.StatusCode, result.ResponseTime, result.Error,
+		result.FailedStep, stepsJSON, result.Maintenance)
 	if err != nil {
 		log.Printf("❌ Ошибка сохранения истории проверки для сайта ID %d: %v", siteID, err)
 	} else {
@@ -823,13 +898,25 @@ func (c *Checker) checkSite(siteURL string, siteID int) CheckResult {
 	result := c.checkSiteWithConfig(siteURL, siteConfig)
 	
 	site := &models.Site{ID: siteID, URL: siteURL}
-	c.updateSiteStatus(site, result)
+	c.updateSiteStatus(site, siteConfig, &result)
 	c.saveCheckHistory(siteID, result)
 	
 	return result
 }
 
 var NotifySiteChecked func(string, CheckResult)
+
+// SiteStatusUpdated is called when the confirmed status of a site or its
+// pending transition changes.
+var SiteStatusUpdated func(siteID int, siteURL string, result CheckResult)
+
+// SiteCheckRecorded is called after every stored check with the confirmed
+// status; site alerts are raised from it by the alert engine.
+var SiteCheckRecorded func(siteID int, siteURL string, result CheckResult)
+
+// MaintenanceLookup returns the name of the maintenance window the site is
+// in, or an empty string. config may be nil.
+var MaintenanceLookup func(siteID int, config *models.SiteConfig) string
 var MetricsRecorder func(int, string, CheckResult, string)
 
 func CreateSiteMonitoringJob(siteID int, siteURL string, checker *Checker) func() error {
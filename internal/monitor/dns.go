@@ -0,0 +1,261 @@
+package monitor
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"ping-tower/internal/models"
+)
+
+func (c *Checker) checkDNS(siteURL string, config *models.SiteConfig) CheckResult {
+	result := CheckResult{
+		Status:     "down",
+		Headers:    make(map[string]string),
+		Keywords:   []string{},
+		Cookies:    []string{},
+		DNSAnswers: []string{},
+	}
+
+	name, err := config.GetTargetHost(siteURL)
+	if err != nil || name == "" {
+		result.Error = fmt.Sprintf("Invalid target: %v", err)
+		return result
+	}
+
+	recordType := strings.ToUpper(config.DNSRecordType)
+	if recordType == "" {
+		recordType = "A"
+	}
+
+	timeout := time.Duration(config.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	resolver, resolverAddr, err := newDNSResolver(config.DNSResolver, timeout)
+	if err != nil {
+		result.Error = fmt.Sprintf("Invalid resolver: %v", err)
+		return result
+	}
+	result.FinalURL = resolverAddr
+
+	log.Printf("🔍 DNS проверка: %s %s (резолвер: %s)", recordType, name, resolverAddr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	answers, err := lookupDNSRecords(ctx, resolver, recordType, name)
+	result.ResponseTime = time.Since(start).Milliseconds()
+	result.DNSTime = result.ResponseTime
+
+	if err != nil {
+		result.Error = fmt.Sprintf("DNS lookup failed: %v", err)
+		log.Printf("❌ DNS %s %s: %v", recordType, name, err)
+		return result
+	}
+
+	answers = normalizeDNSValues(answers, recordType)
+	result.DNSAnswers = answers
+	result.ContentLength = int64(len(answers))
+	if config.CollectContentHash {
+		result.ContentHash = fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(answers, "\n"))))
+	}
+
+	if len(answers) == 0 {
+		result.Error = fmt.Sprintf("No %s records for %s", recordType, name)
+		log.Printf("❌ DNS %s", result.Error)
+		return result
+	}
+
+	expected := parseDNSExpected(config.DNSExpected, recordType)
+	if len(expected) > 0 {
+		compared := answers
+		if recordType == "MX" && !mxHasPreference(expected) {
+			compared = mxHosts(answers)
+		}
+
+		missing, unexpected := diffDNSValues(expected, compared)
+		if len(missing) > 0 || len(unexpected) > 0 {
+			result.Error = formatDNSDiff(recordType, name, missing, unexpected)
+			log.Printf("❌ DNS drift: %s", result.Error)
+			return result
+		}
+	}
+
+	result.Status = "up"
+	log.Printf("✅ DNS %s %s: %s (%dмс)", recordType, name, strings.Join(answers, ", "), result.ResponseTime)
+
+	return result
+}
+
+// newDNSResolver returns a resolver bound to the configured server, or the
+// system resolver when no server is configured.
+func newDNSResolver(server string, timeout time.Duration) (*net.Resolver, string, error) {
+	server = strings.TrimSpace(server)
+	if server == "" {
+		return net.DefaultResolver, "system", nil
+	}
+
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(strings.Trim(server, "[]"), "53")
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, server)
+		},
+	}
+
+	return resolver, server, nil
+}
+
+func lookupDNSRecords(ctx context.Context, resolver *net.Resolver, recordType, name string) ([]string, error) {
+	var answers []string
+
+	switch recordType {
+	case "A", "AAAA":
+		network := "ip4"
+		if recordType == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := resolver.LookupIP(ctx, network, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			answers = append(answers, ip.String())
+		}
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, cname)
+	case "MX":
+		records, err := resolver.LookupMX(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range records {
+			answers = append(answers, fmt.Sprintf("%d %s", mx.Pref, mx.Host))
+		}
+	case "TXT":
+		records, err := resolver.LookupTXT(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, records...)
+	case "NS":
+		records, err := resolver.LookupNS(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range records {
+			answers = append(answers, ns.Host)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported record type %s", recordType)
+	}
+
+	return answers, nil
+}
+
+// parseDNSExpected splits the expected answers. Values are separated by new
+// lines, other record types may also use commas (TXT values often contain them).
+func parseDNSExpected(expected, recordType string) []string {
+	var values []string
+	for _, line := range strings.Split(expected, "\n") {
+		if recordType == "TXT" {
+			values = append(values, line)
+			continue
+		}
+		values = append(values, strings.Split(line, ",")...)
+	}
+	return normalizeDNSValues(values, recordType)
+}
+
+// normalizeDNSValues trims, lowercases host names, drops trailing dots and
+// returns a sorted set.
+func normalizeDNSValues(values []string, recordType string) []string {
+	seen := make(map[string]bool)
+	var normalized []string
+
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if recordType != "TXT" {
+			v = strings.ToLower(strings.Join(strings.Fields(v), " "))
+			v = strings.TrimSuffix(v, ".")
+			if ip := net.ParseIP(v); ip != nil {
+				v = ip.String()
+			}
+		}
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		normalized = append(normalized, v)
+	}
+
+	sort.Strings(normalized)
+	return normalized
+}
+
+func mxHasPreference(values []string) bool {
+	for _, v := range values {
+		if strings.Contains(v, " ") {
+			return true
+		}
+	}
+	return false
+}
+
+func mxHosts(values []string) []string {
+	hosts := make([]string, 0, len(values))
+	for _, v := range values {
+		fields := strings.Fields(v)
+		hosts = append(hosts, fields[len(fields)-1])
+	}
+	return normalizeDNSValues(hosts, "MX")
+}
+
+func diffDNSValues(expected, actual []string) (missing, unexpected []string) {
+	actualSet := make(map[string]bool, len(actual))
+	for _, v := range actual {
+		actualSet[v] = true
+	}
+	expectedSet := make(map[string]bool, len(expected))
+	for _, v := range expected {
+		expectedSet[v] = true
+		if !actualSet[v] {
+			missing = append(missing, v)
+		}
+	}
+	for _, v := range actual {
+		if !expectedSet[v] {
+			unexpected = append(unexpected, v)
+		}
+	}
+	return missing, unexpected
+}
+
+// formatDNSDiff renders the drift as a diff: "-" for expected records that are
+// gone, "+" for records that are not expected.
+func formatDNSDiff(recordType, name string, missing, unexpected []string) string {
+	var lines []string
+	for _, v := range missing {
+		lines = append(lines, "- "+v)
+	}
+	for _, v := range unexpected {
+		lines = append(lines, "+ "+v)
+	}
+	return fmt.Sprintf("DNS %s records for %s differ from expected:\n%s", recordType, name, strings.Join(lines, "\n"))
+}
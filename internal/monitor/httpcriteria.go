@@ -0,0 +1,186 @@
+package monitor
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// statusRange is an inclusive range of accepted HTTP status codes.
+type statusRange struct {
+	from int
+	to   int
+}
+
+// parseStatusCodes parses a list of codes and ranges like "200-299,301".
+func parseStatusCodes(spec string) ([]statusRange, error) {
+	var ranges []statusRange
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		from, to, isRange := strings.Cut(part, "-")
+		start, err := parseStatusCode(from)
+		if err != nil {
+			return nil, err
+		}
+		end := start
+		if isRange {
+			if end, err = parseStatusCode(to); err != nil {
+				return nil, err
+			}
+			if end < start {
+				return nil, fmt.Errorf("invalid status range %q", part)
+			}
+		}
+
+		ranges = append(ranges, statusRange{from: start, to: end})
+	}
+
+	return ranges, nil
+}
+
+func parseStatusCode(value string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || code < 100 || code > 599 {
+		return 0, fmt.Errorf("invalid status code %q", strings.TrimSpace(value))
+	}
+	return code, nil
+}
+
+// statusAccepted reports whether the status code satisfies the configured
+// success criteria. ExpectedStatusCodes takes precedence over ExpectedStatus;
+// with neither set any 2xx or 3xx code is accepted.
+func statusAccepted(code int, expectedStatus int, expectedCodes string) bool {
+	if strings.TrimSpace(expectedCodes) != "" {
+		ranges, err := parseStatusCodes(expectedCodes)
+		if err == nil && len(ranges) > 0 {
+			for _, r := range ranges {
+				if code >= r.from && code <= r.to {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	if expectedStatus == 0 {
+		return code >= 200 && code < 400
+	}
+	return code == expectedStatus
+}
+
+func describeExpectedStatus(expectedStatus int, expectedCodes string) string {
+	if strings.TrimSpace(expectedCodes) != "" {
+		return strings.TrimSpace(expectedCodes)
+	}
+	if expectedStatus == 0 {
+		return "200-399"
+	}
+	return strconv.Itoa(expectedStatus)
+}
+
+// headerAssertion is one line of SiteConfig.HeaderAssertions, e.g.
+// `Content-Type contains application/json` or `X-Cache exists`.
+type headerAssertion struct {
+	raw      string
+	name     string
+	operator string
+	value    string
+}
+
+func parseHeaderAssertions(text string) ([]headerAssertion, error) {
+	var assertions []headerAssertion
+
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		assertion, err := parseHeaderAssertion(line)
+		if err != nil {
+			return nil, fmt.Errorf("header assertion on line %d: %v", i+1, err)
+		}
+		assertions = append(assertions, assertion)
+	}
+
+	return assertions, nil
+}
+
+func parseHeaderAssertion(line string) (headerAssertion, error) {
+	assertion := headerAssertion{raw: line}
+
+	end := strings.IndexAny(line, " \t=!<>")
+	if end < 0 {
+		end = len(line)
+	}
+	assertion.name = strings.TrimSuffix(line[:end], ":")
+	if assertion.name == "" {
+		return assertion, fmt.Errorf("missing header name in %q", line)
+	}
+
+	var err error
+	assertion.operator, assertion.value, err = parseAssertionOperand(line, line[end:])
+	return assertion, err
+}
+
+// evaluateHeaderAssertions checks the response headers and returns a
+// description of every failed assertion.
+func evaluateHeaderAssertions(header http.Header, text string) []string {
+	assertions, err := parseHeaderAssertions(text)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var failures []string
+	for _, assertion := range assertions {
+		values := header.Values(assertion.name)
+		found := len(values) > 0
+		actual := strings.Join(values, ", ")
+
+		var ok bool
+		switch assertion.operator {
+		case "exists":
+			ok = found
+		case "not_exists":
+			ok = !found
+		default:
+			if !found {
+				failures = append(failures, fmt.Sprintf("%s (header missing)", assertion.raw))
+				continue
+			}
+			ok, err = compareValues(actual, assertion.operator, assertion.value)
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("%s (%v)", assertion.raw, err))
+				continue
+			}
+		}
+
+		if !ok {
+			if found {
+				failures = append(failures, fmt.Sprintf("%s (actual: %s)", assertion.raw, truncateValue(actual)))
+			} else {
+				failures = append(failures, assertion.raw)
+			}
+		}
+	}
+
+	return failures
+}
+
+// ValidateStatusCodes reports syntax errors in a status code list like "200-299,301".
+func ValidateStatusCodes(spec string) error {
+	_, err := parseStatusCodes(spec)
+	return err
+}
+
+// ValidateHeaderAssertions reports syntax errors in the configured header assertions.
+func ValidateHeaderAssertions(text string) error {
+	_, err := parseHeaderAssertions(text)
+	return err
+}
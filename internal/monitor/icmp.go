@@ -0,0 +1,319 @@
+package monitor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"ping-tower/internal/models"
+)
+
+const (
+	defaultPingCount    = 4
+	maxPingCount        = 20
+	defaultPingInterval = 1000 * time.Millisecond
+	minPingInterval     = 200 * time.Millisecond
+	icmpReplyTimeout    = 2 * time.Second
+	icmpPayloadSize     = 56
+
+	icmpv4EchoRequest = 8
+	icmpv4EchoReply   = 0
+	icmpv6EchoRequest = 128
+	icmpv6EchoReply   = 129
+)
+
+var icmpIDCounter uint32
+
+type pingSession struct {
+	conn net.PacketConn
+	dst  net.Addr
+	ip   net.IP
+	ipv6 bool
+	raw  bool
+	id   uint16
+}
+
+func (c *Checker) checkICMP(siteURL string, config *models.SiteConfig) CheckResult {
+	result := CheckResult{
+		Status:   "down",
+		Headers:  make(map[string]string),
+		Keywords: []string{},
+		Cookies:  []string{},
+	}
+
+	host, err := config.GetTargetHost(siteURL)
+	if err != nil {
+		result.Error = fmt.Sprintf("Invalid target: %v", err)
+		return result
+	}
+
+	timeout := time.Duration(config.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	count := config.PingCount
+	if count <= 0 {
+		count = defaultPingCount
+	}
+	if count > maxPingCount {
+		count = maxPingCount
+	}
+	interval := time.Duration(config.PingInterval) * time.Millisecond
+	if interval <= 0 {
+		interval = defaultPingInterval
+	}
+	if interval < minPingInterval {
+		interval = minPingInterval
+	}
+
+	start := time.Now()
+	deadline := start.Add(timeout)
+
+	dnsStart := time.Now()
+	ip, err := resolvePingTarget(host)
+	if err != nil {
+		result.Error = fmt.Sprintf("DNS lookup failed: %v", err)
+		result.ResponseTime = time.Since(start).Milliseconds()
+		return result
+	}
+	if config.CollectDNSTime {
+		result.DNSTime = time.Since(dnsStart).Milliseconds()
+	}
+	result.FinalURL = ip.String()
+
+	session, err := openPingSession(ip)
+	if err != nil {
+		result.Error = fmt.Sprintf("ICMP socket unavailable: %v", err)
+		result.ResponseTime = time.Since(start).Milliseconds()
+		log.Printf("❌ Не удалось открыть ICMP сокет для %s: %v", host, err)
+		return result
+	}
+	defer session.conn.Close()
+
+	log.Printf("📡 ICMP проверка: %s (%s, пакетов: %d, интервал: %v)", host, ip, count, interval)
+
+	var rtts []float64
+	sent := 0
+	for seq := 0; seq < count && time.Now().Before(deadline); seq++ {
+		if seq > 0 {
+			time.Sleep(interval)
+		}
+
+		replyDeadline := time.Now().Add(icmpReplyTimeout)
+		if replyDeadline.After(deadline) {
+			replyDeadline = deadline
+		}
+
+		rtt, err := session.ping(uint16(seq), replyDeadline)
+		sent++
+		if err != nil {
+			log.Printf("⚠️ ICMP %s seq=%d: %v", host, seq, err)
+			continue
+		}
+		rtts = append(rtts, float64(rtt.Microseconds())/1000)
+	}
+
+	result.PacketsSent = sent
+	result.PacketsReceived = len(rtts)
+	if sent > 0 {
+		result.PacketLoss = float64(sent-len(rtts)) / float64(sent) * 100
+	}
+	result.RTTMin, result.RTTAvg, result.RTTMax, result.Jitter = rttStats(rtts)
+	result.ResponseTime = int64(math.Round(result.RTTAvg))
+
+	if len(rtts) == 0 {
+		result.Error = fmt.Sprintf("Host unreachable: %d packets transmitted, 0 received", sent)
+		result.ResponseTime = time.Since(start).Milliseconds()
+		log.Printf("❌ ICMP %s недоступен: %s", host, result.Error)
+		return result
+	}
+
+	if config.MaxPacketLoss > 0 && result.PacketLoss > config.MaxPacketLoss {
+		result.Error = fmt.Sprintf("Packet loss %.1f%% exceeds %.1f%%", result.PacketLoss, config.MaxPacketLoss)
+		log.Printf("❌ ICMP %s: %s", host, result.Error)
+		return result
+	}
+
+	result.Status = "up"
+	log.Printf("✅ ICMP %s доступен (rtt min/avg/max = %.2f/%.2f/%.2f мс, jitter %.2f мс, потери %.1f%%)",
+		host, result.RTTMin, result.RTTAvg, result.RTTMax, result.Jitter, result.PacketLoss)
+
+	return result
+}
+
+// resolvePingTarget returns the first IPv4 address of the host, or an IPv6
+// address if the host has no IPv4 records.
+func resolvePingTarget(host string) (net.IP, error) {
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no addresses for %s", host)
+	}
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			return ip.To4(), nil
+		}
+	}
+	return ips[0], nil
+}
+
+// openPingSession prefers an unprivileged datagram socket and falls back to a
+// raw socket, which needs root or CAP_NET_RAW.
+func openPingSession(ip net.IP) (*pingSession, error) {
+	session := &pingSession{
+		ip:   ip,
+		ipv6: ip.To4() == nil,
+		id:   uint16(os.Getpid()) ^ uint16(atomic.AddUint32(&icmpIDCounter, 1)),
+	}
+
+	conn, dgramErr := listenICMPDatagram(session.ipv6)
+	if dgramErr == nil {
+		session.conn = conn
+		session.dst = &net.UDPAddr{IP: ip}
+		return session, nil
+	}
+
+	network, address := "ip4:icmp", "0.0.0.0"
+	if session.ipv6 {
+		network, address = "ip6:ipv6-icmp", "::"
+	}
+	conn, err := net.ListenPacket(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("datagram: %v; raw: %v", dgramErr, err)
+	}
+
+	session.conn = conn
+	session.dst = &net.IPAddr{IP: ip}
+	session.raw = true
+	return session, nil
+}
+
+// ping sends one echo request and waits for the matching reply.
+func (s *pingSession) ping(seq uint16, deadline time.Time) (time.Duration, error) {
+	packet := s.echoRequest(seq)
+
+	sentAt := time.Now()
+	if _, err := s.conn.WriteTo(packet, s.dst); err != nil {
+		return 0, fmt.Errorf("send failed: %w", err)
+	}
+
+	if err := s.conn.SetReadDeadline(deadline); err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				return 0, fmt.Errorf("request timed out")
+			}
+			return 0, err
+		}
+		if s.raw && !peerIP(peer).Equal(s.ip) {
+			continue
+		}
+		if s.isReply(buf[:n], seq) {
+			return time.Since(sentAt), nil
+		}
+	}
+}
+
+func (s *pingSession) echoRequest(seq uint16) []byte {
+	packet := make([]byte, 8+icmpPayloadSize)
+	packet[0] = icmpv4EchoRequest
+	if s.ipv6 {
+		packet[0] = icmpv6EchoRequest
+	}
+	binary.BigEndian.PutUint16(packet[4:], s.id)
+	binary.BigEndian.PutUint16(packet[6:], seq)
+	binary.BigEndian.PutUint64(packet[8:], uint64(time.Now().UnixNano()))
+
+	// The kernel fills in the ICMPv6 checksum, since it covers a pseudo header.
+	if !s.ipv6 {
+		binary.BigEndian.PutUint16(packet[2:], icmpChecksum(packet))
+	}
+	return packet
+}
+
+func (s *pingSession) isReply(data []byte, seq uint16) bool {
+	// Datagram sockets on Darwin deliver IPv4 replies with the IP header.
+	if !s.ipv6 && len(data) >= 20 && data[0]>>4 == 4 {
+		data = data[int(data[0]&0x0f)*4:]
+	}
+	if len(data) < 8 {
+		return false
+	}
+
+	replyType := byte(icmpv4EchoReply)
+	if s.ipv6 {
+		replyType = icmpv6EchoReply
+	}
+	if data[0] != replyType || binary.BigEndian.Uint16(data[6:]) != seq {
+		return false
+	}
+
+	// The kernel rewrites the identifier of datagram sockets and only hands us
+	// our own replies, raw sockets see every echo reply on the host.
+	return !s.raw || binary.BigEndian.Uint16(data[4:]) == s.id
+}
+
+func peerIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.IPAddr:
+		return a.IP
+	case *net.UDPAddr:
+		return a.IP
+	}
+	return nil
+}
+
+func icmpChecksum(data []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(data); i += 2 {
+		sum += uint32(data[i])<<8 | uint32(data[i+1])
+	}
+	if len(data)%2 == 1 {
+		sum += uint32(data[len(data)-1]) << 8
+	}
+	for sum>>16 != 0 {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
+
+// rttStats returns min/avg/max round trip times and jitter, the mean absolute
+// difference between consecutive round trip times.
+func rttStats(rtts []float64) (min, avg, max, jitter float64) {
+	if len(rtts) == 0 {
+		return 0, 0, 0, 0
+	}
+
+	min, max = rtts[0], rtts[0]
+	var sum, diffSum float64
+	for i, rtt := range rtts {
+		sum += rtt
+		if rtt < min {
+			min = rtt
+		}
+		if rtt > max {
+			max = rtt
+		}
+		if i > 0 {
+			diffSum += math.Abs(rtt - rtts[i-1])
+		}
+	}
+
+	avg = sum / float64(len(rtts))
+	if len(rtts) > 1 {
+		jitter = diffSum / float64(len(rtts)-1)
+	}
+	return min, avg, max, jitter
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package monitor
+
+import (
+	"errors"
+	"net"
+)
+
+// listenICMPDatagram is not available on this platform, raw sockets are used.
+func listenICMPDatagram(ipv6 bool) (net.PacketConn, error) {
+	return nil, errors.New("unprivileged ICMP sockets are not supported on this platform")
+}
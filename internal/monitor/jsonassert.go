@@ -0,0 +1,220 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonAssertion is one parsed line of SiteConfig.JSONAssertions, e.g.
+// `$.status == "ok"`, `$.db.latency_ms < 200` or `len($.queue) < 1000`.
+type jsonAssertion struct {
+	raw      string
+	path     string
+	length   bool
+	operator string
+	value    string
+}
+
+var assertionSymbols = []string{"==", "!=", "<=", ">=", "<", ">", "="}
+
+var assertionWords = []string{"not_contains", "contains", "matches", "not_exists", "exists"}
+
+// parseJSONAssertions splits the configured text into assertions, one per
+// line. Empty lines and lines starting with # are ignored.
+func parseJSONAssertions(text string) ([]jsonAssertion, error) {
+	var assertions []jsonAssertion
+
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		assertion, err := parseJSONAssertion(line)
+		if err != nil {
+			return nil, fmt.Errorf("JSON assertion on line %d: %v", i+1, err)
+		}
+		assertions = append(assertions, assertion)
+	}
+
+	return assertions, nil
+}
+
+func parseJSONAssertion(line string) (jsonAssertion, error) {
+	assertion := jsonAssertion{raw: line}
+	rest := line
+
+	if strings.HasPrefix(rest, "len(") {
+		end := strings.Index(rest, ")")
+		if end < 0 {
+			return assertion, fmt.Errorf("unclosed len( in %q", line)
+		}
+		assertion.length = true
+		assertion.path = strings.TrimSpace(rest[len("len("):end])
+		rest = rest[end+1:]
+	} else {
+		end := jsonPathEnd(rest)
+		assertion.path = rest[:end]
+		rest = rest[end:]
+	}
+
+	if !strings.HasPrefix(assertion.path, "$") {
+		return assertion, fmt.Errorf("JSONPath must start with $: %q", line)
+	}
+
+	var err error
+	assertion.operator, assertion.value, err = parseAssertionOperand(line, rest)
+	return assertion, err
+}
+
+// parseAssertionOperand parses the "operator value" tail of an assertion
+// line. exists and not_exists take no value.
+func parseAssertionOperand(line, rest string) (string, string, error) {
+	rest = strings.TrimSpace(rest)
+
+	operator := ""
+	for _, symbol := range assertionSymbols {
+		if strings.HasPrefix(rest, symbol) {
+			operator = symbol
+			break
+		}
+	}
+	if operator == "" {
+		for _, word := range assertionWords {
+			if rest == word || strings.HasPrefix(rest, word+" ") {
+				operator = word
+				break
+			}
+		}
+	}
+	if operator == "" {
+		return "", "", fmt.Errorf("missing operator in %q", line)
+	}
+
+	rest = strings.TrimSpace(rest[len(operator):])
+	if operator == "exists" || operator == "not_exists" {
+		if rest != "" {
+			return "", "", fmt.Errorf("%s takes no value in %q", operator, line)
+		}
+		return operator, "", nil
+	}
+	if rest == "" {
+		return "", "", fmt.Errorf("missing value in %q", line)
+	}
+
+	value, err := unquoteAssertionValue(rest)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid value in %q: %v", line, err)
+	}
+
+	return operator, value, nil
+}
+
+// jsonPathEnd returns the end of a JSONPath at the start of s: the first
+// operator character or space outside of brackets.
+func jsonPathEnd(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ' ', '\t', '=', '!', '<', '>':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+func unquoteAssertionValue(value string) (string, error) {
+	if len(value) >= 2 {
+		switch {
+		case value[0] == '"' && value[len(value)-1] == '"':
+			return strconv.Unquote(value)
+		case value[0] == '\'' && value[len(value)-1] == '\'':
+			return value[1 : len(value)-1], nil
+		}
+	}
+	return value, nil
+}
+
+// evaluate checks the assertion against a decoded JSON document and returns
+// the actual value it saw.
+func (a jsonAssertion) evaluate(document interface{}) (bool, string, error) {
+	value, found, err := evalJSONPath(document, a.path)
+	if err != nil {
+		return false, "", err
+	}
+
+	switch a.operator {
+	case "exists":
+		return found, "", nil
+	case "not_exists":
+		return !found, "", nil
+	}
+
+	if !found {
+		return false, "not found", nil
+	}
+
+	actual := jsonValueString(value)
+	if a.length {
+		switch v := value.(type) {
+		case []interface{}:
+			actual = strconv.Itoa(len(v))
+		case map[string]interface{}:
+			actual = strconv.Itoa(len(v))
+		case string:
+			actual = strconv.Itoa(utf8.RuneCountInString(v))
+		default:
+			return false, actual, fmt.Errorf("len() of %s is undefined", a.path)
+		}
+	}
+
+	ok, err := compareValues(actual, a.operator, a.value)
+	return ok, actual, err
+}
+
+// evaluateJSONAssertions runs all assertions against the body and returns a
+// description of every failed one.
+func evaluateJSONAssertions(body []byte, text string) []string {
+	assertions, err := parseJSONAssertions(text)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	if len(assertions) == 0 {
+		return nil
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return []string{fmt.Sprintf("response is not valid JSON: %v", err)}
+	}
+
+	var failures []string
+	for _, assertion := range assertions {
+		ok, actual, err := assertion.evaluate(document)
+		switch {
+		case err != nil:
+			failures = append(failures, fmt.Sprintf("%s (%v)", assertion.raw, err))
+		case !ok && actual != "":
+			failures = append(failures, fmt.Sprintf("%s (actual: %s)", assertion.raw, truncateValue(actual)))
+		case !ok:
+			failures = append(failures, assertion.raw)
+		}
+	}
+
+	return failures
+}
+
+// ValidateJSONAssertions reports syntax errors in the configured assertions.
+func ValidateJSONAssertions(text string) error {
+	_, err := parseJSONAssertions(text)
+	return err
+}
@@ -0,0 +1,104 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// evalJSONPath resolves a simple JSONPath expression against decoded JSON.
+// Supported syntax: $, .key, ['key'], ["key"], [index] and negative indexes.
+func evalJSONPath(document interface{}, path string) (interface{}, bool, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") {
+		return nil, false, fmt.Errorf("JSONPath must start with $: %q", path)
+	}
+
+	current := document
+	rest := path[1:]
+
+	for rest != "" {
+		var key string
+		index := 0
+		isIndex := false
+
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key = rest[:end]
+			rest = rest[end:]
+			if key == "" {
+				return nil, false, fmt.Errorf("empty key in JSONPath %q", path)
+			}
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, false, fmt.Errorf("unclosed bracket in JSONPath %q", path)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				key = inner[1 : len(inner)-1]
+			} else {
+				n, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, false, fmt.Errorf("invalid index %q in JSONPath %q", inner, path)
+				}
+				index = n
+				isIndex = true
+			}
+		default:
+			return nil, false, fmt.Errorf("unexpected %q in JSONPath %q", rest[0], path)
+		}
+
+		if isIndex {
+			array, ok := current.([]interface{})
+			if !ok {
+				return nil, false, nil
+			}
+			if index < 0 {
+				index += len(array)
+			}
+			if index < 0 || index >= len(array) {
+				return nil, false, nil
+			}
+			current = array[index]
+			continue
+		}
+
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false, nil
+		}
+		value, exists := object[key]
+		if !exists {
+			return nil, false, nil
+		}
+		current = value
+	}
+
+	return current, true, nil
+}
+
+// jsonValueString renders a JSON value the way users write it in assertions:
+// strings without quotes, numbers without exponent, objects as JSON.
+func jsonValueString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
@@ -0,0 +1,243 @@
+package monitor
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maskPlaceholder replaces masked content, so diffs still show where it was.
+const maskPlaceholder = "[masked]"
+
+// voidElements have no content; a matching element is masked as a whole.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+var (
+	startTagPattern   = regexp.MustCompile(`<([a-zA-Z][a-zA-Z0-9-]*)((?:[\s/][^>]*)?)>`)
+	attributePattern  = regexp.MustCompile(`([^\s=/>]+)(?:\s*=\s*("[^"]*"|'[^']*'|[^\s>]+))?`)
+	selectorPartRegex = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9-]*)?((?:[#.][a-zA-Z0-9_-]+|\[[^\]=]+(?:=[^\]]*)?\])*)$`)
+	selectorItemRegex = regexp.MustCompile(`[#.][a-zA-Z0-9_-]+|\[[^\]=]+(?:=[^\]]*)?\]`)
+)
+
+// contentMask hides a dynamic part of a page, e.g. a timestamp or a CSRF
+// token, from the content hash and snapshots.
+type contentMask struct {
+	pattern *regexp.Regexp
+	// selector is a simple CSS selector: tag, #id, .class and [attr] or
+	// [attr=value] in any combination, without combinators
+	tag     string
+	id      string
+	classes []string
+	attrs   map[string]*string
+}
+
+// parseContentMasks parses one mask per line: "regex:<pattern>" or a CSS
+// selector, optionally prefixed with "css:".
+func parseContentMasks(text string) ([]contentMask, error) {
+	var masks []contentMask
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if pattern, ok := strings.CutPrefix(line, "regex:"); ok {
+			re, err := regexp.Compile(strings.TrimSpace(pattern))
+			if err != nil {
+				return nil, fmt.Errorf("invalid mask %q: %v", line, err)
+			}
+			masks = append(masks, contentMask{pattern: re})
+			continue
+		}
+
+		mask, err := parseSelector(strings.TrimSpace(strings.TrimPrefix(line, "css:")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid mask %q: %v", line, err)
+		}
+		masks = append(masks, mask)
+	}
+	return masks, nil
+}
+
+func parseSelector(selector string) (contentMask, error) {
+	m := selectorPartRegex.FindStringSubmatch(selector)
+	if selector == "" || m == nil {
+		return contentMask{}, fmt.Errorf("unsupported selector, use tag, #id, .class and [attr=value]")
+	}
+
+	mask := contentMask{tag: strings.ToLower(m[1])}
+	for _, item := range selectorItemRegex.FindAllString(m[2], -1) {
+		switch item[0] {
+		case '#':
+			mask.id = item[1:]
+		case '.':
+			mask.classes = append(mask.classes, item[1:])
+		case '[':
+			name, value, hasValue := strings.Cut(item[1:len(item)-1], "=")
+			if mask.attrs == nil {
+				mask.attrs = make(map[string]*string)
+			}
+			if hasValue {
+				value = strings.Trim(value, `"'`)
+				mask.attrs[strings.ToLower(name)] = &value
+			} else {
+				mask.attrs[strings.ToLower(name)] = nil
+			}
+		}
+	}
+	return mask, nil
+}
+
+// matches reports whether a start tag matches the selector.
+func (m *contentMask) matches(tag string, attrs map[string]string) bool {
+	if m.tag != "" && m.tag != tag {
+		return false
+	}
+	if m.id != "" && attrs["id"] != m.id {
+		return false
+	}
+	if len(m.classes) > 0 {
+		classes := strings.Fields(attrs["class"])
+		for _, class := range m.classes {
+			found := false
+			for _, c := range classes {
+				if c == class {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	for name, value := range m.attrs {
+		actual, ok := attrs[name]
+		if !ok || (value != nil && actual != *value) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseAttributes(text string) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range attributePattern.FindAllStringSubmatch(text, -1) {
+		attrs[strings.ToLower(m[1])] = strings.Trim(m[2], `"'`)
+	}
+	return attrs
+}
+
+// maskContent replaces the content of elements matching the selector masks
+// and the matches of the regex masks with a placeholder. Elements keep their
+// tags; void elements and elements without a closing tag are replaced whole.
+func maskContent(body []byte, masks []contentMask) []byte {
+	var selectors []*contentMask
+	for i := range masks {
+		if masks[i].pattern == nil {
+			selectors = append(selectors, &masks[i])
+		}
+	}
+
+	if len(selectors) > 0 {
+		body = maskElements(body, selectors)
+	}
+
+	for _, mask := range masks {
+		if mask.pattern != nil {
+			body = mask.pattern.ReplaceAllLiteral(body, []byte(maskPlaceholder))
+		}
+	}
+	return body
+}
+
+func maskElements(body []byte, selectors []*contentMask) []byte {
+	var out bytes.Buffer
+	pos := 0
+	for _, loc := range startTagPattern.FindAllSubmatchIndex(body, -1) {
+		if loc[0] < pos {
+			// Внутри уже скрытого элемента
+			continue
+		}
+
+		tag := strings.ToLower(string(body[loc[2]:loc[3]]))
+		attrs := parseAttributes(string(body[loc[4]:loc[5]]))
+		matched := false
+		for _, selector := range selectors {
+			if selector.matches(tag, attrs) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		end := -1
+		if !voidElements[tag] && !bytes.HasSuffix(body[loc[0]:loc[1]], []byte("/>")) {
+			end = closingTag(body, loc[1], tag)
+		}
+
+		if end < 0 {
+			out.Write(body[pos:loc[0]])
+			out.WriteString(maskPlaceholder)
+			pos = loc[1]
+			continue
+		}
+
+		out.Write(body[pos:loc[1]])
+		out.WriteString(maskPlaceholder)
+		pos = end
+	}
+	out.Write(body[pos:])
+	return out.Bytes()
+}
+
+// closingTag returns the offset of the tag closing the element whose content
+// starts at from, or -1 if it is not closed.
+func closingTag(body []byte, from int, tag string) int {
+	open := []byte("<" + tag)
+	closing := []byte("</" + tag)
+
+	depth := 1
+	for i := from; i < len(body); i++ {
+		next := bytes.IndexByte(body[i:], '<')
+		if next < 0 {
+			return -1
+		}
+		i += next
+		switch {
+		case hasTagPrefix(body[i:], closing):
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case hasTagPrefix(body[i:], open):
+			depth++
+		}
+	}
+	return -1
+}
+
+// hasTagPrefix reports whether text starts with the tag, ignoring case, and
+// the tag name ends there, so <b> is not taken for <body>.
+func hasTagPrefix(text, tag []byte) bool {
+	if len(text) < len(tag) || !bytes.EqualFold(text[:len(tag)], tag) {
+		return false
+	}
+	if len(text) == len(tag) {
+		return true
+	}
+	c := text[len(tag)]
+	return c == '>' || c == '/' || c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// ValidateContentMasks reports syntax errors in the configured content masks.
+func ValidateContentMasks(text string) error {
+	_, err := parseContentMasks(text)
+	return err
+}
@@ -0,0 +1,345 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"ping-tower/internal/models"
+)
+
+const maxStepBodySize = 10 << 20
+
+var stepVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// stepResponse is what captures and assertions of a step are evaluated against.
+type stepResponse struct {
+	resp         *http.Response
+	body         []byte
+	responseTime int64
+	jar          http.CookieJar
+
+	document    interface{}
+	documentErr error
+	decoded     bool
+}
+
+func (c *Checker) checkMultiStep(siteURL string, config *models.SiteConfig) CheckResult {
+	result := CheckResult{
+		Status:   "down",
+		Headers:  make(map[string]string),
+		Keywords: []string{},
+		Cookies:  []string{},
+		Steps:    []models.StepResult{},
+	}
+
+	if len(config.Steps) == 0 {
+		result.Error = "No steps configured"
+		return result
+	}
+
+	baseURL, err := url.Parse(siteURL)
+	if err != nil {
+		result.Error = fmt.Sprintf("Invalid URL: %v", err)
+		return result
+	}
+
+	timeout := time.Duration(config.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Timeout: timeout, Jar: jar}
+	if config.FollowRedirects {
+		maxRedirects := config.MaxRedirects
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return fmt.Errorf("too many redirects")
+			}
+			return nil
+		}
+	} else {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	variables := map[string]string{"site_url": siteURL}
+
+	log.Printf("🧭 Многошаговая проверка: %s (шагов: %d)", siteURL, len(config.Steps))
+
+	start := time.Now()
+	for i, step := range config.Steps {
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprintf("step %d", i+1)
+		}
+
+		stepResult, response, err := c.runStep(client, baseURL, step, variables, config)
+		stepResult.Name = name
+
+		if err == nil {
+			err = checkStepAssertions(step, response)
+		}
+		if err == nil {
+			err = applyStepCaptures(step, response, variables)
+		}
+
+		if response != nil {
+			result.StatusCode = response.resp.StatusCode
+			result.ContentLength = int64(len(response.body))
+			result.FinalURL = response.resp.Request.URL.String()
+		}
+
+		if err != nil {
+			stepResult.Error = err.Error()
+			result.Steps = append(result.Steps, stepResult)
+			result.FailedStep = name
+			result.Error = fmt.Sprintf("Step %d (%s) failed: %v", i+1, name, err)
+			result.ResponseTime = time.Since(start).Milliseconds()
+			log.Printf("❌ %s: %s", siteURL, result.Error)
+			return result
+		}
+
+		stepResult.Passed = true
+		result.Steps = append(result.Steps, stepResult)
+		log.Printf("✅ Шаг %d (%s): %d за %dмс", i+1, name, stepResult.StatusCode, stepResult.ResponseTime)
+	}
+
+	result.ResponseTime = time.Since(start).Milliseconds()
+	result.Status = "up"
+	log.Printf("✅ Многошаговая проверка %s пройдена за %dмс", siteURL, result.ResponseTime)
+
+	return result
+}
+
+func (c *Checker) runStep(client *http.Client, baseURL *url.URL, step models.HTTPStep, variables map[string]string, config *models.SiteConfig) (models.StepResult, *stepResponse, error) {
+	method := strings.ToUpper(strings.TrimSpace(step.Method))
+	if method == "" {
+		method = "GET"
+	}
+
+	stepResult := models.StepResult{Method: method}
+
+	target, err := baseURL.Parse(substituteVariables(step.URL, variables))
+	if err != nil {
+		return stepResult, nil, fmt.Errorf("invalid URL: %v", err)
+	}
+	stepResult.URL = target.String()
+
+	var body io.Reader
+	if step.Body != "" {
+		body = strings.NewReader(substituteVariables(step.Body, variables))
+	}
+
+	req, err := http.NewRequest(method, target.String(), body)
+	if err != nil {
+		return stepResult, nil, fmt.Errorf("invalid request: %v", err)
+	}
+
+	if config.UserAgent != "" {
+		req.Header.Set("User-Agent", config.UserAgent)
+	}
+	for key, value := range config.Headers {
+		if strValue, ok := value.(string); ok {
+			req.Header.Set(key, strValue)
+		}
+	}
+	for key, value := range step.Headers {
+		req.Header.Set(key, substituteVariables(value, variables))
+	}
+
+	requestStart := time.Now()
+	resp, err := client.Do(req)
+	stepResult.ResponseTime = time.Since(requestStart).Milliseconds()
+	if err != nil {
+		return stepResult, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxStepBodySize))
+	stepResult.ResponseTime = time.Since(requestStart).Milliseconds()
+	stepResult.StatusCode = resp.StatusCode
+	if err != nil {
+		return stepResult, nil, fmt.Errorf("failed to read body: %v", err)
+	}
+
+	return stepResult, &stepResponse{
+		resp:         resp,
+		body:         data,
+		responseTime: stepResult.ResponseTime,
+		jar:          client.Jar,
+	}, nil
+}
+
+func checkStepAssertions(step models.HTTPStep, response *stepResponse) error {
+	hasStatusAssertion := false
+
+	for _, assertion := range step.Assertions {
+		source := strings.ToLower(assertion.Source)
+		if source == "status" {
+			hasStatusAssertion = true
+		}
+
+		operator := assertion.Operator
+		if operator == "" {
+			operator = "=="
+		}
+
+		actual, found, err := response.value(source, assertion.Expression)
+		if err != nil {
+			return err
+		}
+
+		label := source
+		if assertion.Expression != "" {
+			label += " " + assertion.Expression
+		}
+
+		switch operator {
+		case "exists":
+			if !found {
+				return fmt.Errorf("assertion failed: %s does not exist", label)
+			}
+			continue
+		case "not_exists":
+			if found {
+				return fmt.Errorf("assertion failed: %s exists", label)
+			}
+			continue
+		}
+
+		if !found {
+			return fmt.Errorf("assertion failed: %s not found", label)
+		}
+
+		ok, err := compareValues(actual, operator, assertion.Value)
+		if err != nil {
+			return fmt.Errorf("assertion %s: %v", label, err)
+		}
+		if !ok {
+			return fmt.Errorf("assertion failed: %s %s %q (actual: %q)", label, operator, assertion.Value, truncateValue(actual))
+		}
+	}
+
+	if !hasStatusAssertion {
+		code := response.resp.StatusCode
+		if code < 200 || code >= 400 {
+			return fmt.Errorf("unexpected status: %d", code)
+		}
+	}
+
+	return nil
+}
+
+func applyStepCaptures(step models.HTTPStep, response *stepResponse, variables map[string]string) error {
+	for _, capture := range step.Captures {
+		if capture.Name == "" {
+			continue
+		}
+
+		value, found, err := response.value(strings.ToLower(capture.Source), capture.Expression)
+		if err != nil {
+			return fmt.Errorf("capture %s: %v", capture.Name, err)
+		}
+		if !found {
+			return fmt.Errorf("capture %s: %s %s not found", capture.Name, capture.Source, capture.Expression)
+		}
+		variables[capture.Name] = value
+	}
+
+	return nil
+}
+
+// value extracts a value of the response for captures and assertions.
+func (r *stepResponse) value(source, expression string) (string, bool, error) {
+	switch source {
+	case "status":
+		return strconv.Itoa(r.resp.StatusCode), true, nil
+	case "response_time":
+		return strconv.FormatInt(r.responseTime, 10), true, nil
+	case "body":
+		return string(r.body), true, nil
+	case "header":
+		values := r.resp.Header.Values(expression)
+		if len(values) == 0 {
+			return "", false, nil
+		}
+		return strings.Join(values, ", "), true, nil
+	case "cookie":
+		for _, cookie := range r.resp.Cookies() {
+			if cookie.Name == expression {
+				return cookie.Value, true, nil
+			}
+		}
+		if r.jar != nil {
+			for _, cookie := range r.jar.Cookies(r.resp.Request.URL) {
+				if cookie.Name == expression {
+					return cookie.Value, true, nil
+				}
+			}
+		}
+		return "", false, nil
+	case "regex":
+		re, err := regexp.Compile(expression)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid regex %q: %v", expression, err)
+		}
+		match := re.FindSubmatch(r.body)
+		if match == nil {
+			return "", false, nil
+		}
+		if len(match) > 1 {
+			return string(match[1]), true, nil
+		}
+		return string(match[0]), true, nil
+	case "json":
+		document, err := r.json()
+		if err != nil {
+			return "", false, err
+		}
+		value, found, err := evalJSONPath(document, expression)
+		if err != nil || !found {
+			return "", found, err
+		}
+		return jsonValueString(value), true, nil
+	}
+
+	return "", false, fmt.Errorf("unsupported source %q", source)
+}
+
+func (r *stepResponse) json() (interface{}, error) {
+	if !r.decoded {
+		r.decoded = true
+		if err := json.Unmarshal(r.body, &r.document); err != nil {
+			r.documentErr = fmt.Errorf("response is not valid JSON: %v", err)
+		}
+	}
+	return r.document, r.documentErr
+}
+
+func substituteVariables(text string, variables map[string]string) string {
+	return stepVariablePattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := stepVariablePattern.FindStringSubmatch(match)[1]
+		if value, ok := variables[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+func truncateValue(value string) string {
+	if len(value) > 100 {
+		return value[:100] + "..."
+	}
+	return value
+}
@@ -0,0 +1,149 @@
+package monitor
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"ping-tower/internal/models"
+)
+
+const maxBannerSize = 4096
+
+func (c *Checker) checkTCP(siteURL string, config *models.SiteConfig) CheckResult {
+	result := CheckResult{
+		Status:   "down",
+		Headers:  make(map[string]string),
+		Keywords: []string{},
+		Cookies:  []string{},
+	}
+
+	address, err := config.GetTargetAddress(siteURL, "")
+	if err != nil {
+		result.Error = fmt.Sprintf("Invalid target: %v", err)
+		return result
+	}
+	result.FinalURL = address
+
+	host, port, _ := net.SplitHostPort(address)
+
+	timeout := time.Duration(config.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	connectTimeout := time.Duration(config.ConnectTimeout) * time.Second
+	if connectTimeout <= 0 || connectTimeout > timeout {
+		connectTimeout = timeout
+	}
+
+	log.Printf("🔌 TCP проверка: %s (таймаут соединения: %v)", address, connectTimeout)
+
+	start := time.Now()
+
+	dnsStart := time.Now()
+	ips, err := net.LookupIP(host)
+	if err != nil || len(ips) == 0 {
+		result.Error = fmt.Sprintf("DNS lookup failed: %v", err)
+		result.ResponseTime = time.Since(start).Milliseconds()
+		return result
+	}
+	if config.CollectDNSTime {
+		result.DNSTime = time.Since(dnsStart).Milliseconds()
+	}
+
+	connectStart := time.Now()
+	dialer := &net.Dialer{Timeout: connectTimeout}
+	conn, err := dialer.Dial("tcp", net.JoinHostPort(ips[0].String(), port))
+	if err != nil {
+		result.Error = fmt.Sprintf("Connection failed: %v", err)
+		result.ResponseTime = time.Since(start).Milliseconds()
+		log.Printf("❌ TCP %s недоступен: %v", address, err)
+		return result
+	}
+	defer conn.Close()
+
+	result.ConnectTime = time.Since(connectStart).Milliseconds()
+	conn.SetDeadline(start.Add(timeout))
+
+	if config.TCPSend != "" {
+		if _, err := conn.Write([]byte(unescapePayload(config.TCPSend))); err != nil {
+			result.Error = fmt.Sprintf("Send failed: %v", err)
+			result.ResponseTime = time.Since(start).Milliseconds()
+			return result
+		}
+	}
+
+	if config.TCPExpect != "" {
+		expect := unescapePayload(config.TCPExpect)
+		banner, found := readBanner(conn, []byte(expect))
+
+		result.ContentLength = int64(len(banner))
+		if config.CollectTTFB {
+			result.TTFB = time.Since(connectStart).Milliseconds()
+		}
+		if config.CollectServerInfo {
+			result.ServerType = firstLine(banner)
+		}
+
+		if !found {
+			result.Error = fmt.Sprintf("Expected banner %q not received, got %q", expect, firstLine(banner))
+			result.ResponseTime = time.Since(start).Milliseconds()
+			log.Printf("❌ TCP %s: %s", address, result.Error)
+			return result
+		}
+		result.Keywords = append(result.Keywords, expect)
+	}
+
+	result.ResponseTime = time.Since(start).Milliseconds()
+	result.Status = "up"
+	log.Printf("✅ TCP %s доступен (соединение: %dмс)", address, result.ConnectTime)
+
+	return result
+}
+
+// readBanner reads from the connection until the expected bytes show up, the
+// banner size limit is reached or the connection deadline fires.
+func readBanner(conn net.Conn, expect []byte) ([]byte, bool) {
+	var banner []byte
+	buf := make([]byte, 1024)
+
+	for len(banner) < maxBannerSize {
+		n, err := conn.Read(buf)
+		banner = append(banner, buf[:n]...)
+		if bytes.Contains(banner, expect) {
+			return banner, true
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	return banner, false
+}
+
+// unescapePayload turns escape sequences such as \r\n typed in the config into
+// the bytes they stand for. Values that are not valid Go escapes are sent as is.
+func unescapePayload(payload string) string {
+	if !strings.Contains(payload, `\`) {
+		return payload
+	}
+	if unquoted, err := strconv.Unquote(`"` + strings.ReplaceAll(payload, `"`, `\"`) + `"`); err == nil {
+		return unquoted
+	}
+	return payload
+}
+
+func firstLine(data []byte) string {
+	line := string(data)
+	if i := strings.IndexAny(line, "\r\n"); i >= 0 {
+		line = line[:i]
+	}
+	if len(line) > 255 {
+		line = line[:255]
+	}
+	return line
+}
@@ -0,0 +1,393 @@
+package monitor
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"ping-tower/internal/models"
+)
+
+// TLSInfo describes the negotiated TLS session and the presented certificate
+// chain. It is filled by the TLS check and by the SSL details of HTTPS checks.
+type TLSInfo struct {
+	Version       string   `json:"version"`
+	Cipher        string   `json:"cipher"`
+	Subject       string   `json:"subject"`
+	SANs          []string `json:"sans"`
+	Chain         []string `json:"chain"`
+	HostnameMatch bool     `json:"hostname_match"`
+	ChainValid    bool     `json:"chain_valid"`
+	ChainError    string   `json:"chain_error,omitempty"`
+	OCSPStapled   bool     `json:"ocsp_stapled"`
+	OCSPStatus    string   `json:"ocsp_status"`
+	Fingerprint   string   `json:"fingerprint_sha256"`
+}
+
+func (c *Checker) checkTLS(siteURL string, config *models.SiteConfig) CheckResult {
+	result := CheckResult{
+		Status:   "down",
+		Headers:  make(map[string]string),
+		Keywords: []string{},
+		Cookies:  []string{},
+	}
+
+	address, err := config.GetTargetAddress(siteURL, "443")
+	if err != nil {
+		result.Error = fmt.Sprintf("Invalid target: %v", err)
+		return result
+	}
+	result.FinalURL = address
+
+	host, _, _ := net.SplitHostPort(address)
+	serverName := config.TLSServerName
+	if serverName == "" {
+		serverName = host
+	}
+
+	timeout := time.Duration(config.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	connectTimeout := time.Duration(config.ConnectTimeout) * time.Second
+	if connectTimeout <= 0 || connectTimeout > timeout {
+		connectTimeout = timeout
+	}
+
+	log.Printf("🔐 TLS проверка: %s (SNI: %s, STARTTLS: %s)", address, serverName, config.TLSStartTLS)
+
+	start := time.Now()
+
+	dnsStart := time.Now()
+	ips, err := net.LookupIP(host)
+	if err != nil || len(ips) == 0 {
+		result.Error = fmt.Sprintf("DNS lookup failed: %v", err)
+		result.ResponseTime = time.Since(start).Milliseconds()
+		return result
+	}
+	if config.CollectDNSTime {
+		result.DNSTime = time.Since(dnsStart).Milliseconds()
+	}
+
+	_, port, _ := net.SplitHostPort(address)
+	connectStart := time.Now()
+	rawConn, err := (&net.Dialer{Timeout: connectTimeout}).Dial("tcp", net.JoinHostPort(ips[0].String(), port))
+	if err != nil {
+		result.Error = fmt.Sprintf("Connection failed: %v", err)
+		result.ResponseTime = time.Since(start).Milliseconds()
+		return result
+	}
+	defer rawConn.Close()
+	result.ConnectTime = time.Since(connectStart).Milliseconds()
+	rawConn.SetDeadline(start.Add(timeout))
+
+	if config.TLSStartTLS != "" {
+		if err := startTLS(rawConn, config.TLSStartTLS); err != nil {
+			result.Error = fmt.Sprintf("STARTTLS (%s) failed: %v", config.TLSStartTLS, err)
+			result.ResponseTime = time.Since(start).Milliseconds()
+			log.Printf("❌ TLS %s: %s", address, result.Error)
+			return result
+		}
+	}
+
+	// The chain is verified by inspectTLS so that details of broken
+	// certificates are still recorded.
+	tlsStart := time.Now()
+	conn := tls.Client(rawConn, &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+	})
+	if err := conn.Handshake(); err != nil {
+		result.Error = fmt.Sprintf("TLS handshake failed: %v", err)
+		result.ResponseTime = time.Since(start).Milliseconds()
+		log.Printf("❌ TLS %s: %s", address, result.Error)
+		return result
+	}
+	result.TLSTime = time.Since(tlsStart).Milliseconds()
+	result.ResponseTime = time.Since(start).Milliseconds()
+
+	state := conn.ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		result.Error = "Server presented no certificate"
+		return result
+	}
+
+	leaf := state.PeerCertificates[0]
+	info := inspectTLS(state, serverName)
+	result.TLS = &info
+	result.SSLExpiry = &leaf.NotAfter
+	result.SSLIssuer = leaf.Issuer.CommonName
+	result.SSLAlgorithm, result.SSLKeyLength = certKeyInfo(leaf)
+
+	now := time.Now()
+	result.SSLValid = !now.After(leaf.NotAfter) && !now.Before(leaf.NotBefore) &&
+		info.HostnameMatch && (info.ChainValid || config.TLSAllowUntrusted)
+
+	switch {
+	case now.After(leaf.NotAfter):
+		result.Error = fmt.Sprintf("Certificate expired on %s", leaf.NotAfter.Format("2006-01-02"))
+	case now.Before(leaf.NotBefore):
+		result.Error = fmt.Sprintf("Certificate is not valid before %s", leaf.NotBefore.Format("2006-01-02"))
+	case !info.HostnameMatch:
+		result.Error = fmt.Sprintf("Certificate does not match hostname %s (SANs: %s)", serverName, strings.Join(info.SANs, ", "))
+	case !info.ChainValid && !config.TLSAllowUntrusted:
+		result.Error = fmt.Sprintf("Certificate chain is not trusted: %s", info.ChainError)
+	case info.OCSPStatus == "revoked":
+		result.Error = "Certificate is revoked (stapled OCSP response)"
+	}
+
+	if result.Error != "" {
+		log.Printf("❌ TLS %s: %s", address, result.Error)
+		return result
+	}
+
+	result.Status = "up"
+	log.Printf("✅ TLS %s: %s %s, сертификат до %s, отпечаток %s",
+		address, info.Version, info.Cipher, leaf.NotAfter.Format("2006-01-02"), info.Fingerprint)
+
+	return result
+}
+
+// inspectTLS collects the session parameters and verifies the presented chain
+// against the system roots and the expected host name.
+func inspectTLS(state tls.ConnectionState, serverName string) TLSInfo {
+	info := TLSInfo{
+		Version:     tls.VersionName(state.Version),
+		Cipher:      tls.CipherSuiteName(state.CipherSuite),
+		SANs:        []string{},
+		Chain:       []string{},
+		OCSPStapled: len(state.OCSPResponse) > 0,
+		OCSPStatus:  "none",
+	}
+
+	if len(state.PeerCertificates) == 0 {
+		return info
+	}
+
+	leaf := state.PeerCertificates[0]
+	info.Subject = certName(leaf.Subject)
+	info.Fingerprint = certFingerprint(leaf)
+
+	info.SANs = append(info.SANs, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		info.SANs = append(info.SANs, ip.String())
+	}
+
+	for _, cert := range state.PeerCertificates {
+		info.Chain = append(info.Chain, fmt.Sprintf("%s | issuer: %s | expires: %s | sha256: %s",
+			certName(cert.Subject), certName(cert.Issuer),
+			cert.NotAfter.Format("2006-01-02"), certFingerprint(cert)))
+	}
+
+	info.HostnameMatch = leaf.VerifyHostname(serverName) == nil
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{Intermediates: intermediates}); err != nil {
+		info.ChainError = err.Error()
+	} else {
+		info.ChainValid = true
+	}
+
+	if info.OCSPStapled {
+		info.OCSPStatus = parseOCSPStatus(state.OCSPResponse)
+	}
+
+	return info
+}
+
+func certName(name pkix.Name) string {
+	if name.CommonName != "" {
+		return name.CommonName
+	}
+	return name.String()
+}
+
+func certFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+func certKeyInfo(cert *x509.Certificate) (string, int) {
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return "RSA", pub.N.BitLen()
+	case *ecdsa.PublicKey:
+		return "ECDSA", pub.Params().BitSize
+	case ed25519.PublicKey:
+		return "Ed25519", 256
+	}
+	return "", 0
+}
+
+// startTLS upgrades a plain connection for protocols that negotiate TLS
+// in-band. Supported protocols: smtp, imap, postgres.
+func startTLS(conn net.Conn, protocol string) error {
+	reader := bufio.NewReader(conn)
+
+	switch strings.ToLower(protocol) {
+	case "smtp":
+		if _, err := readSMTPReply(reader, "220"); err != nil {
+			return fmt.Errorf("greeting: %w", err)
+		}
+		if _, err := io.WriteString(conn, "EHLO ping-tower\r\n"); err != nil {
+			return err
+		}
+		ehlo, err := readSMTPReply(reader, "250")
+		if err != nil {
+			return fmt.Errorf("EHLO: %w", err)
+		}
+		if !strings.Contains(strings.ToUpper(ehlo), "STARTTLS") {
+			return fmt.Errorf("server does not advertise STARTTLS")
+		}
+		if _, err := io.WriteString(conn, "STARTTLS\r\n"); err != nil {
+			return err
+		}
+		if _, err := readSMTPReply(reader, "220"); err != nil {
+			return fmt.Errorf("STARTTLS: %w", err)
+		}
+	case "imap":
+		greeting, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("greeting: %w", err)
+		}
+		if !strings.HasPrefix(greeting, "* OK") {
+			return fmt.Errorf("unexpected greeting %q", strings.TrimSpace(greeting))
+		}
+		if _, err := io.WriteString(conn, "a001 STARTTLS\r\n"); err != nil {
+			return err
+		}
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(line, "a001 ") {
+				if !strings.HasPrefix(line, "a001 OK") {
+					return fmt.Errorf("server refused: %s", strings.TrimSpace(line))
+				}
+				break
+			}
+		}
+	case "postgres":
+		// SSLRequest: length 8 followed by the magic code 80877103.
+		request := make([]byte, 8)
+		binary.BigEndian.PutUint32(request[0:], 8)
+		binary.BigEndian.PutUint32(request[4:], 80877103)
+		if _, err := conn.Write(request); err != nil {
+			return err
+		}
+		answer, err := reader.ReadByte()
+		if err != nil {
+			return err
+		}
+		if answer != 'S' {
+			return fmt.Errorf("server does not support SSL")
+		}
+	default:
+		return fmt.Errorf("unsupported protocol %q", protocol)
+	}
+
+	if reader.Buffered() > 0 {
+		return fmt.Errorf("unexpected data before TLS handshake")
+	}
+	return nil
+}
+
+// readSMTPReply reads a (possibly multi-line) SMTP reply and checks its code.
+func readSMTPReply(reader *bufio.Reader, code string) (string, error) {
+	var reply strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return reply.String(), err
+		}
+		reply.WriteString(line)
+		if !strings.HasPrefix(line, code) {
+			return reply.String(), fmt.Errorf("unexpected reply %q", strings.TrimSpace(line))
+		}
+		if len(line) < 4 || line[3] != '-' {
+			return reply.String(), nil
+		}
+	}
+}
+
+// parseOCSPStatus extracts the certificate status of the first single response
+// of a stapled OCSP response (RFC 6960). The signature is not verified, the
+// value is informational.
+func parseOCSPStatus(der []byte) string {
+	var resp struct {
+		Status        asn1.Enumerated
+		ResponseBytes struct {
+			ResponseType asn1.ObjectIdentifier
+			Response     []byte
+		} `asn1:"explicit,tag:0,optional"`
+	}
+	if _, err := asn1.Unmarshal(der, &resp); err != nil || resp.Status != 0 {
+		return "invalid"
+	}
+
+	// BasicOCSPResponse: tbsResponseData, signatureAlgorithm, signature, certs.
+	var basic, tbs asn1.RawValue
+	if _, err := asn1.Unmarshal(resp.ResponseBytes.Response, &basic); err != nil {
+		return "invalid"
+	}
+	if _, err := asn1.Unmarshal(basic.Bytes, &tbs); err != nil {
+		return "invalid"
+	}
+
+	// ResponseData: [0] version (optional), responderID ([1] or [2]),
+	// producedAt, responses. The responses are the first universal SEQUENCE.
+	rest := tbs.Bytes
+	var field asn1.RawValue
+	found := false
+	for i := 0; i < 4 && len(rest) > 0 && !found; i++ {
+		var err error
+		if rest, err = asn1.Unmarshal(rest, &field); err != nil {
+			return "invalid"
+		}
+		found = field.Class == asn1.ClassUniversal && field.Tag == asn1.TagSequence
+	}
+	if !found {
+		return "invalid"
+	}
+
+	// First SingleResponse: certID, certStatus.
+	var single asn1.RawValue
+	if _, err := asn1.Unmarshal(field.Bytes, &single); err != nil {
+		return "invalid"
+	}
+	var certID, certStatus asn1.RawValue
+	remaining, err := asn1.Unmarshal(single.Bytes, &certID)
+	if err != nil {
+		return "invalid"
+	}
+	if _, err := asn1.Unmarshal(remaining, &certStatus); err != nil {
+		return "invalid"
+	}
+
+	switch certStatus.Tag {
+	case 0:
+		return "good"
+	case 1:
+		return "revoked"
+	default:
+		return "unknown"
+	}
+}
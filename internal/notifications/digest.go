@@ -0,0 +1,146 @@
+package notifications
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// AlertTypeDigest is the alert type of a digest combining several alerts.
+const AlertTypeDigest = "digest"
+
+// digestFactLimit keeps digests within the field limits of rich messages.
+const digestFactLimit = 20
+
+// NewDigest combines the alerts into one digest alert.
+func NewDigest(alerts []AlertData) AlertData {
+	var sites []string
+	seen := map[string]bool{}
+	for _, alert := range alerts {
+		if !seen[alert.SiteURL] {
+			seen[alert.SiteURL] = true
+			sites = append(sites, alert.SiteURL)
+		}
+	}
+
+	return AlertData{
+		SiteURL:   truncate(strings.Join(sites, ", "), 200),
+		Status:    "down",
+		Timestamp: time.Now(),
+		AlertType: AlertTypeDigest,
+		Digest:    alerts,
+		EventID:   newEventID(),
+	}
+}
+
+// SendDigest delivers alerts held by Batch as one message. The alerts must
+// share their routes, e.g. belong to sites with the same alert
+// configurations; every route gets the alerts its conditions accept. A single
+// alert is sent as it is. Incident management tools get every alert on its
+// own, so that their incidents stay per site.
+func (am *AlertManager) SendDigest(alerts []AlertData) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	var routes []Route
+	if SiteRoutes != nil && alerts[0].SiteID > 0 {
+		routes = SiteRoutes(alerts[0].SiteID)
+	}
+
+	if len(routes) == 0 {
+		if !am.config.Enabled {
+			return nil
+		}
+		return am.routed(alerts[0]).deliverDigest(alerts)
+	}
+
+	var errors []string
+	for _, route := range routes {
+		var accepted []AlertData
+		for _, alert := range alerts {
+			if route.Accepts == nil || alert.CheckResult == nil || route.Accepts(alert.AlertType, *alert.CheckResult) {
+				accepted = append(accepted, alert)
+			}
+		}
+		if len(accepted) == 0 {
+			continue
+		}
+
+		manager := NewRouteManager(route)
+
+		if err := manager.deliverDigest(accepted); err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", route.Name, err))
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("failed to send some digests: %s", strings.Join(errors, "; "))
+	}
+
+	return nil
+}
+
+func (am *AlertManager) deliverDigest(alerts []AlertData) error {
+	channels := am.EnabledChannels()
+	if len(alerts) == 1 {
+		return am.deliver(alerts[0], channels)
+	}
+
+	var digestChannels []string
+	var errors []string
+	for _, channel := range channels {
+		if channel != ChannelPagerDuty && channel != ChannelOpsgenie {
+			digestChannels = append(digestChannels, channel)
+			continue
+		}
+		for _, alert := range alerts {
+			if err := am.deliver(alert, []string{channel}); err != nil {
+				errors = append(errors, err.Error())
+			}
+		}
+	}
+
+	log.Printf("📋 Дайджест из %d оповещений отправляется: %s", len(alerts), strings.Join(digestChannels, ", "))
+	if err := am.deliver(NewDigest(alerts), digestChannels); err != nil {
+		errors = append(errors, err.Error())
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, "; "))
+	}
+	return nil
+}
+
+// digestFacts lists the alerts of a digest, one per site and type.
+func digestFacts(alertData AlertData) []alertFact {
+	var facts []alertFact
+	for i, alert := range alertData.Digest {
+		if i == digestFactLimit {
+			facts = append(facts, alertFact{"…", fmt.Sprintf("%d more", len(alertData.Digest)-i)})
+			break
+		}
+
+		value := alert.AlertType
+		if alert.Error != "" {
+			value += ": " + alert.Error
+		}
+		facts = append(facts, alertFact{alert.SiteURL, truncate(value, 200)})
+	}
+	return facts
+}
+
+// digestText lists the alerts of a digest for plain text messages, "" for
+// other alerts.
+func digestText(alertData AlertData) string {
+	if alertData.AlertType != AlertTypeDigest {
+		return ""
+	}
+
+	text := "\n\n📋 Alerts:\n"
+	for _, fact := range digestFacts(alertData) {
+		text += fmt.Sprintf("• %s - %s\n", fact.Name, fact.Value)
+	}
+	return text
+}
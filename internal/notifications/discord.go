@@ -0,0 +1,84 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	discordColorDown     = 0xE01E5A
+	discordColorUp       = 0x2EB67D
+	discordColorDegraded = 0xECB22E
+)
+
+func (am *AlertManager) sendDiscordAlert(alertData AlertData) error {
+	if am.config.Discord.WebhookURL == "" {
+		return fmt.Errorf("discord webhook URL not configured")
+	}
+
+	jsonData, err := json.Marshal(discordMessage(alertData))
+	if err != nil {
+		return fmt.Errorf("failed to marshal discord payload: %v", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(am.config.Discord.WebhookURL, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to send discord webhook: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Discord отвечает 204 No Content, с ?wait=true - 200
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("discord webhook returned status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// discordMessage builds a webhook message with a single embed.
+func discordMessage(alertData AlertData) map[string]interface{} {
+	level, emoji, title := alertHeadline(alertData)
+	color := discordColorDegraded
+	switch level {
+	case levelDown:
+		color = discordColorDown
+	case levelUp:
+		color = discordColorUp
+	}
+
+	var fields []interface{}
+	for _, fact := range alertFacts(alertData) {
+		fields = append(fields, map[string]interface{}{
+			"name":   fact.Name,
+			"value":  truncate(fact.Value, 1024),
+			"inline": true,
+		})
+	}
+	if alertData.Error != "" {
+		fields = append(fields, map[string]interface{}{
+			"name":  "Error",
+			"value": "```" + truncate(alertData.Error, 1000) + "```",
+		})
+	}
+
+	embed := map[string]interface{}{
+		"title":       fmt.Sprintf("%s %s", emoji, title),
+		"description": truncate(alertData.SiteURL, 4096),
+		"color":       color,
+		"fields":      fields,
+		"timestamp":   alertData.Timestamp.Format(time.RFC3339),
+		"footer":      map[string]interface{}{"text": "Ping Tower"},
+	}
+	if link := siteLink(alertData); link != "" {
+		embed["url"] = link
+	}
+
+	return map[string]interface{}{
+		"username": "Ping Tower",
+		"embeds":   []interface{}{embed},
+	}
+}
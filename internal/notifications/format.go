@@ -0,0 +1,183 @@
+package notifications
+
+import (
+	"fmt"
+	"strings"
+)
+
+// alertLevel selects the colour of rich messages (Slack, Discord, Teams).
+type alertLevel int
+
+const (
+	levelDown alertLevel = iota
+	levelDegraded
+	levelUp
+)
+
+// recoveryTypes are the alert types reporting a recovery.
+var recoveryTypes = map[string]bool{
+	"site_up":             true,
+	"heartbeat_recovered": true,
+	"flapping_stopped":    true,
+}
+
+// conditionAlert is the headline of an alert raised by a check condition.
+type conditionAlert struct {
+	level    alertLevel
+	emoji    string
+	headline string
+}
+
+// conditionAlerts are the alerts of check conditions other than outages and
+// their recoveries. They are not outages: incident management tools do not
+// get them and they do not resolve an outage.
+var conditionAlerts = map[string]conditionAlert{
+	"slow_response":           {levelDegraded, "🟡", "Slow response"},
+	"response_time_recovered": {levelUp, "🟢", "Response time recovered"},
+	"response_time_anomaly":   {levelDegraded, "🟡", "Response time anomaly"},
+	"response_time_normal":    {levelUp, "🟢", "Response time back to normal"},
+	"status_code_changed":     {levelDegraded, "🟠", "Status code changed"},
+	"status_code_restored":    {levelUp, "🟢", "Status code restored"},
+	"content_changed":         {levelDegraded, "🟠", "Content changed"},
+	"content_restored":        {levelUp, "🟢", "Content restored"},
+	"redirect_changed":        {levelDegraded, "🟠", "Redirect target changed"},
+	"redirect_restored":       {levelUp, "🟢", "Redirect target restored"},
+	"keyword_lost":            {levelDegraded, "🟠", "Keyword lost"},
+	"keyword_restored":        {levelUp, "🟢", "Keyword restored"},
+}
+
+type alertFact struct {
+	Name  string
+	Value string
+}
+
+// alertHeadline returns the level, emoji and title of the alert.
+func alertHeadline(alertData AlertData) (alertLevel, string, string) {
+	if c, ok := conditionAlerts[alertData.AlertType]; ok {
+		return c.level, c.emoji, c.headline
+	}
+
+	switch {
+	case alertData.AlertType == AlertTypeDigest:
+		return levelDown, "🔴", fmt.Sprintf("%d alerts", len(alertData.Digest))
+	case alertData.AlertType == "site_flapping":
+		return levelDegraded, "🟠", "Site flapping"
+	case alertData.AlertType == "ssl_renewed":
+		return levelUp, "🟢", "SSL certificate renewed"
+	case alertData.AlertType == "ssl_expiry":
+		if alertData.CheckResult != nil && !alertData.CheckResult.SSLValid {
+			return levelDown, "🔴", "SSL certificate expired"
+		}
+		return levelDegraded, "🟡", "SSL certificate expiring"
+	case recoveryTypes[alertData.AlertType]:
+		return levelUp, "🟢", "Site recovered"
+	case alertData.Status == "down":
+		return levelDown, "🔴", "Site down"
+	}
+	return levelDegraded, "🟡", "Site degraded"
+}
+
+// alertFacts returns the details of the alert as name/value pairs; timings
+// and ping results are included when the check measured them.
+func alertFacts(alertData AlertData) []alertFact {
+	if alertData.AlertType == AlertTypeDigest {
+		return digestFacts(alertData)
+	}
+
+	facts := []alertFact{
+		{"Status", strings.ToUpper(alertData.Status)},
+		{"Status Code", fmt.Sprintf("%d", alertData.StatusCode)},
+		{"Response Time", fmt.Sprintf("%dms", alertData.ResponseTime)},
+		{"Alert Type", alertData.AlertType},
+	}
+
+	result := alertData.CheckResult
+	if result == nil {
+		return facts
+	}
+
+	if result.DNSTime > 0 || result.ConnectTime > 0 || result.TLSTime > 0 || result.TTFB > 0 {
+		facts = append(facts,
+			alertFact{"DNS", fmt.Sprintf("%dms", result.DNSTime)},
+			alertFact{"Connect", fmt.Sprintf("%dms", result.ConnectTime)},
+			alertFact{"TLS", fmt.Sprintf("%dms", result.TLSTime)},
+			alertFact{"TTFB", fmt.Sprintf("%dms", result.TTFB)},
+		)
+	}
+
+	if result.PacketsSent > 0 {
+		facts = append(facts,
+			alertFact{"Packet Loss", fmt.Sprintf("%.1f%% (%d/%d)", result.PacketLoss, result.PacketsReceived, result.PacketsSent)},
+			alertFact{"RTT avg / jitter", fmt.Sprintf("%.2fms / %.2fms", result.RTTAvg, result.Jitter)},
+		)
+	}
+
+	if result.FailedStep != "" {
+		facts = append(facts, alertFact{"Failed Step", result.FailedStep})
+	}
+
+	if result.BaselineResponseTime > 0 && strings.HasPrefix(alertData.AlertType, "response_time_") {
+		facts = append(facts, alertFact{"Usual Response Time", fmt.Sprintf("%.0fms ± %.0fms (%+.1fσ)", result.BaselineResponseTime, result.BaselineSigma, result.ResponseTimeDeviation)})
+	}
+
+	if result.SSLExpiry != nil && strings.HasPrefix(alertData.AlertType, "ssl_") {
+		facts = append(facts, alertFact{"SSL Expiry", result.SSLExpiry.Format("2006-01-02")})
+		if result.SSLIssuer != "" {
+			facts = append(facts, alertFact{"SSL Issuer", result.SSLIssuer})
+		}
+	}
+
+	return facts
+}
+
+// siteLink returns the page of the site in the web interface or "" if
+// PublicURL is not set or the alert does not belong to a site.
+func siteLink(alertData AlertData) string {
+	if PublicURL == "" || alertData.SiteID <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s/metrics?site=%d", PublicURL, alertData.SiteID)
+}
+
+// truncate shortens text to at most limit runes for message size limits.
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// dedupKey identifies the incident of an alert source in incident management
+// tools, so the recovery resolves the incident opened by the outage. Test
+// alerts get a key of their own, so they never touch the incident of a site.
+func dedupKey(alertData AlertData) string {
+	if alertData.AlertType == "test" {
+		return "ping-tower-test-" + alertData.EventID
+	}
+	if alertData.SiteID > 0 {
+		return fmt.Sprintf("ping-tower-site-%d", alertData.SiteID)
+	}
+	return "ping-tower-" + alertData.SiteURL
+}
+
+// incidentAction maps an alert to the action of an incident management tool:
+// outages trigger, recoveries resolve, other alerts are not sent. Certificate
+// and condition alerts share the dedup key of the site and must not touch
+// its outage. Test alerts open a low severity incident and resolve it right
+// away.
+func incidentAction(alertData AlertData) string {
+	switch {
+	case alertData.AlertType == "test":
+		return "test"
+	case alertData.AlertType == AlertTypeDigest, strings.HasPrefix(alertData.AlertType, "ssl_"):
+		return ""
+	case conditionAlerts[alertData.AlertType] != conditionAlert{}:
+		return ""
+	case recoveryTypes[alertData.AlertType]:
+		return "resolve"
+	case alertData.Status == "down":
+		return "trigger"
+	}
+	return ""
+}
@@ -2,6 +2,8 @@ package notifications
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,10 +14,17 @@ import (
 	"time"
 
 	"ping-tower/internal/config"
+	"ping-tower/internal/models"
+	"ping-tower/pkg/webhook"
 )
 
 type AlertManager struct {
 	config *config.AlertsConfig
+
+	// name is the alert configuration of a route, "" for the manager's own
+	// configuration; recipients are the overrides applied to it
+	name       string
+	recipients *Target
 }
 
 // CheckResult represents the result of a site check for alerting purposes
@@ -44,9 +53,32 @@ type CheckResult struct {
 	ContentType   string
 	CacheControl  string
 	Cookies       []string
+
+	PacketsSent     int
+	PacketsReceived int
+	PacketLoss      float64
+	RTTMin          float64
+	RTTAvg          float64
+	RTTMax          float64
+	Jitter          float64
+
+	DNSAnswers []string
+
+	Steps      []models.StepResult
+	FailedStep string
+
+	// BaselineResponseTime and BaselineSigma are the usual response time of
+	// the site at the hour of the check and its robust standard deviation,
+	// ResponseTimeDeviation the distance of the smoothed response time above
+	// it in sigmas; all are 0 when the site has no baseline yet
+	BaselineResponseTime  float64
+	BaselineSigma         float64
+	ResponseTimeDeviation float64
 }
 
 type AlertData struct {
+	// EventID identifies the alert; retries of a delivery keep it
+	EventID      string       `json:"event_id,omitempty"`
 	SiteURL      string       `json:"site_url"`
 	SiteID       int          `json:"site_id"`
 	Status       string       `json:"status"`
@@ -56,6 +88,131 @@ type AlertData struct {
 	Timestamp    time.Time    `json:"timestamp"`
 	AlertType    string       `json:"alert_type"`
 	CheckResult  *CheckResult `json:"check_result,omitempty"`
+	// Digest holds the alerts combined into a digest
+	Digest []AlertData `json:"digest,omitempty"`
+}
+
+const (
+	ChannelEmail     = "email"
+	ChannelWebhook   = "webhook"
+	ChannelTelegram  = "telegram"
+	ChannelSlack     = "slack"
+	ChannelDiscord   = "discord"
+	ChannelTeams     = "teams"
+	ChannelPagerDuty = "pagerduty"
+	ChannelOpsgenie  = "opsgenie"
+)
+
+// Channels lists the supported alert channels.
+var Channels = []string{ChannelEmail, ChannelWebhook, ChannelTelegram, ChannelSlack, ChannelDiscord, ChannelTeams,
+	ChannelPagerDuty, ChannelOpsgenie}
+
+// IsChannel reports whether the channel is supported.
+func IsChannel(channel string) bool {
+	for _, c := range Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// Target selects the channels of a delivery and optionally overrides their
+// recipients, e.g. for a step of an escalation policy.
+type Target struct {
+	Channels       []string `json:"channels,omitempty"`
+	EmailTo        []string `json:"email_to,omitempty"`
+	WebhookURL     string   `json:"webhook_url,omitempty"`
+	TelegramChatID string   `json:"telegram_chat_id,omitempty"`
+}
+
+// Delivery is the delivery of an alert on one channel. It carries what is
+// needed to repeat it: the alert configuration and the recipient overrides.
+type Delivery struct {
+	Channel    string    `json:"channel"`
+	ConfigName string    `json:"config_name"`
+	Recipients *Target   `json:"recipients,omitempty"`
+	Alert      AlertData `json:"alert"`
+}
+
+// Suppressor decides that an alert must not be delivered, e.g. because the
+// incident of the site is acknowledged. The reason is written to the log.
+type Suppressor func(siteID int, alertType string) (suppress bool, reason string)
+
+var suppressors []Suppressor
+
+// AddSuppressor registers a check consulted by SendAlert before delivery.
+func AddSuppressor(s Suppressor) {
+	suppressors = append(suppressors, s)
+}
+
+// Interceptor may take over an alert after the suppressors, e.g. to route it
+// through an escalation policy instead of all channels. It returns the names
+// of the alert configurations it handled, whose routes SendAlert skips;
+// "global" stands for the manager's own configuration of alerts without
+// routes.
+type Interceptor func(alertData AlertData) []string
+
+var interceptors []Interceptor
+
+// AddInterceptor registers an interceptor consulted by SendAlert.
+func AddInterceptor(i Interceptor) {
+	interceptors = append(interceptors, i)
+}
+
+// Route is an alert configuration assigned to a site. Accepts applies its
+// conditions, Recipients optionally overrides its recipients.
+type Route struct {
+	Name       string
+	Config     *config.AlertsConfig
+	Recipients *Target
+	Accepts    func(alertType string, result CheckResult) bool
+}
+
+// NewRouteManager returns the manager delivering through the alert
+// configuration of the route, to its recipients if set.
+func NewRouteManager(route Route) *AlertManager {
+	manager := &AlertManager{config: route.Config, name: route.Name}
+	if route.Recipients != nil {
+		manager = manager.withRecipients(*route.Recipients)
+	}
+	return manager
+}
+
+// SiteRoutes returns the alert configurations assigned to a site. Alerts of
+// sites with routes go to them instead of the manager's own configuration.
+var SiteRoutes func(siteID int) []Route
+
+// RouteAlert returns the recipients of an alert, e.g. the contacts of the
+// person on call. Channels of the returned target are ignored; nil keeps the
+// configured recipients.
+var RouteAlert func(alertData AlertData) *Target
+
+// PublicURL is the address of the web interface, used for links to the site
+// in rich messages. Links are omitted when it is empty.
+var PublicURL string
+
+// AlertDelivered is called after each delivery attempt on a channel; err is
+// nil when the alert was sent.
+var AlertDelivered func(alertData AlertData, channel string, err error)
+
+// DeliveryAttempted is called after each delivery made by SendAlert and
+// SendAlertTo, e.g. to queue failed ones for a retry with Redeliver.
+var DeliveryAttempted func(delivery Delivery, err error)
+
+// Batch is called by SendAlert after suppressors and interceptors. It returns
+// true when it holds the alert for a digest, which is sent later with
+// SendDigest.
+var Batch func(alertData AlertData) bool
+
+// Throttle is called before each delivery; it returns true when the channel
+// must skip the alert, e.g. because of a rate limit.
+var Throttle func(channel string, alertData AlertData) bool
+
+func notifyDelivered(alertData AlertData, channel string, err error) {
+	if AlertDelivered != nil {
+		AlertDelivered(alertData, channel, err)
+	}
 }
 
 func NewAlertManager(alertsConfig *config.AlertsConfig) *AlertManager {
@@ -65,11 +222,20 @@ func NewAlertManager(alertsConfig *config.AlertsConfig) *AlertManager {
 }
 
 func (am *AlertManager) SendAlert(siteID int, siteURL string, result CheckResult, alertType string) error {
-	if !am.config.Enabled {
+	var routes []Route
+	if SiteRoutes != nil && siteID > 0 {
+		routes = SiteRoutes(siteID)
+	}
+
+	if len(routes) == 0 && !am.config.Enabled {
 		log.Println("🔕 Алерты отключены в конфигурации")
 		return nil
 	}
 
+	if am.suppressed(siteID, siteURL, alertType) {
+		return nil
+	}
+
 	alertData := AlertData{
 		SiteURL:      siteURL,
 		SiteID:       siteID,
@@ -80,37 +246,188 @@ func (am *AlertManager) SendAlert(siteID int, siteURL string, result CheckResult
 		Timestamp:    time.Now(),
 		AlertType:    alertType,
 		CheckResult:  &result,
+		EventID:      newEventID(),
+	}
+
+	intercepted := map[string]bool{}
+	for _, intercept := range interceptors {
+		for _, name := range intercept(alertData) {
+			intercepted[name] = true
+		}
+	}
+	if len(routes) == 0 && intercepted["global"] {
+		return nil
+	}
+	if len(routes) > 0 && len(intercepted) > 0 {
+		var remaining []Route
+		for _, route := range routes {
+			if !intercepted[route.Name] {
+				remaining = append(remaining, route)
+			}
+		}
+		if len(remaining) == 0 {
+			return nil
+		}
+		// Остальные маршруты получают частично перехваченный алерт сразу, без дайджеста
+		if len(remaining) < len(routes) {
+			return deliverRoutes(alertData, remaining)
+		}
+	}
+
+	if Batch != nil && Batch(alertData) {
+		return nil
+	}
+
+	if len(routes) > 0 {
+		return deliverRoutes(alertData, routes)
 	}
 
+	return am.routed(alertData).deliver(alertData, am.EnabledChannels())
+}
+
+// deliverRoutes sends the alert through every route whose conditions accept it.
+func deliverRoutes(alertData AlertData, routes []Route) error {
 	var errors []string
 
-	// Send email alert
-	if am.config.Email.Enabled {
-		if err := am.sendEmailAlert(alertData); err != nil {
-			errors = append(errors, fmt.Sprintf("email: %v", err))
-			log.Printf("❌ Ошибка отправки email алерта: %v", err)
-		} else {
-			log.Printf("✅ Email алерт отправлен для %s", siteURL)
+	for _, route := range routes {
+		if route.Accepts != nil && !route.Accepts(alertData.AlertType, *alertData.CheckResult) {
+			continue
+		}
+
+		manager := NewRouteManager(route)
+
+		log.Printf("🧭 Алерт %s для %s отправляется по конфигурации '%s'", alertData.AlertType, alertData.SiteURL, route.Name)
+		if err := manager.deliver(alertData, manager.EnabledChannels()); err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", route.Name, err))
 		}
 	}
 
-	// Send webhook alert
-	if am.config.Webhook.Enabled {
-		if err := am.sendWebhookAlert(alertData); err != nil {
-			errors = append(errors, fmt.Sprintf("webhook: %v", err))
-			log.Printf("❌ Ошибка отправки webhook алерта: %v", err)
-		} else {
-			log.Printf("✅ Webhook алерт отправлен для %s", siteURL)
+	if len(errors) > 0 {
+		return fmt.Errorf("failed to send some alerts: %s", strings.Join(errors, "; "))
+	}
+
+	return nil
+}
+
+// SendAlertTo delivers an alert only to the channels of the target, using the
+// recipients of the target instead of the configured or routed ones where
+// they are set.
+// Suppressors are consulted, interceptors are not.
+func (am *AlertManager) SendAlertTo(alertData AlertData, target Target) error {
+	if am.suppressed(alertData.SiteID, alertData.SiteURL, alertData.AlertType) {
+		return nil
+	}
+
+	// Каждый шаг эскалации - отдельное уведомление со своим идентификатором
+	alertData.EventID = newEventID()
+	return am.routed(alertData).withRecipients(target).deliver(alertData, target.Channels)
+}
+
+// routed applies RouteAlert to the recipients. Managers of routes keep the
+// recipients of their own configuration.
+func (am *AlertManager) routed(alertData AlertData) *AlertManager {
+	if RouteAlert == nil || am.name != "" {
+		return am
+	}
+	if target := RouteAlert(alertData); target != nil {
+		return am.withRecipients(*target)
+	}
+	return am
+}
+
+// withRecipients returns a copy of the manager whose recipients are replaced
+// by the ones set in the target.
+func (am *AlertManager) withRecipients(target Target) *AlertManager {
+	recipients := Target{}
+	if am.recipients != nil {
+		recipients = *am.recipients
+	}
+
+	cfg := *am.config
+	if len(target.EmailTo) > 0 {
+		cfg.Email.To = target.EmailTo
+		recipients.EmailTo = target.EmailTo
+	}
+	if target.WebhookURL != "" {
+		cfg.Webhook.URL = target.WebhookURL
+		recipients.WebhookURL = target.WebhookURL
+	}
+	if target.TelegramChatID != "" {
+		cfg.Telegram.ChatID = target.TelegramChatID
+		recipients.TelegramChatID = target.TelegramChatID
+	}
+	return &AlertManager{config: &cfg, name: am.name, recipients: &recipients}
+}
+
+// Suppressed reports whether a suppressor swallows the alert, e.g. during a
+// maintenance window, so that SendAlertTo would not deliver it.
+func (am *AlertManager) Suppressed(alertData AlertData) bool {
+	return am.suppressed(alertData.SiteID, alertData.SiteURL, alertData.AlertType)
+}
+
+func (am *AlertManager) suppressed(siteID int, siteURL, alertType string) bool {
+	for _, suppress := range suppressors {
+		if ok, reason := suppress(siteID, alertType); ok {
+			log.Printf("🔕 Алерт %s для %s подавлен: %s", alertType, siteURL, reason)
+			return true
 		}
 	}
+	return false
+}
 
-	// Send Telegram alert
+// EnabledChannels returns the channels enabled in the configuration.
+func (am *AlertManager) EnabledChannels() []string {
+	var channels []string
+	if am.config.Email.Enabled {
+		channels = append(channels, ChannelEmail)
+	}
+	if am.config.Webhook.Enabled {
+		channels = append(channels, ChannelWebhook)
+	}
 	if am.config.Telegram.Enabled {
-		if err := am.sendTelegramAlert(alertData); err != nil {
-			errors = append(errors, fmt.Sprintf("telegram: %v", err))
-			log.Printf("❌ Ошибка отправки Telegram алерта: %v", err)
-		} else {
-			log.Printf("✅ Telegram алерт отправлен для %s", siteURL)
+		channels = append(channels, ChannelTelegram)
+	}
+	if am.config.Slack.Enabled {
+		channels = append(channels, ChannelSlack)
+	}
+	if am.config.Discord.Enabled {
+		channels = append(channels, ChannelDiscord)
+	}
+	if am.config.Teams.Enabled {
+		channels = append(channels, ChannelTeams)
+	}
+	if am.config.PagerDuty.Enabled {
+		channels = append(channels, ChannelPagerDuty)
+	}
+	if am.config.Opsgenie.Enabled {
+		channels = append(channels, ChannelOpsgenie)
+	}
+	return channels
+}
+
+func (am *AlertManager) deliver(alertData AlertData, channels []string) error {
+	var errors []string
+	if alertData.EventID == "" {
+		alertData.EventID = newEventID()
+	}
+
+	for _, channel := range channels {
+		if Throttle != nil && Throttle(channel, alertData) {
+			log.Printf("🚦 %s алерт %s для %s пропущен: превышен лимит канала", channel, alertData.AlertType, alertData.SiteURL)
+			continue
+		}
+
+		err := am.send(alertData, channel)
+		if DeliveryAttempted != nil {
+			DeliveryAttempted(Delivery{
+				Channel:    channel,
+				ConfigName: am.name,
+				Recipients: am.recipients,
+				Alert:      alertData,
+			}, err)
+		}
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", channel, err))
 		}
 	}
 
@@ -121,6 +438,49 @@ func (am *AlertManager) SendAlert(siteID int, siteURL string, result CheckResult
 	return nil
 }
 
+// Redeliver repeats a delivery with the recipients it was made to.
+// Suppressors and interceptors are not consulted again.
+func (am *AlertManager) Redeliver(delivery Delivery) error {
+	manager := am
+	if delivery.Recipients != nil {
+		manager = am.withRecipients(*delivery.Recipients)
+	}
+	return manager.send(delivery.Alert, delivery.Channel)
+}
+
+func (am *AlertManager) send(alertData AlertData, channel string) error {
+	var err error
+	switch channel {
+	case ChannelEmail:
+		err = am.sendEmailAlert(alertData)
+	case ChannelWebhook:
+		err = am.sendWebhookAlert(alertData)
+	case ChannelTelegram:
+		err = am.sendTelegramAlert(alertData)
+	case ChannelSlack:
+		err = am.sendSlackAlert(alertData)
+	case ChannelDiscord:
+		err = am.sendDiscordAlert(alertData)
+	case ChannelTeams:
+		err = am.sendTeamsAlert(alertData)
+	case ChannelPagerDuty:
+		err = am.sendPagerDutyAlert(alertData)
+	case ChannelOpsgenie:
+		err = am.sendOpsgenieAlert(alertData)
+	default:
+		err = fmt.Errorf("unknown channel")
+	}
+
+	notifyDelivered(alertData, channel, err)
+	if err != nil {
+		log.Printf("❌ Ошибка отправки %s алерта: %v", channel, err)
+	} else {
+		log.Printf("✅ %s алерт отправлен для %s", channel, alertData.SiteURL)
+	}
+
+	return err
+}
+
 func (am *AlertManager) sendEmailAlert(alertData AlertData) error {
 	if am.config.Email.SMTPServer == "" || len(am.config.Email.To) == 0 {
 		return fmt.Errorf("email configuration incomplete")
@@ -141,6 +501,8 @@ Site Monitor Alert
 `, alertData.SiteURL, strings.ToUpper(alertData.Status), alertData.ResponseTime,
 		alertData.StatusCode, alertData.Timestamp.Format("2006-01-02 15:04:05"), alertData.AlertType)
 
+	body += digestText(alertData)
+
 	if alertData.Error != "" {
 		body += fmt.Sprintf("❌ Error: %s\n", alertData.Error)
 	}
@@ -157,14 +519,53 @@ Site Monitor Alert
 `, alertData.CheckResult.ContentLength, alertData.CheckResult.DNSTime,
 			alertData.CheckResult.ConnectTime, alertData.CheckResult.TLSTime,
 			alertData.CheckResult.TTFB, alertData.CheckResult.SSLValid)
+
+		if alertData.CheckResult.SSLExpiry != nil {
+			body += fmt.Sprintf("• SSL Expiry: %s\n", alertData.CheckResult.SSLExpiry.Format("2006-01-02"))
+		}
+
+		if alertData.CheckResult.PacketsSent > 0 {
+			body += fmt.Sprintf(`
+📡 Ping:
+• Packets: %d sent, %d received, %.1f%% loss
+• RTT min/avg/max: %.2f/%.2f/%.2f ms
+• Jitter: %.2f ms
+`, alertData.CheckResult.PacketsSent, alertData.CheckResult.PacketsReceived,
+				alertData.CheckResult.PacketLoss, alertData.CheckResult.RTTMin,
+				alertData.CheckResult.RTTAvg, alertData.CheckResult.RTTMax, alertData.CheckResult.Jitter)
+		}
+
+		if len(alertData.CheckResult.Steps) > 0 {
+			body += "\n🧭 Steps:\n"
+			for i, step := range alertData.CheckResult.Steps {
+				mark := "✅"
+				if !step.Passed {
+					mark = "❌"
+				}
+				body += fmt.Sprintf("%s %d. %s %s %s - %d in %dms\n", mark, i+1, step.Name, step.Method, step.URL,
+					step.StatusCode, step.ResponseTime)
+			}
+		}
 	}
 
+	contentType := "text/plain; charset=UTF-8"
+	if t, templateSubject, templateBody := applyTemplate(ChannelEmail, alertData); t != nil {
+		if templateSubject != "" {
+			subject = templateSubject
+		}
+		body = templateBody
+		if t.Format == models.TemplateFormatHTML {
+			contentType = "text/html; charset=UTF-8"
+		}
+	}
+	subject = strings.NewReplacer("\r", " ", "\n", " ").Replace(subject)
+
 	message := []byte(fmt.Sprintf("Subject: %s\r\n"+
 		"From: %s\r\n"+
 		"To: %s\r\n"+
-		"Content-Type: text/plain; charset=UTF-8\r\n"+
+		"Content-Type: %s\r\n"+
 		"\r\n"+
-		"%s", subject, am.config.Email.From, strings.Join(am.config.Email.To, ","), body))
+		"%s", subject, am.config.Email.From, strings.Join(am.config.Email.To, ","), contentType, body))
 
 	auth := smtp.PlainAuth("", am.config.Email.Username, am.config.Email.Password, am.config.Email.SMTPServer)
 	err := smtp.SendMail(am.config.Email.SMTPServer+":"+am.config.Email.Port, auth,
@@ -173,15 +574,33 @@ Site Monitor Alert
 	return err
 }
 
+// webhookPayload is the versioned webhook body, see webhook.Payload.
+type webhookPayload struct {
+	SchemaVersion string `json:"schema_version"`
+	AlertData
+}
+
+// newEventID returns a random alert event ID.
+func newEventID() string {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("evt_%d", time.Now().UnixNano())
+	}
+	return "evt_" + hex.EncodeToString(buf)
+}
+
 func (am *AlertManager) sendWebhookAlert(alertData AlertData) error {
 	if am.config.Webhook.URL == "" {
 		return fmt.Errorf("webhook URL not configured")
 	}
 
-	jsonData, err := json.Marshal(alertData)
+	jsonData, err := json.Marshal(webhookPayload{SchemaVersion: webhook.SchemaVersion, AlertData: alertData})
 	if err != nil {
 		return fmt.Errorf("failed to marshal alert data: %v", err)
 	}
+	if t, _, body := applyTemplate(ChannelWebhook, alertData); t != nil {
+		jsonData = []byte(body)
+	}
 
 	client := &http.Client{
 		Timeout: time.Duration(am.config.Webhook.Timeout) * time.Second,
@@ -200,6 +619,13 @@ func (am *AlertManager) sendWebhookAlert(alertData AlertData) error {
 		req.Header.Set(key, value)
 	}
 
+	// Подпись покрывает и тело из шаблона, пользовательские заголовки ее не переопределяют
+	req.Header.Set(webhook.EventIDHeader, alertData.EventID)
+	req.Header.Set(webhook.SchemaVersionHeader, webhook.SchemaVersion)
+	if am.config.Webhook.Secret != "" {
+		req.Header.Set(webhook.SignatureHeader, webhook.Sign(am.config.Webhook.Secret, time.Now(), jsonData))
+	}
+
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send webhook: %v", err)
@@ -240,10 +666,26 @@ func (am *AlertManager) sendTelegramAlert(alertData AlertData) error {
 		alertData.ResponseTime, alertData.StatusCode,
 		alertData.Timestamp.Format("2006-01-02 15:04:05"), alertData.AlertType)
 
+	if alertData.CheckResult != nil && alertData.CheckResult.PacketsSent > 0 {
+		message += fmt.Sprintf("\n📡 Packet Loss: %.1f%% (%d/%d)\n📶 RTT avg: %.2fms, jitter: %.2fms",
+			alertData.CheckResult.PacketLoss, alertData.CheckResult.PacketsReceived,
+			alertData.CheckResult.PacketsSent, alertData.CheckResult.RTTAvg, alertData.CheckResult.Jitter)
+	}
+
+	if alertData.CheckResult != nil && alertData.CheckResult.FailedStep != "" {
+		message += fmt.Sprintf("\n🧭 Failed Step: %s", alertData.CheckResult.FailedStep)
+	}
+
 	if alertData.Error != "" {
 		message += fmt.Sprintf("\n\n❌ Error: %s", alertData.Error)
 	}
 
+	message += digestText(alertData)
+
+	if t, _, body := applyTemplate(ChannelTelegram, alertData); t != nil {
+		message = body
+	}
+
 	telegramURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", am.config.Telegram.BotToken)
 
 	payload := map[string]interface{}{
@@ -0,0 +1,123 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// opsgenieAPIURL returns the API address of the account region.
+func opsgenieAPIURL(region string) string {
+	if region == "eu" {
+		return "https://api.eu.opsgenie.com"
+	}
+	return "https://api.opsgenie.com"
+}
+
+// sendOpsgenieAlert creates an alert whose alias is the dedup key of the
+// site; Opsgenie deduplicates open alerts by alias and the recovery closes it.
+// A test alert is created as P5 and closed at once.
+func (am *AlertManager) sendOpsgenieAlert(alertData AlertData) error {
+	if am.config.Opsgenie.APIKey == "" {
+		return fmt.Errorf("opsgenie API key not configured")
+	}
+
+	alias := dedupKey(alertData)
+	baseURL := opsgenieAPIURL(am.config.Opsgenie.Region)
+	closeEndpoint := baseURL + "/v2/alerts/" + url.PathEscape(truncate(alias, 512)) + "/close?identifierType=alias"
+
+	var endpoint string
+	var body map[string]interface{}
+
+	action := incidentAction(alertData)
+	switch action {
+	case "trigger", "test":
+		_, emoji, title := alertHeadline(alertData)
+		details := map[string]string{}
+		for _, fact := range alertFacts(alertData) {
+			details[fact.Name] = fact.Value
+		}
+		if link := siteLink(alertData); link != "" {
+			details["Link"] = link
+		}
+
+		description := alertData.SiteURL
+		if alertData.Error != "" {
+			description += "\n\nError: " + alertData.Error
+		}
+
+		endpoint = baseURL + "/v2/alerts"
+		body = map[string]interface{}{
+			"message":     truncate(fmt.Sprintf("%s %s: %s", emoji, title, alertData.SiteURL), 130),
+			"alias":       truncate(alias, 512),
+			"description": truncate(description, 15000),
+			"source":      "Ping Tower",
+			"entity":      alertData.SiteURL,
+			"priority":    opsgeniePriority(action),
+			"tags":        []string{"ping-tower", alertData.AlertType},
+			"details":     details,
+		}
+	case "resolve":
+		endpoint = closeEndpoint
+		body = map[string]interface{}{
+			"source": "Ping Tower",
+			"note":   fmt.Sprintf("%s recovered at %s", alertData.SiteURL, alertData.Timestamp.Format("2006-01-02 15:04:05")),
+		}
+	default:
+		log.Printf("⏭️ Opsgenie: алерт %s для %s не открывает и не закрывает инцидент", alertData.AlertType, alertData.SiteURL)
+		return nil
+	}
+
+	if err := am.postOpsgenie(endpoint, body); err != nil {
+		return err
+	}
+
+	if action == "test" {
+		return am.postOpsgenie(closeEndpoint, map[string]interface{}{
+			"source": "Ping Tower",
+			"note":   "Test alert",
+		})
+	}
+	return nil
+}
+
+func opsgeniePriority(action string) string {
+	if action == "test" {
+		return "P5"
+	}
+	return "P1"
+}
+
+func (am *AlertManager) postOpsgenie(endpoint string, body map[string]interface{}) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal opsgenie request: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create opsgenie request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+am.config.Opsgenie.APIKey)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send opsgenie request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Запросы обрабатываются асинхронно, успешный ответ - 202 Accepted
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("opsgenie returned status code %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// sendPagerDutyAlert sends an Events API v2 event. Outages trigger an
+// incident with the dedup key of the site, the recovery resolves it. A test
+// alert triggers an info incident and resolves it at once.
+func (am *AlertManager) sendPagerDutyAlert(alertData AlertData) error {
+	if am.config.PagerDuty.RoutingKey == "" {
+		return fmt.Errorf("pagerduty routing key not configured")
+	}
+
+	action := incidentAction(alertData)
+	if action == "" {
+		log.Printf("⏭️ PagerDuty: алерт %s для %s не открывает и не закрывает инцидент", alertData.AlertType, alertData.SiteURL)
+		return nil
+	}
+
+	event := map[string]interface{}{
+		"routing_key":  am.config.PagerDuty.RoutingKey,
+		"event_action": action,
+		"dedup_key":    dedupKey(alertData),
+	}
+
+	if action == "trigger" || action == "test" {
+		severity := "critical"
+		if action == "test" {
+			event["event_action"] = "trigger"
+			severity = "info"
+		}
+
+		_, emoji, title := alertHeadline(alertData)
+		details := map[string]interface{}{}
+		for _, fact := range alertFacts(alertData) {
+			details[fact.Name] = fact.Value
+		}
+		if alertData.Error != "" {
+			details["Error"] = alertData.Error
+		}
+
+		event["payload"] = map[string]interface{}{
+			"summary":        truncate(fmt.Sprintf("%s %s: %s", emoji, title, alertData.SiteURL), 1024),
+			"source":         alertData.SiteURL,
+			"severity":       severity,
+			"timestamp":      alertData.Timestamp.Format(time.RFC3339),
+			"component":      alertData.SiteURL,
+			"class":          alertData.AlertType,
+			"custom_details": details,
+		}
+		if link := siteLink(alertData); link != "" {
+			event["links"] = []interface{}{
+				map[string]interface{}{"href": link, "text": "Open in Ping Tower"},
+			}
+		}
+	}
+
+	if err := postPagerDutyEvent(event); err != nil {
+		return err
+	}
+
+	if action == "test" {
+		return postPagerDutyEvent(map[string]interface{}{
+			"routing_key":  am.config.PagerDuty.RoutingKey,
+			"event_action": "resolve",
+			"dedup_key":    event["dedup_key"],
+		})
+	}
+	return nil
+}
+
+func postPagerDutyEvent(event map[string]interface{}) error {
+	jsonData, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pagerduty event: %v", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(pagerDutyEventsURL, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to send pagerduty event: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("pagerduty returned status code %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
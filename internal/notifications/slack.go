@@ -0,0 +1,236 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"ping-tower/internal/config"
+)
+
+const slackPostMessageURL = "https://slack.com/api/chat.postMessage"
+
+const (
+	slackColorDown     = "#e01e5a"
+	slackColorUp       = "#2eb67d"
+	slackColorDegraded = "#ecb22e"
+)
+
+type slackThread struct {
+	Channel string
+	TS      string
+}
+
+// slackThreads keeps the first message of an open outage per channel and
+// site, follow-ups and the recovery are posted in its thread. Only the bot
+// mode returns message timestamps; after a restart the recovery of an
+// earlier outage is posted to the channel.
+var slackThreads = struct {
+	sync.Mutex
+	threads map[string]slackThread
+}{threads: make(map[string]slackThread)}
+
+func (am *AlertManager) sendSlackAlert(alertData AlertData) error {
+	cfg := am.config.Slack
+	message := slackMessage(alertData)
+
+	if cfg.BotToken != "" {
+		if cfg.Channel == "" {
+			return fmt.Errorf("slack channel not configured")
+		}
+		return postSlackMessage(cfg, alertData, message)
+	}
+
+	if cfg.WebhookURL == "" {
+		return fmt.Errorf("slack configuration incomplete")
+	}
+	return postSlackWebhook(cfg.WebhookURL, message)
+}
+
+// postSlackMessage sends the message with chat.postMessage, replying in the
+// thread of the open outage of the site if there is one.
+func postSlackMessage(cfg config.SlackAlertConfig, alertData AlertData, message map[string]interface{}) error {
+	key := cfg.Channel + "|" + alertData.SiteURL
+	threaded := alertData.AlertType != "test" && alertData.AlertType != AlertTypeDigest
+	recovery := recoveryTypes[alertData.AlertType]
+
+	slackThreads.Lock()
+	thread, open := slackThreads.threads[key]
+	slackThreads.Unlock()
+
+	message["channel"] = cfg.Channel
+	if threaded && open {
+		message["channel"] = thread.Channel
+		message["thread_ts"] = thread.TS
+		if recovery {
+			message["reply_broadcast"] = true
+		}
+	}
+
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal slack payload: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", slackPostMessageURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create slack request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+cfg.BotToken)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send slack message: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack API returned status code: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		OK      bool   `json:"ok"`
+		Error   string `json:"error"`
+		Channel string `json:"channel"`
+		TS      string `json:"ts"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode slack response: %v", err)
+	}
+	if !result.OK {
+		return fmt.Errorf("slack API error: %s", result.Error)
+	}
+
+	if !threaded {
+		return nil
+	}
+
+	slackThreads.Lock()
+	defer slackThreads.Unlock()
+	switch {
+	case recovery:
+		delete(slackThreads.threads, key)
+	case !open && alertData.Status == "down":
+		slackThreads.threads[key] = slackThread{Channel: result.Channel, TS: result.TS}
+		log.Printf("🧵 Открыт тред Slack для %s", alertData.SiteURL)
+	}
+
+	return nil
+}
+
+// postSlackWebhook sends the message to an incoming webhook. Webhooks do not
+// return the message timestamp, so every message goes to the channel.
+func postSlackWebhook(webhookURL string, message map[string]interface{}) error {
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal slack payload: %v", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(webhookURL, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to send slack webhook: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack webhook returned status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// slackMessage builds a Block Kit message; the blocks are wrapped in an
+// attachment to get the status colour bar.
+func slackMessage(alertData AlertData) map[string]interface{} {
+	level, emoji, title := alertHeadline(alertData)
+	color := slackColorDegraded
+	switch level {
+	case levelDown:
+		color = slackColorDown
+	case levelUp:
+		color = slackColorUp
+	}
+
+	blocks := []interface{}{
+		slackSection(fmt.Sprintf("*%s %s*\n%s", emoji, title, slackEscape(alertData.SiteURL))),
+	}
+
+	// По 4 поля в секции: статус, тайминги, ping
+	facts := alertFacts(alertData)
+	for i := 0; i < len(facts); i += 4 {
+		var fields []string
+		for _, fact := range facts[i:min(i+4, len(facts))] {
+			fields = append(fields, fmt.Sprintf("*%s:*\n%s", fact.Name, slackEscape(fact.Value)))
+		}
+		blocks = append(blocks, slackFields(fields...))
+	}
+
+	if alertData.Error != "" {
+		blocks = append(blocks, slackSection("*Error:*\n```"+slackEscape(strings.ReplaceAll(alertData.Error, "```", "'''"))+"```"))
+	}
+
+	if link := siteLink(alertData); link != "" {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "actions",
+			"elements": []interface{}{
+				map[string]interface{}{
+					"type": "button",
+					"text": map[string]interface{}{"type": "plain_text", "text": "Open in Ping Tower"},
+					"url":  link,
+				},
+			},
+		})
+	}
+
+	blocks = append(blocks, map[string]interface{}{
+		"type": "context",
+		"elements": []interface{}{
+			map[string]interface{}{
+				"type": "mrkdwn",
+				"text": fmt.Sprintf("<!date^%d^{date_short_pretty} {time_secs}|%s>",
+					alertData.Timestamp.Unix(), alertData.Timestamp.Format("2006-01-02 15:04:05")),
+			},
+		},
+	})
+
+	return map[string]interface{}{
+		"text": fmt.Sprintf("%s %s: %s", emoji, title, alertData.SiteURL),
+		"attachments": []interface{}{
+			map[string]interface{}{
+				"color":  color,
+				"blocks": blocks,
+			},
+		},
+	}
+}
+
+func slackSection(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "section",
+		"text": map[string]interface{}{"type": "mrkdwn", "text": text},
+	}
+}
+
+func slackFields(fields ...string) map[string]interface{} {
+	items := make([]interface{}, 0, len(fields))
+	for _, field := range fields {
+		items = append(items, map[string]interface{}{"type": "mrkdwn", "text": field})
+	}
+	return map[string]interface{}{
+		"type":   "section",
+		"fields": items,
+	}
+}
+
+// slackEscape escapes the control characters of Slack mrkdwn.
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
@@ -0,0 +1,116 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func (am *AlertManager) sendTeamsAlert(alertData AlertData) error {
+	if am.config.Teams.WebhookURL == "" {
+		return fmt.Errorf("teams webhook URL not configured")
+	}
+
+	jsonData, err := json.Marshal(teamsMessage(alertData))
+	if err != nil {
+		return fmt.Errorf("failed to marshal teams payload: %v", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(am.config.Teams.WebhookURL, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to send teams webhook: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Incoming Webhook отвечает 200, Workflows (Power Automate) - 202
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("teams webhook returned status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// teamsMessage wraps an Adaptive Card in a message accepted by both Teams
+// incoming webhooks and Workflows webhooks.
+func teamsMessage(alertData AlertData) map[string]interface{} {
+	level, emoji, title := alertHeadline(alertData)
+	color := "Warning"
+	switch level {
+	case levelDown:
+		color = "Attention"
+	case levelUp:
+		color = "Good"
+	}
+
+	var facts []interface{}
+	for _, fact := range alertFacts(alertData) {
+		facts = append(facts, map[string]interface{}{"title": fact.Name, "value": fact.Value})
+	}
+
+	body := []interface{}{
+		map[string]interface{}{
+			"type":   "TextBlock",
+			"text":   fmt.Sprintf("%s %s", emoji, title),
+			"size":   "Large",
+			"weight": "Bolder",
+			"color":  color,
+		},
+		map[string]interface{}{
+			"type": "TextBlock",
+			"text": alertData.SiteURL,
+			"wrap": true,
+		},
+		map[string]interface{}{
+			"type":  "FactSet",
+			"facts": facts,
+		},
+	}
+
+	if alertData.Error != "" {
+		body = append(body, map[string]interface{}{
+			"type":     "TextBlock",
+			"text":     "Error: " + alertData.Error,
+			"wrap":     true,
+			"color":    "Attention",
+			"fontType": "Monospace",
+		})
+	}
+
+	body = append(body, map[string]interface{}{
+		"type":     "TextBlock",
+		"text":     alertData.Timestamp.Format("2006-01-02 15:04:05 MST"),
+		"isSubtle": true,
+		"size":     "Small",
+	})
+
+	card := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+		"msteams": map[string]interface{}{"width": "Full"},
+	}
+	if link := siteLink(alertData); link != "" {
+		card["actions"] = []interface{}{
+			map[string]interface{}{
+				"type":  "Action.OpenUrl",
+				"title": "Open in Ping Tower",
+				"url":   link,
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []interface{}{
+			map[string]interface{}{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"contentUrl":  nil,
+				"content":     card,
+			},
+		},
+	}
+}
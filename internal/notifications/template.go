@@ -0,0 +1,199 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"strings"
+	"text/template"
+	"time"
+
+	"ping-tower/internal/models"
+)
+
+// TemplatedChannels lists the channels whose message can be replaced by an
+// alert template. Slack, Discord, Teams and the incident management tools
+// keep their structured payloads.
+var TemplatedChannels = []string{ChannelEmail, ChannelWebhook, ChannelTelegram}
+
+// LookupTemplate returns the enabled template of the channel for the alert
+// type or nil to use the built-in message.
+var LookupTemplate func(channel, alertType string) *models.AlertTemplate
+
+// templateFuncs are available in templates next to the fields of AlertData.
+var templateFuncs = map[string]interface{}{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"join":  strings.Join,
+	"date": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+	"statusEmoji": func(alertData AlertData) string {
+		_, emoji, _ := alertHeadline(alertData)
+		return emoji
+	},
+	"headline": func(alertData AlertData) string {
+		_, _, title := alertHeadline(alertData)
+		return title
+	},
+	"siteLink": siteLink,
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// CheckTemplate validates the template and renders it against a sample
+// alert, so that unknown fields are reported when it is saved.
+func CheckTemplate(t *models.AlertTemplate) error {
+	templated := false
+	for _, channel := range TemplatedChannels {
+		if channel == t.Channel {
+			templated = true
+		}
+	}
+	if !templated {
+		return fmt.Errorf("channel must be one of: %s", strings.Join(TemplatedChannels, ", "))
+	}
+
+	switch t.Format {
+	case models.TemplateFormatText:
+	case models.TemplateFormatHTML:
+		if t.Channel != ChannelEmail {
+			return fmt.Errorf("html format is only supported for email")
+		}
+	default:
+		return fmt.Errorf("format must be text or html")
+	}
+
+	if strings.TrimSpace(t.Body) == "" {
+		return fmt.Errorf("body is required")
+	}
+
+	_, _, err := RenderTemplate(t, SampleAlert(t.AlertType, ""))
+	return err
+}
+
+// RenderTemplate executes the subject and the body of the template with the
+// alert as data. The subject is always plain text.
+func RenderTemplate(t *models.AlertTemplate, alertData AlertData) (subject, body string, err error) {
+	if subject, err = renderText("subject", t.Subject, alertData); err != nil {
+		return "", "", err
+	}
+
+	if t.Format == models.TemplateFormatHTML {
+		body, err = renderHTML(t.Body, alertData)
+	} else {
+		body, err = renderText("body", t.Body, alertData)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	return strings.TrimSpace(subject), body, nil
+}
+
+func renderText(name, text string, alertData AlertData) (string, error) {
+	tmpl, err := template.New(name).Funcs(template.FuncMap(templateFuncs)).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, alertData); err != nil {
+		return "", fmt.Errorf("%s: %v", name, err)
+	}
+	return buf.String(), nil
+}
+
+func renderHTML(text string, alertData AlertData) (string, error) {
+	tmpl, err := htmltemplate.New("body").Funcs(htmltemplate.FuncMap(templateFuncs)).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("body: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, alertData); err != nil {
+		return "", fmt.Errorf("body: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// applyTemplate renders the template of the channel for the alert. It
+// returns nil when there is none or rendering failed, the built-in message is
+// sent then.
+func applyTemplate(channel string, alertData AlertData) (t *models.AlertTemplate, subject, body string) {
+	if LookupTemplate == nil {
+		return nil, "", ""
+	}
+
+	t = LookupTemplate(channel, alertData.AlertType)
+	if t == nil {
+		return nil, "", ""
+	}
+
+	subject, body, err := RenderTemplate(t, alertData)
+	if err != nil {
+		log.Printf("⚠️ Ошибка шаблона оповещения #%d (%s): %v - используется стандартное сообщение", t.ID, channel, err)
+		return nil, "", ""
+	}
+
+	return t, subject, body
+}
+
+// SampleAlert returns an alert of the given type with a plausible check
+// result, used to preview templates.
+func SampleAlert(alertType, siteURL string) AlertData {
+	if alertType == "" {
+		alertType = "site_down"
+	}
+	if siteURL == "" {
+		siteURL = "https://example.com"
+	}
+
+	expiry := time.Now().AddDate(0, 2, 0)
+	result := CheckResult{
+		Status:        "down",
+		StatusCode:    503,
+		ResponseTime:  2450,
+		ContentLength: 512,
+		SSLValid:      true,
+		SSLExpiry:     &expiry,
+		Error:         "HTTP 503 Service Unavailable",
+		DNSTime:       12,
+		ConnectTime:   35,
+		TLSTime:       48,
+		TTFB:          2310,
+		FinalURL:      siteURL,
+		ServerType:    "nginx",
+		ContentType:   "text/html",
+	}
+
+	switch {
+	case recoveryTypes[alertType]:
+		result.Status = "up"
+		result.StatusCode = 200
+		result.ResponseTime = 180
+		result.TTFB = 95
+		result.Error = ""
+	case conditionAlerts[alertType] != conditionAlert{}:
+		result.Status = "up"
+		result.StatusCode = 200
+		result.Error = ""
+	}
+
+	return AlertData{
+		SiteURL:      siteURL,
+		SiteID:       1,
+		Status:       result.Status,
+		StatusCode:   result.StatusCode,
+		ResponseTime: result.ResponseTime,
+		Error:        result.Error,
+		Timestamp:    time.Now(),
+		AlertType:    alertType,
+		CheckResult:  &result,
+		EventID:      "evt_example",
+	}
+}
@@ -0,0 +1,58 @@
+package oncall
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"ping-tower/internal/models"
+)
+
+const icalTimeFormat = "20060102T150405Z"
+
+// ICal renders the shifts as an iCalendar feed, one event per shift.
+func ICal(s *models.OnCallSchedule, shifts []models.OnCallShift) string {
+	var b strings.Builder
+	line := func(format string, args ...interface{}) {
+		b.WriteString(fmt.Sprintf(format, args...))
+		b.WriteString("\r\n")
+	}
+
+	now := time.Now().UTC().Format(icalTimeFormat)
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//PingTower//On-call//RU")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:%s", icalEscape(s.Name))
+	line("X-WR-TIMEZONE:%s", s.Timezone)
+
+	for _, shift := range shifts {
+		name := fmt.Sprintf("#%d", shift.UserID)
+		if shift.User != nil {
+			name = shift.User.Name
+		}
+
+		description := "Layer: " + shift.Layer
+		if shift.Override {
+			description = "Override"
+		}
+
+		line("BEGIN:VEVENT")
+		line("UID:oncall-%d-%d-%d@ping-tower", s.ID, shift.UserID, shift.Start.Unix())
+		line("DTSTAMP:%s", now)
+		line("DTSTART:%s", shift.Start.UTC().Format(icalTimeFormat))
+		line("DTEND:%s", shift.End.UTC().Format(icalTimeFormat))
+		line("SUMMARY:%s", icalEscape("On call: "+name))
+		line("DESCRIPTION:%s", icalEscape(description))
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+	return b.String()
+}
+
+func icalEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,273 @@
+package oncall
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"ping-tower/internal/models"
+)
+
+// MaxRange limits the period of computed shifts and iCal exports.
+const MaxRange = 366 * 24 * time.Hour
+
+// Validate checks the layers of the schedule and fills in defaults.
+func Validate(s *models.OnCallSchedule) error {
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if s.Timezone == "" {
+		s.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone: %v", err)
+	}
+	if len(s.Layers) == 0 {
+		return fmt.Errorf("at least one layer is required")
+	}
+
+	for i := range s.Layers {
+		layer := &s.Layers[i]
+		if strings.TrimSpace(layer.Name) == "" {
+			layer.Name = fmt.Sprintf("Layer %d", i+1)
+		}
+		if rotationDays(layer.Rotation) == 0 {
+			return fmt.Errorf("layer %d: rotation must be daily or weekly", i+1)
+		}
+		if layer.StartAt.IsZero() {
+			return fmt.Errorf("layer %d: start_at is required", i+1)
+		}
+		if len(layer.UserIDs) == 0 {
+			return fmt.Errorf("layer %d: at least one user is required", i+1)
+		}
+		if (layer.RestrictFrom == "") != (layer.RestrictTo == "") {
+			return fmt.Errorf("layer %d: restrict_from and restrict_to must be set together", i+1)
+		}
+		if layer.RestrictFrom != "" {
+			if _, err := parseClock(layer.RestrictFrom); err != nil {
+				return fmt.Errorf("layer %d: invalid restrict_from: %v", i+1, err)
+			}
+			if _, err := parseClock(layer.RestrictTo); err != nil {
+				return fmt.Errorf("layer %d: invalid restrict_to: %v", i+1, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateUser checks an on-call user.
+func ValidateUser(u *models.OnCallUser) error {
+	u.Name = strings.TrimSpace(u.Name)
+	u.Email = strings.TrimSpace(u.Email)
+	u.TelegramChatID = strings.TrimSpace(u.TelegramChatID)
+	u.WebhookURL = strings.TrimSpace(u.WebhookURL)
+
+	if u.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if u.Email == "" && u.TelegramChatID == "" && u.WebhookURL == "" {
+		return fmt.Errorf("at least one contact (email, telegram_chat_id or webhook_url) is required")
+	}
+	return nil
+}
+
+// ValidateOverride checks the period of an override.
+func ValidateOverride(o *models.OnCallOverride) error {
+	if o.UserID <= 0 {
+		return fmt.Errorf("user_id is required")
+	}
+	if o.StartsAt.IsZero() || o.EndsAt.IsZero() {
+		return fmt.Errorf("starts_at and ends_at are required")
+	}
+	if !o.EndsAt.After(o.StartsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+	return nil
+}
+
+// Location returns the time zone of the schedule, UTC if it is invalid.
+func Location(s *models.OnCallSchedule) *time.Location {
+	if loc, err := time.LoadLocation(s.Timezone); err == nil && s.Timezone != "" {
+		return loc
+	}
+	return time.UTC
+}
+
+// Resolve returns who is on call at the given moment: the latest override
+// covering it, otherwise the last layer with somebody on call. The returned
+// shift has no Start/End.
+func Resolve(s *models.OnCallSchedule, overrides []models.OnCallOverride, at time.Time) (models.OnCallShift, bool) {
+	for i := len(overrides) - 1; i >= 0; i-- {
+		o := overrides[i]
+		if !at.Before(o.StartsAt) && at.Before(o.EndsAt) {
+			return models.OnCallShift{UserID: o.UserID, Layer: "override", Override: true}, true
+		}
+	}
+
+	loc := Location(s)
+	for i := len(s.Layers) - 1; i >= 0; i-- {
+		if userID, ok := layerUser(&s.Layers[i], loc, at); ok {
+			return models.OnCallShift{UserID: userID, Layer: s.Layers[i].Name}, true
+		}
+	}
+
+	return models.OnCallShift{}, false
+}
+
+// Shifts computes the final schedule for [from, to): consecutive periods
+// with the same person merge into one shift, gaps without anybody on call
+// are left out.
+func Shifts(s *models.OnCallSchedule, overrides []models.OnCallOverride, from, to time.Time) []models.OnCallShift {
+	loc := Location(s)
+
+	points := []time.Time{from, to}
+	for i := range s.Layers {
+		points = append(points, layerBoundaries(&s.Layers[i], loc, from, to)...)
+	}
+	for _, o := range overrides {
+		points = append(points, o.StartsAt, o.EndsAt)
+	}
+
+	sort.Slice(points, func(i, j int) bool { return points[i].Before(points[j]) })
+
+	shifts := []models.OnCallShift{}
+	for i := 0; i+1 < len(points); i++ {
+		start, end := points[i], points[i+1]
+		if start.Before(from) || !end.After(start) || end.After(to) {
+			continue
+		}
+
+		shift, ok := Resolve(s, overrides, start)
+		if !ok {
+			continue
+		}
+
+		if n := len(shifts); n > 0 {
+			last := &shifts[n-1]
+			if last.End.Equal(start) && last.UserID == shift.UserID && last.Layer == shift.Layer && last.Override == shift.Override {
+				last.End = end
+				continue
+			}
+		}
+
+		shift.Start, shift.End = start, end
+		shifts = append(shifts, shift)
+	}
+
+	return shifts
+}
+
+func rotationDays(rotation string) int {
+	switch rotation {
+	case models.OnCallRotationDaily:
+		return 1
+	case models.OnCallRotationWeekly:
+		return 7
+	}
+	return 0
+}
+
+// handoff returns the start of the k-th rotation period. Calendar days are
+// used so that the handoff keeps its local time across DST changes.
+func handoff(layer *models.OnCallLayer, loc *time.Location, k int) time.Time {
+	return layer.StartAt.In(loc).AddDate(0, 0, k*rotationDays(layer.Rotation))
+}
+
+// period returns the index of the rotation period containing at.
+func period(layer *models.OnCallLayer, loc *time.Location, at time.Time) int {
+	days := rotationDays(layer.Rotation)
+	k := int(at.Sub(layer.StartAt).Hours() / 24 / float64(days))
+	for k > 0 && handoff(layer, loc, k).After(at) {
+		k--
+	}
+	for !handoff(layer, loc, k+1).After(at) {
+		k++
+	}
+	return k
+}
+
+func layerUser(layer *models.OnCallLayer, loc *time.Location, at time.Time) (int, bool) {
+	if len(layer.UserIDs) == 0 || rotationDays(layer.Rotation) == 0 || at.Before(layer.StartAt) {
+		return 0, false
+	}
+	if !restricted(layer, loc, at) {
+		return 0, false
+	}
+	return layer.UserIDs[period(layer, loc, at)%len(layer.UserIDs)], true
+}
+
+// restricted reports whether at falls into the daily restriction of the
+// layer; a layer without restriction covers the whole day.
+func restricted(layer *models.OnCallLayer, loc *time.Location, at time.Time) bool {
+	if layer.RestrictFrom == "" {
+		return true
+	}
+	from, err1 := parseClock(layer.RestrictFrom)
+	to, err2 := parseClock(layer.RestrictTo)
+	if err1 != nil || err2 != nil || from == to {
+		return true
+	}
+
+	local := at.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	if from < to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to
+}
+
+// layerBoundaries returns the moments in (from, to) at which the layer may
+// change hands: rotation handoffs and restriction edges.
+func layerBoundaries(layer *models.OnCallLayer, loc *time.Location, from, to time.Time) []time.Time {
+	var points []time.Time
+	if rotationDays(layer.Rotation) == 0 {
+		return points
+	}
+
+	inRange := func(t time.Time) bool { return t.After(from) && t.Before(to) }
+
+	if inRange(layer.StartAt) {
+		points = append(points, layer.StartAt)
+	}
+	k := 0
+	if from.After(layer.StartAt) {
+		k = period(layer, loc, from)
+	}
+	for t := handoff(layer, loc, k); t.Before(to); t = handoff(layer, loc, k) {
+		if inRange(t) {
+			points = append(points, t)
+		}
+		k++
+	}
+
+	if layer.RestrictFrom != "" {
+		edges := []string{layer.RestrictFrom, layer.RestrictTo}
+		day := from.In(loc)
+		day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+		for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+			for _, edge := range edges {
+				minute, err := parseClock(edge)
+				if err != nil {
+					continue
+				}
+				t := time.Date(day.Year(), day.Month(), day.Day(), minute/60, minute%60, 0, 0, loc)
+				if inRange(t) {
+					points = append(points, t)
+				}
+			}
+		}
+	}
+
+	return points
+}
+
+// parseClock parses "HH:MM" into minutes since midnight.
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
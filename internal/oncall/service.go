@@ -0,0 +1,132 @@
+package oncall
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"ping-tower/internal/database"
+	"ping-tower/internal/models"
+	"ping-tower/internal/notifications"
+)
+
+type Service struct {
+	db *database.DB
+}
+
+func NewService(db *database.DB) *Service {
+	return &Service{db: db}
+}
+
+// WhoIsOnCall returns the shift covering the given moment with its user, or
+// nil if nobody is on call. Start and End are searched within MaxRange.
+func (s *Service) WhoIsOnCall(scheduleID int, at time.Time) (*models.OnCallShift, error) {
+	schedule, err := s.db.GetOnCallSchedule(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	window := 31 * 24 * time.Hour
+	_, shifts, err := s.shifts(schedule, at.Add(-window), at.Add(window))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range shifts {
+		if !at.Before(shifts[i].Start) && at.Before(shifts[i].End) {
+			return &shifts[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Shifts returns the final schedule for [from, to) with users attached.
+func (s *Service) Shifts(scheduleID int, from, to time.Time) (*models.OnCallSchedule, []models.OnCallShift, error) {
+	if !to.After(from) {
+		return nil, nil, fmt.Errorf("to must be after from")
+	}
+	if to.Sub(from) > MaxRange {
+		return nil, nil, fmt.Errorf("range must not exceed %d days", int(MaxRange.Hours()/24))
+	}
+
+	schedule, err := s.db.GetOnCallSchedule(scheduleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.shifts(schedule, from, to)
+}
+
+func (s *Service) shifts(schedule *models.OnCallSchedule, from, to time.Time) (*models.OnCallSchedule, []models.OnCallShift, error) {
+	overrides, err := s.db.GetOnCallOverrides(schedule.ID, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	shifts := Shifts(schedule, overrides, from, to)
+
+	users := map[int]*models.OnCallUser{}
+	for i := range shifts {
+		id := shifts[i].UserID
+		if _, ok := users[id]; !ok {
+			users[id], _ = s.db.GetOnCallUser(id)
+		}
+		shifts[i].User = users[id]
+	}
+
+	return schedule, shifts, nil
+}
+
+// TargetFor returns the contacts of the person on call now, nil if the
+// schedule is disabled or nobody is on call.
+func (s *Service) TargetFor(scheduleID int) *notifications.Target {
+	schedule, err := s.db.GetOnCallSchedule(scheduleID)
+	if err != nil || !schedule.Enabled {
+		return nil
+	}
+
+	now := time.Now()
+	overrides, err := s.db.GetOnCallOverrides(schedule.ID, now, now.Add(time.Second))
+	if err != nil {
+		return nil
+	}
+
+	shift, ok := Resolve(schedule, overrides, now)
+	if !ok {
+		log.Printf("⚠️ По расписанию '%s' сейчас никто не дежурит", schedule.Name)
+		return nil
+	}
+
+	user, err := s.db.GetOnCallUser(shift.UserID)
+	if err != nil {
+		return nil
+	}
+
+	target := &notifications.Target{
+		TelegramChatID: user.TelegramChatID,
+		WebhookURL:     user.WebhookURL,
+	}
+	if user.Email != "" {
+		target.EmailTo = []string{user.Email}
+	}
+	return target
+}
+
+// Route is assigned to notifications.RouteAlert: alerts go to the person on
+// call in the schedule of the global alert configuration.
+func (s *Service) Route(alertData notifications.AlertData) *notifications.Target {
+	// Тестовый алерт проверяет получателей своей конфигурации
+	if alertData.AlertType == "test" {
+		return nil
+	}
+
+	global, err := s.db.GetAlertConfig("global")
+	if err != nil || global.OnCallScheduleID == 0 {
+		return nil
+	}
+
+	target := s.TargetFor(global.OnCallScheduleID)
+	if target != nil {
+		log.Printf("📟 Алерт %s для %s направлен дежурному", alertData.AlertType, alertData.SiteURL)
+	}
+	return target
+}
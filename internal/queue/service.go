@@ -0,0 +1,368 @@
+// Package queue retries failed alert deliveries. Every failed delivery is
+// stored in the notification_queue table and attempted again with an
+// exponential backoff per channel; when it runs out of attempts it is kept as
+// a dead letter until it is resent by hand. Once a newer alert of the same
+// site and family is delivered on the channel, the older queued ones are
+// superseded and never sent: a retried site_down must not open an incident
+// that the delivered site_up already resolved, nor a retried site_up resolve
+// a newer outage.
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"ping-tower/internal/database"
+	"ping-tower/internal/models"
+	"ping-tower/internal/notifications"
+	"ping-tower/internal/routing"
+)
+
+const (
+	// MaxAttempts includes the first delivery.
+	MaxAttempts = 7
+
+	baseBackoff = time.Minute
+	maxBackoff  = time.Hour
+
+	batchSize = 100
+)
+
+// alertFamilies group the alerts that replace each other: an outage and its
+// recovery, a condition and the alert it clears with. Alerts of no family,
+// e.g. packet_loss or digests, are never superseded.
+var alertFamilies = [][]string{
+	{"site_down", "server_error", "site_up", "site_flapping", "flapping_stopped"},
+	{"slow_response", "response_time_recovered"},
+	{"response_time_anomaly", "response_time_normal"},
+	{"status_code_changed", "status_code_restored"},
+	{"content_changed", "content_restored"},
+	{"redirect_changed", "redirect_restored"},
+	{"keyword_lost", "keyword_restored"},
+	{"ssl_expiry", "ssl_renewed"},
+	{"heartbeat_missed", "heartbeat_failed", "heartbeat_recovered"},
+}
+
+// family returns the alert types the alert type replaces, nil for none.
+func family(alertType string) []string {
+	for _, types := range alertFamilies {
+		for _, t := range types {
+			if t == alertType {
+				return types
+			}
+		}
+	}
+	return nil
+}
+
+type Service struct {
+	db           *database.DB
+	alertManager *notifications.AlertManager
+
+	// mu serializes the worker and manual resends so an item is never sent twice
+	mu sync.Mutex
+
+	deliveredMu sync.Mutex
+	// delivered is the time of the newest delivered alert per deliveryKey
+	delivered map[string]time.Time
+}
+
+func NewService(db *database.DB) *Service {
+	return &Service{
+		db:           db,
+		alertManager: nil, // Will be set externally
+		delivered:    make(map[string]time.Time),
+	}
+}
+
+func (s *Service) SetAlertManager(alertManager *notifications.AlertManager) {
+	s.alertManager = alertManager
+}
+
+// Backoff returns the wait before the next attempt after the given number of
+// failed ones: 1, 2, 4, ... minutes, at most an hour.
+func Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	if attempts > 7 {
+		return maxBackoff
+	}
+	return min(baseBackoff<<(attempts-1), maxBackoff)
+}
+
+// Attempted is registered as notifications.DeliveryAttempted. It records the
+// delivery in the alert history and queues it for a retry if it failed, or
+// supersedes the older queued alerts if it succeeded. Test alerts are not
+// retried.
+func (s *Service) Attempted(delivery notifications.Delivery, err error) {
+	s.logAttempt(delivery, 1, err)
+
+	if delivery.Alert.AlertType == "test" {
+		return
+	}
+	if err == nil {
+		s.supersede(delivery)
+		return
+	}
+
+	alertJSON, marshalErr := json.Marshal(delivery.Alert)
+	if marshalErr != nil {
+		log.Printf("❌ Ошибка сериализации алерта для очереди: %v", marshalErr)
+		return
+	}
+
+	next := time.Now().Add(Backoff(1))
+	n := &models.QueuedNotification{
+		SiteID:        delivery.Alert.SiteID,
+		SiteURL:       delivery.Alert.SiteURL,
+		AlertType:     delivery.Alert.AlertType,
+		Channel:       delivery.Channel,
+		ConfigName:    delivery.ConfigName,
+		Alert:         alertJSON,
+		AlertedAt:     alertedAt(delivery.Alert),
+		Status:        models.NotificationStatusPending,
+		Attempts:      1,
+		MaxAttempts:   MaxAttempts,
+		NextAttemptAt: &next,
+		LastError:     err.Error(),
+	}
+	if delivery.Recipients != nil {
+		n.Recipients, _ = json.Marshal(delivery.Recipients)
+	}
+
+	if err := s.db.EnqueueNotification(n); err != nil {
+		log.Printf("❌ Ошибка постановки оповещения в очередь: %v", err)
+		return
+	}
+
+	log.Printf("📥 %s алерт для %s поставлен в очередь #%d, повтор через %s", n.Channel, n.SiteURL, n.ID, Backoff(1))
+}
+
+// ProcessDue retries the queued notifications whose next attempt is due.
+func (s *Service) ProcessDue() error {
+	if s.alertManager == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due, err := s.db.GetDueNotifications(time.Now(), batchSize)
+	if err != nil {
+		return fmt.Errorf("failed to load due notifications: %w", err)
+	}
+
+	for i := range due {
+		if s.stale(&due[i]) {
+			continue
+		}
+		s.attempt(&due[i])
+	}
+
+	return nil
+}
+
+// CreateJob returns a scheduler job that retries due notifications.
+func (s *Service) CreateJob() func() error {
+	return func() error {
+		return s.ProcessDue()
+	}
+}
+
+// Resend attempts a pending or dead notification right away, e.g. after the
+// channel was fixed. A dead letter that fails again stays dead.
+func (s *Service) Resend(id int) (*models.QueuedNotification, error) {
+	if s.alertManager == nil {
+		return nil, fmt.Errorf("alert manager not configured")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, err := s.db.GetQueuedNotification(id)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case n.Status == models.NotificationStatusSent:
+		return nil, fmt.Errorf("notification is already sent")
+	case n.Status == models.NotificationStatusSuperseded || s.stale(n):
+		return nil, fmt.Errorf("notification is superseded by a newer alert")
+	}
+
+	log.Printf("🔁 Повторная отправка оповещения #%d вручную", n.ID)
+	s.attempt(n)
+	return n, nil
+}
+
+// attempt delivers the notification once more and stores the outcome.
+func (s *Service) attempt(n *models.QueuedNotification) {
+	delivery, err := s.delivery(n)
+	if err == nil {
+		err = s.redeliver(delivery)
+	}
+
+	n.Attempts++
+	s.logAttempt(delivery, n.Attempts, err)
+
+	now := time.Now()
+	switch {
+	case err == nil:
+		n.Status = models.NotificationStatusSent
+		n.SentAt = &now
+		n.NextAttemptAt = nil
+		n.LastError = ""
+		log.Printf("📤 Оповещение #%d доставлено с попытки %d", n.ID, n.Attempts)
+	case n.Status == models.NotificationStatusDead || n.Attempts >= n.MaxAttempts:
+		n.Status = models.NotificationStatusDead
+		n.NextAttemptAt = nil
+		n.LastError = err.Error()
+		log.Printf("💀 Оповещение #%d (%s, %s) не доставлено за %d попыток: %v", n.ID, n.Channel, n.SiteURL, n.Attempts, err)
+	default:
+		next := now.Add(Backoff(n.Attempts))
+		n.NextAttemptAt = &next
+		n.LastError = err.Error()
+		log.Printf("⏳ Оповещение #%d: попытка %d/%d не удалась, следующая через %s", n.ID, n.Attempts, n.MaxAttempts, Backoff(n.Attempts))
+	}
+
+	if err := s.db.UpdateNotificationState(n); err != nil {
+		log.Printf("❌ Ошибка сохранения оповещения #%d: %v", n.ID, err)
+	}
+
+	if n.Status == models.NotificationStatusSent {
+		s.supersede(delivery)
+	}
+}
+
+// supersede remembers the delivered alert and marks the queued notifications
+// of older alerts of its family for the same site, channel and alert
+// configuration as superseded.
+func (s *Service) supersede(delivery notifications.Delivery) {
+	types := family(delivery.Alert.AlertType)
+	if types == nil {
+		return
+	}
+
+	at := alertedAt(delivery.Alert)
+	key := deliveryKey(delivery.Alert.SiteID, delivery.Alert.SiteURL, delivery.Alert.AlertType, delivery.Channel, delivery.ConfigName)
+
+	s.deliveredMu.Lock()
+	if at.After(s.delivered[key]) {
+		s.delivered[key] = at
+	}
+	s.deliveredMu.Unlock()
+
+	count, err := s.db.SupersedeNotifications(delivery.Alert.SiteID, delivery.Alert.SiteURL, types,
+		delivery.Channel, delivery.ConfigName, at)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return
+	}
+	if count > 0 {
+		log.Printf("🗑️ %d устаревших %s оповещений для %s отменено: доставлен более новый алерт %s",
+			count, delivery.Channel, delivery.Alert.SiteURL, delivery.Alert.AlertType)
+	}
+}
+
+// stale reports whether a newer alert of its family than the notification was
+// delivered on its channel, e.g. while the worker was busy with the batch; a stale
+// notification is marked as superseded.
+func (s *Service) stale(n *models.QueuedNotification) bool {
+	s.deliveredMu.Lock()
+	delivered, ok := s.delivered[deliveryKey(n.SiteID, n.SiteURL, n.AlertType, n.Channel, n.ConfigName)]
+	s.deliveredMu.Unlock()
+
+	if !ok || !n.AlertedAt.Before(delivered) {
+		return false
+	}
+
+	n.Status = models.NotificationStatusSuperseded
+	n.NextAttemptAt = nil
+	if err := s.db.UpdateNotificationState(n); err != nil {
+		log.Printf("❌ Ошибка сохранения оповещения #%d: %v", n.ID, err)
+	}
+	log.Printf("🗑️ Оповещение #%d (%s, %s) отменено: доставлен более новый алерт", n.ID, n.Channel, n.SiteURL)
+	return true
+}
+
+// deliveryKey identifies the alerts that supersede each other: those of a
+// family and site, or of a URL for alerts without a site, on a channel of an
+// alert configuration. Alerts of no family have no key.
+func deliveryKey(siteID int, siteURL, alertType, channel, configName string) string {
+	types := family(alertType)
+	if types == nil {
+		return ""
+	}
+	if siteID != 0 {
+		siteURL = ""
+	}
+	return fmt.Sprintf("%d|%s|%s|%s|%s", siteID, siteURL, types[0], channel, configName)
+}
+
+// alertedAt returns when the alert was raised.
+func alertedAt(alert notifications.AlertData) time.Time {
+	if alert.Timestamp.IsZero() {
+		return time.Now()
+	}
+	return alert.Timestamp
+}
+
+// delivery restores the delivery of the notification. Routed deliveries use
+// the current state of their alert configuration.
+func (s *Service) delivery(n *models.QueuedNotification) (notifications.Delivery, error) {
+	delivery := notifications.Delivery{Channel: n.Channel, ConfigName: n.ConfigName}
+	if err := json.Unmarshal(n.Alert, &delivery.Alert); err != nil {
+		return delivery, fmt.Errorf("corrupted alert payload: %v", err)
+	}
+	if len(n.Recipients) > 0 {
+		delivery.Recipients = &notifications.Target{}
+		if err := json.Unmarshal(n.Recipients, delivery.Recipients); err != nil {
+			return delivery, fmt.Errorf("corrupted recipients: %v", err)
+		}
+	}
+	return delivery, nil
+}
+
+// redeliver sends the delivery with the manager of its alert configuration.
+func (s *Service) redeliver(delivery notifications.Delivery) error {
+	if delivery.ConfigName == "" {
+		return s.alertManager.Redeliver(delivery)
+	}
+
+	cfg, err := s.db.GetAlertConfig(delivery.ConfigName)
+	if err != nil {
+		return err
+	}
+	if !cfg.Enabled {
+		return fmt.Errorf("alert config '%s' is disabled", cfg.Name)
+	}
+	return notifications.NewAlertManager(routing.AlertsConfig(cfg)).Redeliver(delivery)
+}
+
+// logAttempt records the attempt in alert_history under the alert
+// configuration of the delivery.
+func (s *Service) logAttempt(delivery notifications.Delivery, attempt int, err error) {
+	name := delivery.ConfigName
+	if name == "" {
+		name = "global"
+	}
+
+	configID := 0
+	if cfg, lookupErr := s.db.GetAlertConfig(name); lookupErr == nil {
+		configID = cfg.ID
+	}
+
+	status, message, errorMessage := "sent", fmt.Sprintf("attempt %d", attempt), ""
+	if err != nil {
+		status, errorMessage = "failed", err.Error()
+	}
+
+	if logErr := s.db.LogAlert(delivery.Alert.SiteID, configID, delivery.Alert.AlertType, delivery.Channel,
+		status, message, errorMessage); logErr != nil {
+		log.Printf("⚠️ Ошибка записи истории оповещений: %v", logErr)
+	}
+}
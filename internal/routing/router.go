@@ -0,0 +1,226 @@
+package routing
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"ping-tower/internal/config"
+	"ping-tower/internal/database"
+	"ping-tower/internal/models"
+	"ping-tower/internal/notifications"
+	"ping-tower/internal/oncall"
+)
+
+// Router sends the alerts of a site to the alert configurations assigned to
+// it in site_alert_configs. Sites without an enabled assignment use the
+// global configuration.
+type Router struct {
+	db     *database.DB
+	oncall *oncall.Service
+}
+
+func NewRouter(db *database.DB) *Router {
+	return &Router{db: db}
+}
+
+func (r *Router) SetOnCallService(service *oncall.Service) {
+	r.oncall = service
+}
+
+// Configs returns the enabled alert configurations assigned to the site.
+func (r *Router) Configs(siteID int) []models.AlertConfig {
+	if siteID <= 0 {
+		return nil
+	}
+
+	configs, err := r.db.GetSiteAlertConfigs(siteID)
+	if err != nil {
+		log.Printf("⚠️ Ошибка получения конфигураций алертов сайта %d: %v", siteID, err)
+		return nil
+	}
+
+	enabled := configs[:0]
+	for _, cfg := range configs {
+		if cfg.Enabled {
+			enabled = append(enabled, cfg)
+		}
+	}
+	return enabled
+}
+
+// ConditionConfigs returns the configurations whose conditions decide which
+// alerts a site check raises: the assigned ones or the global one.
+func (r *Router) ConditionConfigs(siteID int) []models.AlertConfig {
+	if configs := r.Configs(siteID); len(configs) > 0 {
+		return configs
+	}
+	if global, err := r.db.GetAlertConfig("global"); err == nil {
+		return []models.AlertConfig{*global}
+	}
+	return nil
+}
+
+// Routes is assigned to notifications.SiteRoutes.
+func (r *Router) Routes(siteID int) []notifications.Route {
+	configs := r.Configs(siteID)
+	if len(configs) == 0 {
+		return nil
+	}
+
+	routes := make([]notifications.Route, 0, len(configs))
+	for _, cfg := range configs {
+		routes = append(routes, r.Route(cfg))
+	}
+	return routes
+}
+
+// Route returns the route of the alert configuration, to the person on call
+// when it has a schedule.
+func (r *Router) Route(cfg models.AlertConfig) notifications.Route {
+	route := notifications.Route{
+		Name:   cfg.Name,
+		Config: AlertsConfig(&cfg),
+		Accepts: func(alertType string, result notifications.CheckResult) bool {
+			return Accepts(&cfg, alertType, result)
+		},
+	}
+	if cfg.OnCallScheduleID > 0 && r.oncall != nil {
+		route.Recipients = r.oncall.TargetFor(cfg.OnCallScheduleID)
+	}
+	return route
+}
+
+// Conditions returns the alerts whose conditions of the configuration the
+// check result meets. Recoveries are not conditions, the alert engine sends
+// them when a condition clears.
+func Conditions(cfg *models.AlertConfig, result notifications.CheckResult) []string {
+	var alertTypes []string
+	switch {
+	case result.Status == "down" && cfg.AlertOnDown:
+		alertTypes = append(alertTypes, "site_down")
+	case result.StatusCode >= 500 && cfg.AlertOnDown:
+		alertTypes = append(alertTypes, "server_error")
+	}
+	if cfg.AlertOnPacketLoss && result.PacketsSent > 0 && result.PacketLoss >= cfg.PacketLossThreshold {
+		alertTypes = append(alertTypes, "packet_loss")
+	}
+	if result.Status == "up" && cfg.AlertOnResponseTimeThreshold && result.ResponseTime > int64(cfg.ResponseTimeThreshold) {
+		alertTypes = append(alertTypes, "slow_response")
+	}
+	if result.Status == "up" && cfg.AlertOnResponseTimeAnomaly && anomalous(cfg, result) {
+		alertTypes = append(alertTypes, "response_time_anomaly")
+	}
+	return alertTypes
+}
+
+// DefaultAnomalySigmas is used by configurations that set no number of sigmas.
+const DefaultAnomalySigmas = 3.0
+
+// anomalous reports whether the response time is further above the baseline
+// than the configuration allows; checks without a baseline never are.
+func anomalous(cfg *models.AlertConfig, result notifications.CheckResult) bool {
+	sigmas := cfg.ResponseTimeAnomalySigmas
+	if sigmas <= 0 {
+		sigmas = DefaultAnomalySigmas
+	}
+	return result.BaselineResponseTime > 0 && result.ResponseTimeDeviation >= sigmas
+}
+
+// Accepts reports whether the conditions of the configuration allow the
+// alert. Types without a condition, e.g. test alerts, are always accepted.
+func Accepts(cfg *models.AlertConfig, alertType string, result notifications.CheckResult) bool {
+	switch alertType {
+	case "site_down", "server_error", "site_flapping", "flapping_stopped":
+		return cfg.AlertOnDown
+	case "site_up":
+		return cfg.AlertOnUp
+	case "status_change":
+		return cfg.AlertOnDown || cfg.AlertOnStatusCodeChange
+	case "packet_loss":
+		return cfg.AlertOnPacketLoss && result.PacketLoss >= cfg.PacketLossThreshold
+	case "slow_response":
+		return cfg.AlertOnResponseTimeThreshold && result.ResponseTime > int64(cfg.ResponseTimeThreshold)
+	case "response_time_recovered":
+		return cfg.AlertOnResponseTimeThreshold
+	case "response_time_anomaly":
+		return cfg.AlertOnResponseTimeAnomaly && anomalous(cfg, result)
+	case "response_time_normal":
+		return cfg.AlertOnResponseTimeAnomaly
+	case "status_code_changed", "status_code_restored":
+		return cfg.AlertOnStatusCodeChange
+	case "content_changed", "content_restored":
+		return cfg.AlertOnContentChange
+	case "redirect_changed", "redirect_restored":
+		return cfg.AlertOnRedirectChange
+	case "keyword_lost", "keyword_restored":
+		return cfg.AlertOnKeywordLost
+	case "ssl_expiry":
+		if result.SSLExpiry == nil || cfg.SSLExpiryDays <= 0 {
+			return cfg.AlertOnSSLExpiry
+		}
+		return cfg.AlertOnSSLExpiry && int(time.Until(*result.SSLExpiry).Hours()/24) <= cfg.SSLExpiryDays
+	case "ssl_renewed":
+		return cfg.AlertOnSSLExpiry
+	}
+	return true
+}
+
+// AlertsConfig converts a stored alert configuration into the one used by
+// the notifications package.
+func AlertsConfig(dbConfig *models.AlertConfig) *config.AlertsConfig {
+	var emailTo []string
+	for _, email := range strings.Split(dbConfig.EmailTo, ",") {
+		if email = strings.TrimSpace(email); email != "" {
+			emailTo = append(emailTo, email)
+		}
+	}
+
+	return &config.AlertsConfig{
+		Enabled: dbConfig.Enabled,
+		Email: config.EmailAlertConfig{
+			Enabled:    dbConfig.EmailEnabled,
+			SMTPServer: dbConfig.SMTPServer,
+			Port:       dbConfig.SMTPPort,
+			Username:   dbConfig.SMTPUsername,
+			Password:   dbConfig.SMTPPassword,
+			From:       dbConfig.EmailFrom,
+			To:         emailTo,
+		},
+		Webhook: config.WebhookAlertConfig{
+			Enabled: dbConfig.WebhookEnabled,
+			URL:     dbConfig.WebhookURL,
+			Headers: dbConfig.WebhookHeaders,
+			Timeout: dbConfig.WebhookTimeout,
+			Secret:  dbConfig.WebhookSecret,
+		},
+		Telegram: config.TelegramAlertConfig{
+			Enabled:  dbConfig.TelegramEnabled,
+			BotToken: dbConfig.TelegramBotToken,
+			ChatID:   dbConfig.TelegramChatID,
+		},
+		Slack: config.SlackAlertConfig{
+			Enabled:    dbConfig.SlackEnabled,
+			WebhookURL: dbConfig.SlackWebhookURL,
+			BotToken:   dbConfig.SlackBotToken,
+			Channel:    dbConfig.SlackChannel,
+		},
+		Discord: config.DiscordAlertConfig{
+			Enabled:    dbConfig.DiscordEnabled,
+			WebhookURL: dbConfig.DiscordWebhookURL,
+		},
+		Teams: config.TeamsAlertConfig{
+			Enabled:    dbConfig.TeamsEnabled,
+			WebhookURL: dbConfig.TeamsWebhookURL,
+		},
+		PagerDuty: config.PagerDutyAlertConfig{
+			Enabled:    dbConfig.PagerDutyEnabled,
+			RoutingKey: dbConfig.PagerDutyRoutingKey,
+		},
+		Opsgenie: config.OpsgenieAlertConfig{
+			Enabled: dbConfig.OpsgenieEnabled,
+			APIKey:  dbConfig.OpsgenieAPIKey,
+			Region:  dbConfig.OpsgenieRegion,
+		},
+	}
+}
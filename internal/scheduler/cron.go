@@ -153,8 +153,20 @@ func (cs *CronScheduler) GetJobs() map[string]*Job {
 
 	result := make(map[string]*Job)
 	for id, job := range cs.jobs {
-		jobCopy := *job
-		result[id] = &jobCopy
+		job.mutex.Lock()
+		result[id] = &Job{
+			ID:         job.ID,
+			Name:       job.Name,
+			Schedule:   job.Schedule,
+			LastRun:    job.LastRun,
+			NextRun:    job.NextRun,
+			Running:    job.Running,
+			Enabled:    job.Enabled,
+			ErrorCount: job.ErrorCount,
+			LastError:  job.LastError,
+			RunCount:   job.RunCount,
+		}
+		job.mutex.Unlock()
 	}
 
 	return result
@@ -242,11 +254,15 @@ func (cs *CronScheduler) runJob(job *Job, now time.Time) {
 }
 
 func (cs *CronScheduler) calculateNextRun(schedule *Schedule, from time.Time) time.Time {
+	return schedule.Next(from)
+}
 
+// Next returns the first minute after from that matches the schedule.
+func (schedule *Schedule) Next(from time.Time) time.Time {
 	next := from.Add(time.Minute).Truncate(time.Minute)
 
 	for i := 0; i < 366*24*60; i++ {
-		if cs.matchesSchedule(schedule, next) {
+		if schedule.Matches(next) {
 			return next
 		}
 		next = next.Add(time.Minute)
@@ -255,7 +271,8 @@ func (cs *CronScheduler) calculateNextRun(schedule *Schedule, from time.Time) ti
 	return from.Add(365 * 24 * time.Hour)
 }
 
-func (cs *CronScheduler) matchesSchedule(schedule *Schedule, t time.Time) bool {
+// Matches reports whether the minute of t is part of the schedule.
+func (schedule *Schedule) Matches(t time.Time) bool {
 	minute := t.Minute()
 	hour := t.Hour()
 	day := t.Day()
@@ -0,0 +1,172 @@
+// Package snapshots stores the content of sites whenever its hash changes, so
+// a content change can be inspected and compared with what was served before,
+// e.g. to tell a defacement from a routine update. The content is stored with
+// the content masks of the site applied, compressed and capped in size; only
+// the newest snapshots of every site are kept.
+package snapshots
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"ping-tower/internal/config"
+	"ping-tower/internal/database"
+	"ping-tower/internal/models"
+	"ping-tower/internal/monitor"
+)
+
+type Service struct {
+	db     *database.DB
+	config config.SnapshotConfig
+
+	mu sync.Mutex
+	// latest is the hash of the newest snapshot per site, loaded on first use
+	latest map[int]string
+}
+
+func NewService(db *database.DB, cfg config.SnapshotConfig) *Service {
+	return &Service{
+		db:     db,
+		config: cfg,
+	}
+}
+
+// Record stores the content of the check when its hash differs from the
+// newest snapshot of the site.
+func (s *Service) Record(siteID int, siteURL string, result monitor.CheckResult) {
+	if result.Content == nil || result.ContentHash == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.latest == nil {
+		latest, err := s.db.GetLatestContentHashes()
+		if err != nil {
+			log.Printf("❌ %v", err)
+			return
+		}
+		s.latest = latest
+	}
+	if s.latest[siteID] == result.ContentHash {
+		return
+	}
+
+	content := result.Content
+	truncated := false
+	if len(content) > s.config.MaxBytes {
+		content = content[:s.config.MaxBytes]
+		truncated = true
+	}
+
+	data, err := compress(content)
+	if err != nil {
+		log.Printf("❌ Ошибка сжатия снимка контента %s: %v", siteURL, err)
+		return
+	}
+
+	snapshot := &models.ContentSnapshot{
+		SiteID:       siteID,
+		ContentHash:  result.ContentHash,
+		StatusCode:   result.StatusCode,
+		Size:         len(content),
+		OriginalSize: len(result.Content),
+		Truncated:    truncated,
+		Data:         data,
+	}
+	if err := s.db.CreateContentSnapshot(snapshot); err != nil {
+		log.Printf("❌ %v", err)
+		return
+	}
+	s.latest[siteID] = result.ContentHash
+	log.Printf("📸 Сохранен снимок контента %s: хэш %s, %d байт (%d сжато)", siteURL, result.ContentHash, len(content), len(data))
+
+	if err := s.db.PruneContentSnapshots(siteID, s.config.Retention); err != nil {
+		log.Printf("❌ %v", err)
+	}
+}
+
+// Get returns a snapshot of the site with its content.
+func (s *Service) Get(siteID, id int) (*models.ContentSnapshot, error) {
+	snapshot, err := s.db.GetContentSnapshot(siteID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := decompress(snapshot.Data)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot %d is corrupted: %v", id, err)
+	}
+	snapshot.Content = string(content)
+	snapshot.Data = nil
+	return snapshot, nil
+}
+
+// Diff compares two snapshots of the site. Without toID the newest snapshot
+// is used, without fromID the one stored before it.
+func (s *Service) Diff(siteID, fromID, toID int) (*models.ContentSnapshotDiff, error) {
+	var err error
+	if toID == 0 {
+		if toID, err = s.db.GetPreviousContentSnapshotID(siteID, 0); err != nil {
+			return nil, err
+		}
+		if toID == 0 {
+			return nil, fmt.Errorf("no snapshots stored for the site")
+		}
+	}
+	if fromID == 0 {
+		if fromID, err = s.db.GetPreviousContentSnapshotID(siteID, toID); err != nil {
+			return nil, err
+		}
+		if fromID == 0 {
+			return nil, fmt.Errorf("no snapshot stored before snapshot %d", toID)
+		}
+	}
+
+	from, err := s.Get(siteID, fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.Get(siteID, toID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := unified(snapshotName(from), snapshotName(to), diffLines(splitLines(from.Content), splitLines(to.Content)))
+	from.Content, to.Content = "", ""
+	return &models.ContentSnapshotDiff{From: *from, To: *to, Diff: diff}, nil
+}
+
+func snapshotName(snapshot *models.ContentSnapshot) string {
+	return fmt.Sprintf("snapshot %d\t%s", snapshot.ID, snapshot.CreatedAt.Format(time.RFC3339))
+}
+
+func compress(content []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(content); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
@@ -0,0 +1,151 @@
+// Package sslexpiry alerts on expiring SSL certificates. A daily job compares
+// the certificate expiry recorded by the last check of every site with the
+// thresholds of the site and sends one alert per threshold crossed. The
+// smallest threshold alerted is kept in ssl_expiry_alerts, so restarts do not
+// repeat alerts; a certificate with a different expiry is a renewed one and
+// starts over.
+package sslexpiry
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"ping-tower/internal/database"
+	"ping-tower/internal/models"
+	"ping-tower/internal/notifications"
+	"ping-tower/internal/routing"
+)
+
+const (
+	AlertExpiry  = "ssl_expiry"
+	AlertRenewed = "ssl_renewed"
+)
+
+type Service struct {
+	db           *database.DB
+	router       *routing.Router
+	alertManager *notifications.AlertManager
+
+	// mu keeps the job and a manual run from alerting twice
+	mu sync.Mutex
+}
+
+func NewService(db *database.DB, router *routing.Router) *Service {
+	return &Service{
+		db:     db,
+		router: router,
+	}
+}
+
+func (s *Service) SetAlertManager(alertManager *notifications.AlertManager) {
+	s.alertManager = alertManager
+}
+
+// CheckAll evaluates the certificates of all sites.
+func (s *Service) CheckAll() error {
+	if s.alertManager == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	certificates, err := s.db.GetSSLCertificates()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range certificates {
+		s.check(&certificates[i], now)
+	}
+	return nil
+}
+
+// CreateJob returns a scheduler job that evaluates the certificates.
+func (s *Service) CreateJob() func() error {
+	return func() error {
+		return s.CheckAll()
+	}
+}
+
+func (s *Service) check(cert *models.SSLCertificate, now time.Time) {
+	if cert.Alert != nil && !cert.Alert.SSLExpiry.Equal(cert.Expiry) {
+		log.Printf("🔐 SSL сертификат %s обновлен: действует до %s", cert.SiteURL, cert.Expiry.Format("2006-01-02"))
+		if err := s.db.DeleteSSLExpiryAlert(cert.SiteID); err != nil {
+			log.Printf("❌ %v", err)
+			return
+		}
+		if cert.Expiry.After(now) {
+			s.send(cert, AlertRenewed, "")
+		}
+		cert.Alert = nil
+	}
+
+	if !cert.Enabled || !cert.CheckSSL {
+		return
+	}
+
+	threshold, crossed := Crossed(Thresholds(cert), cert.Expiry, now)
+	if !crossed || (cert.Alert != nil && cert.Alert.Threshold <= threshold) {
+		return
+	}
+
+	message := fmt.Sprintf("SSL certificate expires in %d days (%s)", DaysLeft(cert.Expiry, now), cert.Expiry.Format("2006-01-02"))
+	if threshold == 0 {
+		message = fmt.Sprintf("SSL certificate expired on %s", cert.Expiry.Format("2006-01-02"))
+	}
+	if !s.send(cert, AlertExpiry, message) {
+		return
+	}
+
+	err := s.db.SaveSSLExpiryAlert(&models.SSLExpiryAlert{
+		SiteID:    cert.SiteID,
+		SSLExpiry: cert.Expiry,
+		Threshold: threshold,
+		AlertedAt: now,
+	})
+	if err != nil {
+		log.Printf("❌ %v", err)
+	}
+}
+
+// send delivers the alert and reports whether any alert configuration of the
+// site accepts it; otherwise the threshold is not recorded, so enabling the
+// alerts later still reports it.
+func (s *Service) send(cert *models.SSLCertificate, alertType, message string) bool {
+	expiry := cert.Expiry
+	result := notifications.CheckResult{
+		Status:    cert.SiteStatus,
+		SSLValid:  alertType == AlertRenewed || expiry.After(time.Now()),
+		SSLExpiry: &expiry,
+		SSLIssuer: cert.Issuer,
+		Error:     message,
+	}
+
+	if !s.accepted(cert.SiteID, alertType, result) {
+		return false
+	}
+
+	log.Printf("🔐 SSL алерт %s для %s: %s", alertType, cert.SiteURL, message)
+	if err := s.alertManager.SendAlert(cert.SiteID, cert.SiteURL, result, alertType); err != nil {
+		log.Printf("⚠️ Ошибка отправки SSL алерта для %s: %v", cert.SiteURL, err)
+	}
+	return true
+}
+
+func (s *Service) accepted(siteID int, alertType string, result notifications.CheckResult) bool {
+	configs := s.router.ConditionConfigs(siteID)
+	if len(configs) == 0 {
+		// Только конфигурация из переменных окружения - условий нет
+		return true
+	}
+	for i := range configs {
+		if routing.Accepts(&configs[i], alertType, result) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package sslexpiry
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ping-tower/internal/models"
+)
+
+// DefaultThresholds are used when a site sets no thresholds of its own;
+// those above its ssl_alert_days are left out.
+var DefaultThresholds = []int{30, 14, 7, 1}
+
+const maxThreshold = 3650
+
+// ParseThresholds parses a comma separated list of days, e.g. "30,14,7,1".
+// The result is sorted from the earliest warning to the last one.
+func ParseThresholds(spec string) ([]int, error) {
+	var thresholds []int
+	seen := map[int]bool{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		days, err := strconv.Atoi(part)
+		if err != nil || days < 1 || days > maxThreshold {
+			return nil, fmt.Errorf("invalid SSL alert threshold %q: expected days between 1 and %d", part, maxThreshold)
+		}
+		if !seen[days] {
+			seen[days] = true
+			thresholds = append(thresholds, days)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(thresholds)))
+	return thresholds, nil
+}
+
+// Thresholds returns the thresholds of the certificate's site.
+func Thresholds(cert *models.SSLCertificate) []int {
+	if thresholds, err := ParseThresholds(cert.Thresholds); err == nil && len(thresholds) > 0 {
+		return thresholds
+	}
+
+	alertDays := cert.AlertDays
+	if alertDays <= 0 {
+		alertDays = DefaultThresholds[0]
+	}
+	thresholds := []int{alertDays}
+	for _, days := range DefaultThresholds {
+		if days < alertDays {
+			thresholds = append(thresholds, days)
+		}
+	}
+	return thresholds
+}
+
+// DaysLeft returns the whole days until the expiry.
+func DaysLeft(expiry, now time.Time) int {
+	return int(expiry.Sub(now).Hours() / 24)
+}
+
+// Crossed returns the smallest threshold the certificate has reached, 0 once
+// it has expired. The thresholds must be sorted from the largest.
+func Crossed(thresholds []int, expiry, now time.Time) (int, bool) {
+	if !expiry.After(now) {
+		return 0, true
+	}
+
+	daysLeft := DaysLeft(expiry, now)
+	crossed, ok := 0, false
+	for _, days := range thresholds {
+		if daysLeft <= days {
+			crossed, ok = days, true
+		}
+	}
+	return crossed, ok
+}
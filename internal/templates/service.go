@@ -0,0 +1,84 @@
+// Package templates keeps the alert templates stored in Postgres in memory
+// for the notifications package.
+package templates
+
+import (
+	"log"
+	"strings"
+	"sync"
+
+	"ping-tower/internal/database"
+	"ping-tower/internal/models"
+	"ping-tower/internal/notifications"
+)
+
+type Service struct {
+	db *database.DB
+
+	mu        sync.RWMutex
+	loaded    bool
+	templates map[string]*models.AlertTemplate
+}
+
+func NewService(db *database.DB) *Service {
+	return &Service{db: db}
+}
+
+// Validate normalizes the template and checks that it renders.
+func Validate(t *models.AlertTemplate) error {
+	t.Channel = strings.TrimSpace(t.Channel)
+	t.AlertType = strings.TrimSpace(t.AlertType)
+	if t.Format == "" {
+		t.Format = models.TemplateFormatText
+	}
+	return notifications.CheckTemplate(t)
+}
+
+// Reload reads the enabled templates, it must be called after every change.
+func (s *Service) Reload() error {
+	templates, err := s.db.GetAlertTemplates()
+	if err != nil {
+		return err
+	}
+
+	byKey := make(map[string]*models.AlertTemplate)
+	for i := range templates {
+		if templates[i].Enabled {
+			byKey[key(templates[i].Channel, templates[i].AlertType)] = &templates[i]
+		}
+	}
+
+	s.mu.Lock()
+	s.templates = byKey
+	s.loaded = true
+	s.mu.Unlock()
+
+	log.Printf("📝 Загружено шаблонов оповещений: %d", len(byKey))
+	return nil
+}
+
+// Lookup returns the template of the channel for the alert type, falling
+// back to the template of the channel for all types.
+func (s *Service) Lookup(channel, alertType string) *models.AlertTemplate {
+	s.mu.RLock()
+	loaded := s.loaded
+	s.mu.RUnlock()
+
+	if !loaded {
+		if err := s.Reload(); err != nil {
+			log.Printf("⚠️ Ошибка загрузки шаблонов оповещений: %v", err)
+			return nil
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if t, ok := s.templates[key(channel, alertType)]; ok {
+		return t
+	}
+	return s.templates[key(channel, "")]
+}
+
+func key(channel, alertType string) string {
+	return channel + "|" + alertType
+}
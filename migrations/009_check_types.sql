@@ -0,0 +1,35 @@
+-- Add check type support (http, tcp) to site configurations
+DO $$
+BEGIN
+    -- Type of check performed for the site
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'check_type') THEN
+        ALTER TABLE site_configs ADD COLUMN check_type VARCHAR(20) DEFAULT 'http';
+    END IF;
+
+    -- Explicit host:port target (otherwise taken from the site URL)
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'target') THEN
+        ALTER TABLE site_configs ADD COLUMN target VARCHAR(255) DEFAULT '';
+    END IF;
+
+    -- TCP connect timeout in seconds
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'connect_timeout') THEN
+        ALTER TABLE site_configs ADD COLUMN connect_timeout INTEGER DEFAULT 10;
+    END IF;
+
+    -- Optional payload sent after connect
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'tcp_send') THEN
+        ALTER TABLE site_configs ADD COLUMN tcp_send TEXT DEFAULT '';
+    END IF;
+
+    -- Optional banner expected in the response
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'tcp_expect') THEN
+        ALTER TABLE site_configs ADD COLUMN tcp_expect TEXT DEFAULT '';
+    END IF;
+END $$;
+
+-- Sites added with a tcp:// URL are TCP checks
+UPDATE site_configs c
+SET check_type = 'tcp',
+    updated_at = CURRENT_TIMESTAMP
+FROM sites s
+WHERE s.id = c.site_id AND s.url LIKE 'tcp://%' AND COALESCE(c.check_type, 'http') = 'http';
@@ -0,0 +1,34 @@
+-- Add ICMP ping settings and packet loss alert rules
+DO $$
+BEGIN
+    -- Number of echo requests sent per check
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'ping_count') THEN
+        ALTER TABLE site_configs ADD COLUMN ping_count INTEGER DEFAULT 4;
+    END IF;
+
+    -- Interval between echo requests in milliseconds
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'ping_interval') THEN
+        ALTER TABLE site_configs ADD COLUMN ping_interval INTEGER DEFAULT 1000;
+    END IF;
+
+    -- Packet loss percent above which the site is considered down (0 = only full loss)
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'max_packet_loss') THEN
+        ALTER TABLE site_configs ADD COLUMN max_packet_loss DOUBLE PRECISION DEFAULT 0;
+    END IF;
+
+    -- Alert when packet loss reaches the threshold
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'alert_on_packet_loss') THEN
+        ALTER TABLE alert_configs ADD COLUMN alert_on_packet_loss BOOLEAN DEFAULT FALSE;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'packet_loss_threshold') THEN
+        ALTER TABLE alert_configs ADD COLUMN packet_loss_threshold DOUBLE PRECISION DEFAULT 20;
+    END IF;
+END $$;
+
+-- Sites added with an icmp:// URL are ICMP checks
+UPDATE site_configs c
+SET check_type = 'icmp',
+    updated_at = CURRENT_TIMESTAMP
+FROM sites s
+WHERE s.id = c.site_id AND s.url LIKE 'icmp://%' AND COALESCE(c.check_type, 'http') = 'http';
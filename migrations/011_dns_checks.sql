@@ -0,0 +1,25 @@
+-- Add DNS record check settings to site configurations
+DO $$
+BEGIN
+    -- Record type to query: A, AAAA, CNAME, MX, TXT, NS
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'dns_record_type') THEN
+        ALTER TABLE site_configs ADD COLUMN dns_record_type VARCHAR(10) DEFAULT 'A';
+    END IF;
+
+    -- Resolver host[:port] (empty = system resolver)
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'dns_resolver') THEN
+        ALTER TABLE site_configs ADD COLUMN dns_resolver VARCHAR(255) DEFAULT '';
+    END IF;
+
+    -- Expected answers, one per line (empty = any answer)
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'dns_expected') THEN
+        ALTER TABLE site_configs ADD COLUMN dns_expected TEXT DEFAULT '';
+    END IF;
+END $$;
+
+-- Sites added with a dns:// URL are DNS checks
+UPDATE site_configs c
+SET check_type = 'dns',
+    updated_at = CURRENT_TIMESTAMP
+FROM sites s
+WHERE s.id = c.site_id AND s.url LIKE 'dns://%' AND COALESCE(c.check_type, 'http') = 'http';
@@ -0,0 +1,25 @@
+-- Add standalone TLS check settings to site configurations
+DO $$
+BEGIN
+    -- STARTTLS protocol: smtp, imap, postgres (empty = implicit TLS)
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'tls_starttls') THEN
+        ALTER TABLE site_configs ADD COLUMN tls_starttls VARCHAR(20) DEFAULT '';
+    END IF;
+
+    -- Host name sent as SNI and matched against the certificate (empty = target host)
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'tls_server_name') THEN
+        ALTER TABLE site_configs ADD COLUMN tls_server_name VARCHAR(255) DEFAULT '';
+    END IF;
+
+    -- Do not fail the check for chains signed by a private CA
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'tls_allow_untrusted') THEN
+        ALTER TABLE site_configs ADD COLUMN tls_allow_untrusted BOOLEAN DEFAULT FALSE;
+    END IF;
+END $$;
+
+-- Sites added with a tls:// URL are TLS checks
+UPDATE site_configs c
+SET check_type = 'tls',
+    updated_at = CURRENT_TIMESTAMP
+FROM sites s
+WHERE s.id = c.site_id AND s.url LIKE 'tls://%' AND COALESCE(c.check_type, 'http') = 'http';
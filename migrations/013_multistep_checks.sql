@@ -0,0 +1,18 @@
+-- Add multi-step HTTP transaction checks
+DO $$
+BEGIN
+    -- Ordered list of HTTP steps with captures and assertions
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'steps') THEN
+        ALTER TABLE site_configs ADD COLUMN steps JSONB DEFAULT '[]';
+    END IF;
+
+    -- Name of the step that failed
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_history' AND column_name = 'failed_step') THEN
+        ALTER TABLE site_history ADD COLUMN failed_step VARCHAR(255) DEFAULT '';
+    END IF;
+
+    -- Per-step status codes and timings
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_history' AND column_name = 'step_results') THEN
+        ALTER TABLE site_history ADD COLUMN step_results JSONB;
+    END IF;
+END $$;
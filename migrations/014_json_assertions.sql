@@ -0,0 +1,8 @@
+-- Add JSON body assertions for API health endpoints
+DO $$
+BEGIN
+    -- One assertion per line, e.g. $.status == "ok" or len($.queue) < 1000
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'json_assertions') THEN
+        ALTER TABLE site_configs ADD COLUMN json_assertions TEXT DEFAULT '';
+    END IF;
+END $$;
@@ -0,0 +1,27 @@
+-- Add configurable HTTP method, request body and success criteria
+DO $$
+BEGIN
+    -- Request method: GET, HEAD, POST, PUT, PATCH, DELETE or OPTIONS
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'method') THEN
+        ALTER TABLE site_configs ADD COLUMN method VARCHAR(10) DEFAULT 'GET';
+    END IF;
+
+    -- Request body and its content type
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'request_body') THEN
+        ALTER TABLE site_configs ADD COLUMN request_body TEXT DEFAULT '';
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'request_content_type') THEN
+        ALTER TABLE site_configs ADD COLUMN request_content_type VARCHAR(255) DEFAULT '';
+    END IF;
+
+    -- Accepted status codes and ranges, e.g. 200-299,301 (overrides expected_status)
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'expected_status_codes') THEN
+        ALTER TABLE site_configs ADD COLUMN expected_status_codes VARCHAR(255) DEFAULT '';
+    END IF;
+
+    -- Response header assertions, one per line
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'header_assertions') THEN
+        ALTER TABLE site_configs ADD COLUMN header_assertions TEXT DEFAULT '';
+    END IF;
+END $$;
@@ -0,0 +1,35 @@
+-- Add heartbeat (push) monitors for cron jobs and batch workers
+CREATE TABLE IF NOT EXISTS heartbeats (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    -- Expected cadence: period in seconds or a cron expression (cron wins if set)
+    period_seconds INTEGER DEFAULT 86400,
+    grace_seconds INTEGER DEFAULT 3600,
+    cron_schedule VARCHAR(100) DEFAULT '',
+    -- new, up, running, down
+    status VARCHAR(20) DEFAULT 'new',
+    enabled BOOLEAN DEFAULT TRUE,
+    last_ping_at TIMESTAMP,
+    last_start_at TIMESTAMP,
+    last_duration_ms BIGINT DEFAULT 0,
+    last_log TEXT DEFAULT '',
+    -- Deadline for the next ping including grace time
+    next_due_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Received pings: success, start, fail
+CREATE TABLE IF NOT EXISTS heartbeat_pings (
+    id SERIAL PRIMARY KEY,
+    heartbeat_id INTEGER REFERENCES heartbeats(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    log TEXT DEFAULT '',
+    remote_addr VARCHAR(255) DEFAULT '',
+    duration_ms BIGINT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_heartbeats_next_due ON heartbeats(next_due_at) WHERE enabled = TRUE;
+CREATE INDEX IF NOT EXISTS idx_heartbeat_pings_heartbeat_id ON heartbeat_pings(heartbeat_id, created_at DESC);
@@ -0,0 +1,35 @@
+-- Add consecutive failure/success thresholds and retry before a failure counts
+DO $$
+BEGIN
+    -- Number of consecutive failed checks before the site is marked down
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'fail_threshold') THEN
+        ALTER TABLE site_configs ADD COLUMN fail_threshold INTEGER DEFAULT 1;
+    END IF;
+
+    -- Number of consecutive successful checks before a down site is marked up
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'recover_threshold') THEN
+        ALTER TABLE site_configs ADD COLUMN recover_threshold INTEGER DEFAULT 1;
+    END IF;
+
+    -- Immediate retry of a failed check after retry_delay seconds
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'retry_on_failure') THEN
+        ALTER TABLE site_configs ADD COLUMN retry_on_failure BOOLEAN DEFAULT FALSE;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'retry_delay') THEN
+        ALTER TABLE site_configs ADD COLUMN retry_delay INTEGER DEFAULT 5;
+    END IF;
+
+    -- Status the site is moving to while the threshold is not reached yet
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'sites' AND column_name = 'pending_status') THEN
+        ALTER TABLE sites ADD COLUMN pending_status VARCHAR(20) DEFAULT '';
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'sites' AND column_name = 'consecutive_failures') THEN
+        ALTER TABLE sites ADD COLUMN consecutive_failures INTEGER DEFAULT 0;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'sites' AND column_name = 'consecutive_successes') THEN
+        ALTER TABLE sites ADD COLUMN consecutive_successes INTEGER DEFAULT 0;
+    END IF;
+END $$;
@@ -0,0 +1,42 @@
+-- Add incidents: opened on a confirmed outage, resolved on recovery
+CREATE TABLE IF NOT EXISTS incidents (
+    id SERIAL PRIMARY KEY,
+    site_id INTEGER REFERENCES sites(id) ON DELETE CASCADE,
+    site_url VARCHAR(2048) NOT NULL,
+    -- open, acknowledged, resolved
+    status VARCHAR(20) DEFAULT 'open',
+    title VARCHAR(500) DEFAULT '',
+    -- Error of the check that opened the incident and of the latest one
+    cause TEXT DEFAULT '',
+    last_error TEXT DEFAULT '',
+    started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    acknowledged_at TIMESTAMP,
+    acknowledged_by VARCHAR(255) DEFAULT '',
+    assigned_to VARCHAR(255) DEFAULT '',
+    resolved_at TIMESTAMP,
+    resolved_by VARCHAR(255) DEFAULT '',
+    last_check_at TIMESTAMP,
+    check_count INTEGER DEFAULT 0,
+    failed_checks INTEGER DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Timeline: opened, check, alert, acknowledged, assigned, comment, resolved
+CREATE TABLE IF NOT EXISTS incident_events (
+    id SERIAL PRIMARY KEY,
+    incident_id INTEGER REFERENCES incidents(id) ON DELETE CASCADE,
+    event_type VARCHAR(30) NOT NULL,
+    message TEXT DEFAULT '',
+    author VARCHAR(255) DEFAULT '',
+    status VARCHAR(20) DEFAULT '',
+    status_code INTEGER DEFAULT 0,
+    response_time BIGINT DEFAULT 0,
+    channel VARCHAR(50) DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Only one unresolved incident per site
+CREATE UNIQUE INDEX IF NOT EXISTS idx_incidents_site_active ON incidents(site_id) WHERE status != 'resolved';
+CREATE INDEX IF NOT EXISTS idx_incidents_started_at ON incidents(started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_incident_events_incident_id ON incident_events(incident_id, created_at);
@@ -0,0 +1,35 @@
+-- Add maintenance windows: alerts are suppressed and checks are excluded from uptime
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT DEFAULT '',
+    -- all, sites, tags
+    scope VARCHAR(20) DEFAULT 'all',
+    site_ids JSONB DEFAULT '[]',
+    tags JSONB DEFAULT '[]',
+    -- One-off window: starts_at..ends_at. Recurring window: starts at every
+    -- cron match and lasts duration_minutes, starts_at/ends_at optionally
+    -- limit the period it is in effect
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    cron_schedule VARCHAR(100) DEFAULT '',
+    duration_minutes INTEGER DEFAULT 0,
+    enabled BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+DO $$
+BEGIN
+    -- Tags used to scope maintenance windows to a group of sites
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'tags') THEN
+        ALTER TABLE site_configs ADD COLUMN tags JSONB DEFAULT '[]';
+    END IF;
+
+    -- Checks made during a maintenance window
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_history' AND column_name = 'maintenance') THEN
+        ALTER TABLE site_history ADD COLUMN maintenance BOOLEAN DEFAULT FALSE;
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_windows_enabled ON maintenance_windows(enabled);
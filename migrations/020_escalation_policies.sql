@@ -0,0 +1,46 @@
+-- Add escalation policies for unacknowledged alerts
+CREATE TABLE IF NOT EXISTS escalation_policies (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT DEFAULT '',
+    -- [{"delay_minutes": 0, "channels": ["telegram"]}, {"delay_minutes": 10, "channels": ["email"], "email_to": ["lead@example.com"]}]
+    steps JSONB DEFAULT '[]',
+    enabled BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Running escalations, picked up by the worker after a restart
+CREATE TABLE IF NOT EXISTS escalations (
+    id SERIAL PRIMARY KEY,
+    policy_id INTEGER REFERENCES escalation_policies(id) ON DELETE CASCADE,
+    -- 0 for alerts that do not belong to a site (heartbeats)
+    site_id INTEGER DEFAULT 0,
+    site_url VARCHAR(2048) NOT NULL,
+    alert_type VARCHAR(50) NOT NULL,
+    -- Alert payload resent by every step
+    alert JSONB NOT NULL,
+    -- active, acknowledged, resolved, completed
+    status VARCHAR(20) DEFAULT 'active',
+    current_step INTEGER DEFAULT 0,
+    notified_channels JSONB DEFAULT '[]',
+    next_step_at TIMESTAMP,
+    acknowledged_at TIMESTAMP,
+    acknowledged_by VARCHAR(255) DEFAULT '',
+    started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'escalation_policy_id') THEN
+        ALTER TABLE site_configs ADD COLUMN escalation_policy_id INTEGER DEFAULT 0;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'escalation_policy_id') THEN
+        ALTER TABLE alert_configs ADD COLUMN escalation_policy_id INTEGER DEFAULT 0;
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_escalations_due ON escalations(next_step_at) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_escalations_site_url ON escalations(site_url) WHERE status IN ('active', 'acknowledged');
@@ -0,0 +1,43 @@
+-- Add on-call schedules with rotations and overrides
+CREATE TABLE IF NOT EXISTS oncall_users (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) DEFAULT '',
+    telegram_chat_id VARCHAR(100) DEFAULT '',
+    webhook_url VARCHAR(2048) DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS oncall_schedules (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT DEFAULT '',
+    -- IANA time zone of handoffs and layer restrictions
+    timezone VARCHAR(64) DEFAULT 'UTC',
+    -- [{"name": "Основная", "rotation": "weekly", "start_at": "...", "user_ids": [1, 2]}], later layers win
+    layers JSONB DEFAULT '[]',
+    enabled BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Temporary replacements that win over every layer
+CREATE TABLE IF NOT EXISTS oncall_overrides (
+    id SERIAL PRIMARY KEY,
+    schedule_id INTEGER NOT NULL REFERENCES oncall_schedules(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES oncall_users(id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    reason TEXT DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'oncall_schedule_id') THEN
+        ALTER TABLE alert_configs ADD COLUMN oncall_schedule_id INTEGER DEFAULT 0;
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_oncall_overrides_schedule ON oncall_overrides(schedule_id, starts_at, ends_at);
@@ -0,0 +1,20 @@
+-- Add Slack channel to alert configurations
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'slack_enabled') THEN
+        ALTER TABLE alert_configs ADD COLUMN slack_enabled BOOLEAN DEFAULT FALSE;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'slack_webhook_url') THEN
+        ALTER TABLE alert_configs ADD COLUMN slack_webhook_url VARCHAR(2048) DEFAULT '';
+    END IF;
+
+    -- Bot token (xoxb-...) enables threaded follow-ups via chat.postMessage
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'slack_bot_token') THEN
+        ALTER TABLE alert_configs ADD COLUMN slack_bot_token VARCHAR(255) DEFAULT '';
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'slack_channel') THEN
+        ALTER TABLE alert_configs ADD COLUMN slack_channel VARCHAR(255) DEFAULT '';
+    END IF;
+END $$;
@@ -0,0 +1,20 @@
+-- Add Discord and Microsoft Teams channels to alert configurations
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'discord_enabled') THEN
+        ALTER TABLE alert_configs ADD COLUMN discord_enabled BOOLEAN DEFAULT FALSE;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'discord_webhook_url') THEN
+        ALTER TABLE alert_configs ADD COLUMN discord_webhook_url VARCHAR(2048) DEFAULT '';
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'teams_enabled') THEN
+        ALTER TABLE alert_configs ADD COLUMN teams_enabled BOOLEAN DEFAULT FALSE;
+    END IF;
+
+    -- Incoming Webhook or Workflows (Power Automate) URL
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'teams_webhook_url') THEN
+        ALTER TABLE alert_configs ADD COLUMN teams_webhook_url VARCHAR(2048) DEFAULT '';
+    END IF;
+END $$;
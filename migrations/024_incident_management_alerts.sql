@@ -0,0 +1,25 @@
+-- Add PagerDuty and Opsgenie integrations to alert configurations
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'pagerduty_enabled') THEN
+        ALTER TABLE alert_configs ADD COLUMN pagerduty_enabled BOOLEAN DEFAULT FALSE;
+    END IF;
+
+    -- Integration key of an Events API v2 service
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'pagerduty_routing_key') THEN
+        ALTER TABLE alert_configs ADD COLUMN pagerduty_routing_key VARCHAR(255) DEFAULT '';
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'opsgenie_enabled') THEN
+        ALTER TABLE alert_configs ADD COLUMN opsgenie_enabled BOOLEAN DEFAULT FALSE;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'opsgenie_api_key') THEN
+        ALTER TABLE alert_configs ADD COLUMN opsgenie_api_key VARCHAR(255) DEFAULT '';
+    END IF;
+
+    -- us or eu
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'opsgenie_region') THEN
+        ALTER TABLE alert_configs ADD COLUMN opsgenie_region VARCHAR(10) DEFAULT 'us';
+    END IF;
+END $$;
@@ -0,0 +1,16 @@
+-- Add message templates per channel and alert type
+CREATE TABLE IF NOT EXISTS alert_templates (
+    id SERIAL PRIMARY KEY,
+    -- email, webhook, telegram
+    channel VARCHAR(50) NOT NULL,
+    -- '' applies to every alert type without its own template
+    alert_type VARCHAR(50) NOT NULL DEFAULT '',
+    -- text (text/template) or html (html/template, email only)
+    format VARCHAR(10) NOT NULL DEFAULT 'text',
+    subject TEXT DEFAULT '',
+    body TEXT NOT NULL,
+    enabled BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (channel, alert_type)
+);
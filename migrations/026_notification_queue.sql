@@ -0,0 +1,26 @@
+-- Persistent queue of failed alert deliveries, retried with exponential backoff
+CREATE TABLE IF NOT EXISTS notification_queue (
+    id SERIAL PRIMARY KEY,
+    -- 0 for alerts that do not belong to a site (heartbeats)
+    site_id INTEGER DEFAULT 0,
+    site_url VARCHAR(2048) NOT NULL,
+    alert_type VARCHAR(50) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    -- Alert configuration of the route, '' for the global one
+    config_name VARCHAR(255) DEFAULT '',
+    -- Recipient overrides of escalation steps and on-call schedules
+    recipients JSONB,
+    alert JSONB NOT NULL,
+    -- pending, sent, dead
+    status VARCHAR(20) DEFAULT 'pending',
+    attempts INTEGER DEFAULT 0,
+    max_attempts INTEGER DEFAULT 7,
+    next_attempt_at TIMESTAMP,
+    last_error TEXT DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_queue_due ON notification_queue(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_notification_queue_status ON notification_queue(status, created_at);
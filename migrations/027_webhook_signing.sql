@@ -0,0 +1,8 @@
+-- Add per-webhook signing secrets to alert configurations
+DO $$
+BEGIN
+    -- HMAC-SHA256 key for the X-PingTower-Signature header, empty disables signing
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'webhook_secret') THEN
+        ALTER TABLE alert_configs ADD COLUMN webhook_secret VARCHAR(255) DEFAULT '';
+    END IF;
+END $$;
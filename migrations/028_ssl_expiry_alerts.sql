@@ -0,0 +1,17 @@
+-- SSL certificate expiry alert thresholds and the last threshold alerted per site
+DO $$
+BEGIN
+    -- Comma separated days before expiry, e.g. '30,14,7,1'; '' derives them from ssl_alert_days
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'ssl_alert_thresholds') THEN
+        ALTER TABLE site_configs ADD COLUMN ssl_alert_thresholds VARCHAR(255) DEFAULT '';
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS ssl_expiry_alerts (
+    site_id INTEGER PRIMARY KEY REFERENCES sites(id) ON DELETE CASCADE,
+    -- Expiry of the certificate the alerts were sent for, a different one means it was renewed
+    ssl_expiry TIMESTAMP NOT NULL,
+    -- Smallest threshold alerted, 0 once the certificate has expired
+    threshold INTEGER NOT NULL,
+    alerted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
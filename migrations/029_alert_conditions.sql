@@ -0,0 +1,21 @@
+-- Check conditions beyond outages: sustained slow responses and changes of
+-- the status code, content, redirect target and keywords
+DO $$
+BEGIN
+    -- Consecutive checks above response_time_threshold before alerting
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'response_time_checks') THEN
+        ALTER TABLE alert_configs ADD COLUMN response_time_checks INTEGER DEFAULT 1;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'alert_on_content_change') THEN
+        ALTER TABLE alert_configs ADD COLUMN alert_on_content_change BOOLEAN DEFAULT FALSE;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'alert_on_redirect_change') THEN
+        ALTER TABLE alert_configs ADD COLUMN alert_on_redirect_change BOOLEAN DEFAULT FALSE;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'alert_on_keyword_lost') THEN
+        ALTER TABLE alert_configs ADD COLUMN alert_on_keyword_lost BOOLEAN DEFAULT FALSE;
+    END IF;
+END $$;
@@ -0,0 +1,24 @@
+-- Content masks and stored snapshots of the page content
+DO $$
+BEGIN
+    -- One CSS selector or 'regex:<pattern>' per line, hidden from the content hash and snapshots
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'site_configs' AND column_name = 'content_masks') THEN
+        ALTER TABLE site_configs ADD COLUMN content_masks TEXT DEFAULT '';
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS content_snapshots (
+    id SERIAL PRIMARY KEY,
+    site_id INTEGER NOT NULL REFERENCES sites(id) ON DELETE CASCADE,
+    content_hash VARCHAR(64) NOT NULL,
+    status_code INTEGER DEFAULT 0,
+    -- Size of the stored content and of the masked body it was cut from
+    size INTEGER NOT NULL,
+    original_size INTEGER NOT NULL,
+    truncated BOOLEAN DEFAULT FALSE,
+    -- Gzip compressed content
+    data BYTEA NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_snapshots_site_created ON content_snapshots(site_id, created_at DESC);
@@ -0,0 +1,12 @@
+-- Alerts on response times far above the usual ones of the site at that hour
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'alert_on_response_time_anomaly') THEN
+        ALTER TABLE alert_configs ADD COLUMN alert_on_response_time_anomaly BOOLEAN DEFAULT FALSE;
+    END IF;
+
+    -- Distance above the baseline median, in robust standard deviations, that counts as an anomaly
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'alert_configs' AND column_name = 'response_time_anomaly_sigmas') THEN
+        ALTER TABLE alert_configs ADD COLUMN response_time_anomaly_sigmas DOUBLE PRECISION DEFAULT 3;
+    END IF;
+END $$;
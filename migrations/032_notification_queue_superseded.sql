@@ -0,0 +1,10 @@
+-- Queued notifications are superseded once a newer alert of their site is delivered on the same channel
+DO $$
+BEGIN
+    -- When the alert was raised; older alerts than a delivered one are not retried
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'notification_queue' AND column_name = 'alerted_at') THEN
+        ALTER TABLE notification_queue ADD COLUMN alerted_at TIMESTAMPTZ;
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_notification_queue_key ON notification_queue(site_id, channel, config_name) WHERE status IN ('pending', 'dead');
@@ -0,0 +1,8 @@
+-- Escalations run their steps with the alert configuration that owns the policy
+DO $$
+BEGIN
+    -- Alert configuration of the steps, '' for the global one
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'escalations' AND column_name = 'config_name') THEN
+        ALTER TABLE escalations ADD COLUMN config_name VARCHAR(255) DEFAULT '';
+    END IF;
+END $$;
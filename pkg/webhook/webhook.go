@@ -0,0 +1,158 @@
+// Package webhook verifies alert webhooks sent by ping-tower.
+//
+// Every webhook carries an X-PingTower-Event-ID header, and when the webhook
+// has a secret, an X-PingTower-Signature header of the form
+//
+//	t=<unix timestamp>,v1=<hex HMAC-SHA256 of "<timestamp>.<body>">
+//
+// The timestamp is the time of the delivery attempt, so retries are signed
+// again while keeping their event ID. Receivers reject signatures older than
+// a tolerance and drop event IDs they have already processed within it, which
+// together protect against replayed requests.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SignatureHeader     = "X-PingTower-Signature"
+	EventIDHeader       = "X-PingTower-Event-ID"
+	SchemaVersionHeader = "X-PingTower-Schema-Version"
+
+	// SchemaVersion is the version of Payload. It changes only when fields
+	// are removed or change their meaning; new fields keep the version.
+	SchemaVersion = "1"
+
+	// DefaultTolerance is the accepted age of a signature.
+	DefaultTolerance = 5 * time.Minute
+)
+
+var (
+	ErrNoSignature       = errors.New("webhook: missing signature")
+	ErrInvalidSignature  = errors.New("webhook: malformed signature header")
+	ErrSignatureMismatch = errors.New("webhook: signature mismatch")
+	ErrExpired           = errors.New("webhook: timestamp outside tolerance")
+	ErrUnsupportedSchema = errors.New("webhook: unsupported schema version")
+)
+
+// Payload is the JSON body of an alert webhook.
+type Payload struct {
+	SchemaVersion string          `json:"schema_version"`
+	EventID       string          `json:"event_id"`
+	SiteURL       string          `json:"site_url"`
+	SiteID        int             `json:"site_id"`
+	Status        string          `json:"status"`
+	StatusCode    int             `json:"status_code"`
+	ResponseTime  int64           `json:"response_time"`
+	Error         string          `json:"error,omitempty"`
+	Timestamp     time.Time       `json:"timestamp"`
+	AlertType     string          `json:"alert_type"`
+	CheckResult   json.RawMessage `json:"check_result,omitempty"`
+	// Digest holds the alerts combined into a digest alert
+	Digest []Payload `json:"digest,omitempty"`
+}
+
+// Sign returns the signature header value for the body sent at the given time.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := timestamp.Unix()
+	return fmt.Sprintf("t=%d,v1=%s", t, hex.EncodeToString(signature(secret, t, body)))
+}
+
+func signature(secret string, t int64, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(t, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// Verify checks the signature header against the body. The header may carry
+// several v1 signatures, e.g. while a secret is being rotated; one match is
+// enough. A tolerance of zero disables the timestamp check.
+func Verify(secret, header string, body []byte, tolerance time.Duration) error {
+	if header == "" {
+		return ErrNoSignature
+	}
+
+	var t int64
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			return ErrInvalidSignature
+		}
+		switch kv[0] {
+		case "t":
+			parsed, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil {
+				return ErrInvalidSignature
+			}
+			t = parsed
+		case "v1":
+			sig, err := hex.DecodeString(kv[1])
+			if err != nil {
+				return ErrInvalidSignature
+			}
+			signatures = append(signatures, sig)
+		}
+	}
+	if t == 0 || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	if tolerance > 0 {
+		age := time.Since(time.Unix(t, 0))
+		if age > tolerance || age < -tolerance {
+			return ErrExpired
+		}
+	}
+
+	expected := signature(secret, t, body)
+	for _, sig := range signatures {
+		if hmac.Equal(sig, expected) {
+			return nil
+		}
+	}
+	return ErrSignatureMismatch
+}
+
+// VerifyRequest reads the body of the request and verifies its signature.
+// The body is returned and also restored on the request.
+func VerifyRequest(r *http.Request, secret string, tolerance time.Duration) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("webhook: read body: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := Verify(secret, r.Header.Get(SignatureHeader), body, tolerance); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// Parse decodes a payload of a supported schema version. Bodies rendered
+// from a custom template are not payloads and must be decoded by the caller.
+func Parse(body []byte) (*Payload, error) {
+	var payload Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("webhook: decode payload: %w", err)
+	}
+	if payload.SchemaVersion != SchemaVersion {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedSchema, payload.SchemaVersion)
+	}
+	return &payload, nil
+}